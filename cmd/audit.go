@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+	"github.com/opiproject/opi-marvell-bridge/pkg/store"
+)
+
+// newAuditLogger creates the audit logger writing to the file and the Redis
+// stream, nil if neither is configured
+func newAuditLogger(c *config.AuditConfig, redis *config.RedisConfig) (*audit.Logger, error) {
+	var sinks []audit.Sink
+	if c.File != "" {
+		sink, err := audit.NewFileSink(c.File, int64(c.MaxSizeMB)*1024*1024, c.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	if c.RedisStream != "" {
+		client, err := store.NewRedisClient(redis)
+		if err != nil {
+			for _, sink := range sinks {
+				_ = sink.Close()
+			}
+			return nil, err
+		}
+		sinks = append(sinks, audit.NewRedisSink(client, redis.KeyPrefix+c.RedisStream, c.RedisMaxLen))
+	}
+	if len(sinks) == 0 {
+		logger.Warn("Audit log is disabled, set -audit_file or -audit_redis_stream to enable it")
+		return nil, nil
+	}
+	l, err := audit.NewLogger(sinks...)
+	if err != nil {
+		for _, sink := range sinks {
+			_ = sink.Close()
+		}
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,149 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/auth"
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+)
+
+// listenUnix listens on the unix socket path, only accessible to the
+// bridge user. A socket left behind by a previous run is removed.
+func listenUnix(path string) (net.Listener, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, 0600); err != nil {
+		_ = lis.Close()
+		return nil, err
+	}
+	return lis, nil
+}
+
+// loadCertPool reads the PEM certificates of file
+func loadCertPool(file string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+	return pool, nil
+}
+
+// orDefault returns value, or fallback if value is empty
+func orDefault(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// gatewayServerTLSConfig returns the HTTPS configuration of the gateway,
+// nil to serve plain HTTP
+func gatewayServerTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	t := &cfg.HTTP.TLS
+	if !t.Enabled {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(orDefault(t.CertFile, cfg.TLS.CertFile), orDefault(t.KeyFile, cfg.TLS.KeyFile))
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	switch t.ClientAuth {
+	case config.ClientAuthRequest:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	case config.ClientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return tlsConfig, nil
+	}
+	if tlsConfig.ClientCAs, err = loadCertPool(orDefault(t.ClientCAFile, cfg.TLS.CAFile)); err != nil {
+		return nil, err
+	}
+	return tlsConfig, nil
+}
+
+// gatewayEndpoint returns the gRPC endpoint dialed by the gateway and the
+// options to dial it: the unix socket if any, otherwise the gRPC port with
+// the gRPC server TLS material if any
+func gatewayEndpoint(cfg *config.Config) (string, []grpc.DialOption, error) {
+	if cfg.GRPC.UnixSocket != "" {
+		return "unix://" + cfg.GRPC.UnixSocket, []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+	endpoint := fmt.Sprintf("localhost:%d", cfg.GRPC.Port)
+	if !cfg.TLS.Enabled() {
+		return endpoint, []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}, nil
+	}
+	b := &cfg.HTTP.Backend
+	// the gRPC server requires client certificates
+	cert, err := tls.LoadX509KeyPair(orDefault(b.CertFile, cfg.TLS.CertFile), orDefault(b.KeyFile, cfg.TLS.KeyFile))
+	if err != nil {
+		return "", nil, err
+	}
+	roots, err := loadCertPool(orDefault(b.CAFile, cfg.TLS.CAFile))
+	if err != nil {
+		return "", nil, err
+	}
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      roots,
+		ServerName:   b.ServerName,
+		MinVersion:   tls.VersionTLS12,
+	})
+	return endpoint, []grpc.DialOption{grpc.WithTransportCredentials(creds)}, nil
+}
+
+// gatewayMuxOptions forwards the common name of the verified HTTPS client
+// certificate to the gRPC server, trusting the gateway as a proxy, and
+// drops the same metadata sent by the HTTP clients themselves
+func gatewayMuxOptions() []runtime.ServeMuxOption {
+	return []runtime.ServeMuxOption{
+		runtime.WithIncomingHeaderMatcher(func(key string) (string, bool) {
+			if strings.EqualFold(key, runtime.MetadataHeaderPrefix+auth.ForwardedClientCertKey) {
+				return "", false
+			}
+			return runtime.DefaultHeaderMatcher(key)
+		}),
+		runtime.WithMetadata(func(_ context.Context, r *http.Request) metadata.MD {
+			if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+				return nil
+			}
+			return metadata.Pairs(auth.ForwardedClientCertKey, r.TLS.VerifiedChains[0][0].Subject.CommonName)
+		}),
+	}
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	fe "github.com/opiproject/opi-marvell-bridge/pkg/frontend"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// healthResponse is the body of the /healthz and /readyz responses
+type healthResponse struct {
+	Status   string            `json:"status"`
+	Services map[string]string `json:"services,omitempty"`
+	Error    string            `json:"error,omitempty"`
+}
+
+// healthHandlersFromEndpoint serves /healthz and /readyz next to the HTTP
+// gateway, both answered by the gRPC health service within timeout.
+// /healthz only needs the gRPC server to answer, /readyz needs all checked
+// services to be serving.
+func healthHandlersFromEndpoint(timeout time.Duration) registerHandlerFunc {
+	return func(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
+		return registerHealthHandlers(ctx, mux, endpoint, opts, timeout)
+	}
+}
+
+func registerHealthHandlers(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption, timeout time.Duration) error {
+	conn, err := grpc.DialContext(ctx, endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		if err := conn.Close(); err != nil {
+			logger.Error("Failed to close conn", "endpoint", endpoint, "error", err)
+		}
+	}()
+	client := grpc_health_v1.NewHealthClient(conn)
+
+	err = mux.HandlePath(http.MethodGet, "/healthz", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		if _, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{}); err != nil {
+			writeHealthResponse(w, http.StatusServiceUnavailable, &healthResponse{Status: "DOWN", Error: err.Error()})
+			return
+		}
+		writeHealthResponse(w, http.StatusOK, &healthResponse{Status: "UP"})
+	})
+	if err != nil {
+		return err
+	}
+	return mux.HandlePath(http.MethodGet, "/readyz", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		overall, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+		if err != nil {
+			writeHealthResponse(w, http.StatusServiceUnavailable, &healthResponse{Status: "DOWN", Error: err.Error()})
+			return
+		}
+		response := &healthResponse{Status: overall.Status.String(), Services: map[string]string{}}
+		for _, service := range fe.HealthServices {
+			resp, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+			if err != nil {
+				response.Services[service] = err.Error()
+				continue
+			}
+			response.Services[service] = resp.Status.String()
+		}
+		code := http.StatusOK
+		if overall.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+			code = http.StatusServiceUnavailable
+		}
+		writeHealthResponse(w, code, response)
+	})
+}
+
+func writeHealthResponse(w http.ResponseWriter, code int, response *healthResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Failed to write health response", "error", err)
+	}
+}
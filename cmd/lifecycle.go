@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// shutdownStep releases a single resource of the bridge
+type shutdownStep struct {
+	name string
+	fn   func(context.Context) error
+}
+
+// lifecycle releases the resources of the bridge on shutdown, in the
+// reverse order of their registration, like deferred calls
+type lifecycle struct {
+	steps []shutdownStep
+}
+
+// onShutdown registers fn to release the resource called name
+func (l *lifecycle) onShutdown(name string, fn func(context.Context) error) {
+	l.steps = append(l.steps, shutdownStep{name: name, fn: fn})
+}
+
+// shutdown runs all steps, even after one fails or ctx is done, so that
+// the resources not depending on ctx, e.g. the store, are still released
+func (l *lifecycle) shutdown(ctx context.Context) error {
+	var errs []error
+	for i := len(l.steps) - 1; i >= 0; i-- {
+		step := l.steps[i]
+		start := time.Now()
+		if err := step.fn(ctx); err != nil {
+			logger.Error("Failed to shut down", "component", step.name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", step.name, err))
+			continue
+		}
+		logger.Info("Shut down", "component", step.name, "duration", time.Since(start))
+	}
+	return errors.Join(errs...)
+}
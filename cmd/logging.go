@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+
+	bridgelog "github.com/opiproject/opi-marvell-bridge/pkg/logging"
+)
+
+var logger = bridgelog.Logger("main")
+
+// configureLogging sets up the loggers of all bridge components
+func configureLogging(level string, levels string, format string, maxValueLength int) error {
+	opts := bridgelog.Options{MaxValueLength: maxValueLength}
+	if err := opts.Level.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	var err error
+	if opts.Levels, err = bridgelog.ParseLevels(levels); err != nil {
+		return err
+	}
+	switch format {
+	case "text":
+	case "json":
+		opts.JSON = true
+	default:
+		return fmt.Errorf("invalid log format %q, expected text or json", format)
+	}
+	bridgelog.Configure(os.Stderr, opts)
+	return nil
+}
+
+// grpcLogEvents returns the events logged by the gRPC interceptor, payloads
+// are large and only logged on request
+func grpcLogEvents(logPayloads bool) []logging.LoggableEvent {
+	events := []logging.LoggableEvent{logging.StartCall, logging.FinishCall}
+	if logPayloads {
+		events = append(events, logging.PayloadReceived, logging.PayloadSent)
+	}
+	return events
+}
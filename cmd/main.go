@@ -7,16 +7,27 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
-	"time"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
 	"github.com/opiproject/gospdk/spdk"
 
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
+	"github.com/opiproject/opi-marvell-bridge/pkg/auth"
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
 	fe "github.com/opiproject/opi-marvell-bridge/pkg/frontend"
+	bridgelog "github.com/opiproject/opi-marvell-bridge/pkg/logging"
+	bridgestore "github.com/opiproject/opi-marvell-bridge/pkg/store"
 	"github.com/opiproject/opi-smbios-bridge/pkg/inventory"
 	"github.com/opiproject/opi-spdk-bridge/pkg/backend"
 	"github.com/opiproject/opi-spdk-bridge/pkg/frontend"
@@ -29,10 +40,11 @@ import (
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
 
 	"github.com/philippgille/gokv"
-	"github.com/philippgille/gokv/redis"
+	"github.com/prometheus/client_golang/prometheus"
 
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
@@ -42,120 +54,264 @@ import (
 )
 
 func main() {
-	var grpcPort int
-	flag.IntVar(&grpcPort, "grpc_port", 50051, "The gRPC server port")
-
-	var httpPort int
-	flag.IntVar(&httpPort, "http_port", 8082, "The HTTP server port")
-
-	var spdkAddress string
-	flag.StringVar(&spdkAddress, "spdk_addr", "/var/tmp/spdk.sock", "Points to SPDK unix socket/tcp socket to interact with")
-
-	var tlsFiles string
-	flag.StringVar(&tlsFiles, "tls", "", "TLS files in server_cert:server_key:ca_cert format.")
+	printConfig := flag.Bool("print_config", false, "Print the effective configuration as YAML and exit")
+	cfg, err := config.Load(flag.CommandLine, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *printConfig {
+		fmt.Print(cfg)
+		return
+	}
 
-	var redisAddress string
-	flag.StringVar(&redisAddress, "redis_addr", "127.0.0.1:6379", "Redis address in ip_address:port format")
+	if err := configureLogging(cfg.Log.Level, cfg.Log.Levels, cfg.Log.Format, cfg.Log.MaxValueLength); err != nil {
+		log.Panic(err)
+	}
 
-	flag.Parse()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	lc := &lifecycle{}
 
 	// Create KV store for persistence
-	options := redis.DefaultOptions
-	options.Address = redisAddress
-	options.Codec = utils.ProtoCodec{}
-	store, err := redis.NewClient(options)
+	store, err := bridgestore.New(&cfg.Store)
 	if err != nil {
 		log.Panic(err)
 	}
-	defer func(store gokv.Store) {
-		err := store.Close()
-		if err != nil {
-			log.Panic(err)
-		}
-	}(store)
+	logger.Info("Store created", "type", cfg.Store.Type)
+	lc.onShutdown("store", func(context.Context) error { return store.Close() })
 
-	go runGatewayServer(grpcPort, httpPort)
-	runGrpcServer(grpcPort, spdkAddress, tlsFiles, store)
-}
+	auditLogger, err := newAuditLogger(&cfg.Audit, &cfg.Store.Redis)
+	if err != nil {
+		log.Panic(err)
+	}
+	if auditLogger != nil {
+		lc.onShutdown("audit log", func(context.Context) error { return auditLogger.Close() })
+	}
 
-func runGrpcServer(grpcPort int, spdkAddress string, tlsFiles string, store gokv.Store) {
 	tp := utils.InitTracerProvider("opi-marvell-bridge")
-	defer func() {
-		if err := tp.Shutdown(context.Background()); err != nil {
-			log.Panicf("Tracer Provider Shutdown: %v", err)
-		}
-	}()
+	lc.onShutdown("tracer provider", tp.Shutdown)
+	mp := initMeterProvider("opi-marvell-bridge")
+	lc.onShutdown("meter provider", mp.Shutdown)
+
+	registry := newMetricsRegistry()
+	errs := make(chan error, 4)
+	drain := runGrpcServer(ctx, lc, errs, cfg, store, registry, auditLogger)
+	runGatewayServer(lc, errs, cfg, registry)
+	if cfg.Metrics.Port != 0 {
+		runMetricsServer(lc, errs, cfg.Metrics.Port, registry)
+	}
+	// first of all, stop being ready and end the watch streams, which would
+	// otherwise keep the gateway from shutting down
+	lc.onShutdown("readiness and watch streams", func(context.Context) error {
+		drain()
+		return nil
+	})
+
+	var serveErr error
+	select {
+	case <-ctx.Done():
+		logger.Info("Shutting down", "timeout", cfg.Shutdown.Timeout)
+	case serveErr = <-errs:
+		logger.Error("Shutting down after a server failed", "error", serveErr, "timeout", cfg.Shutdown.Timeout)
+	}
+	// a second signal terminates right away
+	stop()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Shutdown.Timeout)
+	shutdownErr := lc.shutdown(shutdownCtx)
+	cancel()
+	if err := errors.Join(serveErr, shutdownErr); err != nil {
+		log.Fatalf("Shutdown finished with errors: %v", err)
+	}
+	logger.Info("Shutdown finished")
+}
 
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", grpcPort))
+// runGrpcServer starts serving the gRPC APIs, serving errors are sent to
+// errs. The returned drain function marks the server not serving and ends
+// the watch streams.
+func runGrpcServer(ctx context.Context, lc *lifecycle, errs chan<- error, cfg *config.Config, store gokv.Store, registry *prometheus.Registry, auditLogger *audit.Logger) func() {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
 	if err != nil {
 		log.Panicf("failed to listen: %v", err)
 	}
 
-	jsonRPC := spdk.NewClient(spdkAddress)
-	frontendOpiMarvellServer := fe.NewServer(jsonRPC, store)
+	jsonRPC := spdk.NewClient(cfg.SDK.Address)
+	quotas := make(map[string]fe.Quota, len(cfg.Tenants.Quotas))
+	for tenant, quota := range cfg.Tenants.Quotas {
+		quotas[tenant] = fe.Quota(quota)
+	}
+	pcieFunctions := make([]fe.PcieFunction, 0, len(cfg.Frontend.PcieFunctions))
+	for _, f := range cfg.Frontend.PcieFunctions {
+		pcieFunctions = append(pcieFunctions, fe.PcieFunction(f))
+	}
 	frontendOpiSpdkServer := frontend.NewServer(jsonRPC, store)
 	backendOpiSpdkServer := backend.NewServer(jsonRPC, store)
 	middleendOpiSpdkServer := middleend.NewServer(jsonRPC, store)
-
-	var serverOptions []grpc.ServerOption
-	if tlsFiles == "" {
-		log.Println("TLS files are not specified. Use insecure connection.")
+	oui, err := cfg.Frontend.ParseOUI()
+	if err != nil {
+		log.Panicf("invalid configuration: %v", err)
+	}
+	var volumes fe.VolumeResolver
+	var volumeStore *fe.StoreVolumes
+	if cfg.Frontend.CheckVolumes {
+		volumeStore = fe.NewStoreVolumes(jsonRPC, store)
+		volumes = volumeStore
+	}
+	frontendOpiMarvellServer := fe.NewServerWithOptions(jsonRPC, store, fe.Options{
+		MinCtrlrID:          cfg.Frontend.MinCtrlrID,
+		MaxCtrlrID:          cfg.Frontend.MaxCtrlrID,
+		ShareNamespaces:     cfg.Frontend.ShareNamespaces,
+		AsyncOperations:     cfg.Features.AsyncOperations,
+		Quotas:              quotas,
+		DefaultQuota:        fe.Quota(cfg.Tenants.DefaultQuota),
+		PcieFunctions:       pcieFunctions,
+		QueueBudget:         fe.QueueBudget(cfg.Frontend.QueueBudget),
+		OUI:                 oui,
+		Volumes:             volumes,
+		RemovalTimeout:      cfg.Frontend.RemovalTimeout,
+		RemovalPollInterval: cfg.Frontend.RemovalPollInterval,
+	})
+
+	metricsCollector := fe.NewMetricsCollector(frontendOpiMarvellServer, cfg.Metrics.Interval)
+	registry.MustRegister(metricsCollector)
+	go metricsCollector.Run(ctx)
+
+	healthServer := health.NewServer()
+	healthChecker := fe.NewHealthChecker(frontendOpiMarvellServer, healthServer, cfg.Health.Interval)
+	go healthChecker.Run(ctx)
+
+	var tlsOptions []grpc.ServerOption
+	if !cfg.TLS.Enabled() {
+		logger.Warn("TLS files are not specified. Use insecure connection.")
 	} else {
-		log.Println("Use TLS certificate files:", tlsFiles)
-		config, err := utils.ParseTLSFiles(tlsFiles)
-		if err != nil {
-			log.Panic("Failed to parse string with tls paths:", err)
+		tlsConfig := utils.TLSConfig{
+			ServerCertPath: cfg.TLS.CertFile,
+			ServerKeyPath:  cfg.TLS.KeyFile,
+			CaCertPath:     cfg.TLS.CAFile,
 		}
-		log.Println("TLS config:", config)
-		var option grpc.ServerOption
-		if option, err = utils.SetupTLSCredentials(config); err != nil {
+		logger.Info("Use TLS certificate files", "config", tlsConfig)
+		option, err := utils.SetupTLSCredentials(tlsConfig)
+		if err != nil {
 			log.Panic("Failed to setup TLS:", err)
 		}
-		serverOptions = append(serverOptions, option)
+		tlsOptions = append(tlsOptions, option)
+	}
+	interceptors := []grpc.UnaryServerInterceptor{
+		logging.UnaryServerInterceptor(bridgelog.InterceptorLogger(bridgelog.Logger("grpc")),
+			logging.WithLogOnEvents(grpcLogEvents(cfg.Log.Payloads)...),
+		),
 	}
-	serverOptions = append(serverOptions,
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if cfg.Auth.Enabled {
+		authInterceptor, err := auth.New(&cfg.Auth)
+		if err != nil {
+			log.Panicf("cannot configure the authentication: %v", err)
+		}
+		interceptors = append(interceptors, authInterceptor.UnaryServerInterceptor())
+		streamInterceptors = append(streamInterceptors, authInterceptor.StreamServerInterceptor())
+	} else {
+		logger.Warn("Authentication is disabled. Every caller is allowed to call every method.")
+	}
+	if auditLogger != nil {
+		interceptors = append(interceptors, auditLogger.UnaryServerInterceptor())
+	}
+	interceptors = append(interceptors, frontendOpiMarvellServer.VolumeInUseInterceptor())
+	if volumeStore != nil {
+		interceptors = append(interceptors, volumeStore.UnaryServerInterceptor())
+	}
+	serverOptions := []grpc.ServerOption{
 		grpc.StatsHandler(otelgrpc.NewServerHandler()),
-		grpc.UnaryInterceptor(
-			logging.UnaryServerInterceptor(utils.InterceptorLogger(log.Default()),
-				logging.WithLogOnEvents(
-					logging.StartCall,
-					logging.FinishCall,
-					logging.PayloadReceived,
-					logging.PayloadSent,
-				),
-			)),
-	)
-	s := grpc.NewServer(serverOptions...)
-
-	pb.RegisterFrontendNvmeServiceServer(s, frontendOpiMarvellServer)
-	pb.RegisterFrontendVirtioBlkServiceServer(s, frontendOpiSpdkServer)
-	pb.RegisterFrontendVirtioScsiServiceServer(s, frontendOpiSpdkServer)
-	pb.RegisterNvmeRemoteControllerServiceServer(s, backendOpiSpdkServer)
-	pb.RegisterNullVolumeServiceServer(s, backendOpiSpdkServer)
-	pb.RegisterMallocVolumeServiceServer(s, backendOpiSpdkServer)
-	pb.RegisterAioVolumeServiceServer(s, backendOpiSpdkServer)
-	pb.RegisterMiddleendEncryptionServiceServer(s, middleendOpiSpdkServer)
-	pc.RegisterInventoryServiceServer(s, &inventory.Server{})
-	ps.RegisterIPsecServiceServer(s, &ipsec.Server{})
-
-	reflection.Register(s)
-
-	log.Printf("gRPC server listening at %v", lis.Addr())
-	if err := s.Serve(lis); err != nil {
-		log.Panicf("failed to serve: %v", err)
+		grpc.ChainUnaryInterceptor(interceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	}
+	newServer := func(opts ...grpc.ServerOption) *grpc.Server {
+		s := grpc.NewServer(append(opts, serverOptions...)...)
+
+		pb.RegisterFrontendNvmeServiceServer(s, frontendOpiMarvellServer)
+		longrunningpb.RegisterOperationsServer(s, frontendOpiMarvellServer)
+		mb.RegisterNvmeMetadataServiceServer(s, frontendOpiMarvellServer)
+		mb.RegisterNvmeCapacityServiceServer(s, frontendOpiMarvellServer)
+		mb.RegisterNvmeStateServiceServer(s, frontendOpiMarvellServer)
+		if cfg.Features.Watch {
+			mb.RegisterNvmeWatchServiceServer(s, frontendOpiMarvellServer)
+		}
+		pb.RegisterFrontendVirtioBlkServiceServer(s, frontendOpiSpdkServer)
+		pb.RegisterFrontendVirtioScsiServiceServer(s, frontendOpiSpdkServer)
+		pb.RegisterNvmeRemoteControllerServiceServer(s, backendOpiSpdkServer)
+		pb.RegisterNullVolumeServiceServer(s, backendOpiSpdkServer)
+		pb.RegisterMallocVolumeServiceServer(s, backendOpiSpdkServer)
+		pb.RegisterAioVolumeServiceServer(s, backendOpiSpdkServer)
+		pb.RegisterMiddleendEncryptionServiceServer(s, middleendOpiSpdkServer)
+		pc.RegisterInventoryServiceServer(s, &inventory.Server{})
+		ps.RegisterIPsecServiceServer(s, &ipsec.Server{})
+		healthpb.RegisterHealthServer(s, healthServer)
+
+		if cfg.Features.Reflection {
+			reflection.Register(s)
+		}
+		return s
+	}
+	servers := map[net.Listener]*grpc.Server{lis: newServer(tlsOptions...)}
+	if cfg.GRPC.UnixSocket != "" {
+		// plaintext, the socket is only accessible to the bridge user
+		unixLis, err := listenUnix(cfg.GRPC.UnixSocket)
+		if err != nil {
+			log.Panicf("failed to listen: %v", err)
+		}
+		servers[unixLis] = newServer()
+	}
+
+	lc.onShutdown("gRPC server", func(ctx context.Context) error {
+		stopped := make(chan struct{})
+		go func() {
+			var wg sync.WaitGroup
+			for _, s := range servers {
+				wg.Add(1)
+				go func(s *grpc.Server) {
+					defer wg.Done()
+					s.GracefulStop()
+				}(s)
+			}
+			wg.Wait()
+			close(stopped)
+		}()
+		err := frontendOpiMarvellServer.Shutdown(ctx)
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			// cancels the in-flight calls, multi-step calls roll back
+			for _, s := range servers {
+				s.Stop()
+			}
+			<-stopped
+		}
+		return err
+	})
+
+	for lis, s := range servers {
+		logger.Info("gRPC server listening", "address", lis.Addr())
+		go func(lis net.Listener, s *grpc.Server) {
+			if err := s.Serve(lis); err != nil {
+				errs <- fmt.Errorf("failed to serve: %w", err)
+			}
+		}(lis, s)
+	}
+	return func() {
+		healthServer.Shutdown()
+		frontendOpiMarvellServer.StopWatches()
 	}
 }
 
-func runGatewayServer(grpcPort int, httpPort int) {
-	ctx := context.Background()
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+// runGatewayServer starts serving the HTTP gateway, serving errors are sent to errs
+func runGatewayServer(lc *lifecycle, errs chan<- error, cfg *config.Config, registry *prometheus.Registry) {
+	// closes the gateway connections to the gRPC server on shutdown
+	ctx, cancel := context.WithCancel(context.Background())
 
 	// Register gRPC server endpoint
 	// Note: Make sure the gRPC server is running properly and accessible
-	mux := runtime.NewServeMux()
-	opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
-	endpoint := fmt.Sprintf("localhost:%d", grpcPort)
+	mux := runtime.NewServeMux(gatewayMuxOptions()...)
+	endpoint, opts, err := gatewayEndpoint(cfg)
+	if err != nil {
+		log.Panicf("cannot configure the gateway connection to %s: %v", endpoint, err)
+	}
 	registerGatewayHandler(ctx, mux, endpoint, opts, pc.RegisterInventoryServiceHandlerFromEndpoint, "inventory")
 
 	registerGatewayHandler(ctx, mux, endpoint, opts, pb.RegisterAioVolumeServiceHandlerFromEndpoint, "backend aio")
@@ -169,19 +325,49 @@ func runGatewayServer(grpcPort int, httpPort int) {
 	registerGatewayHandler(ctx, mux, endpoint, opts, pb.RegisterFrontendVirtioBlkServiceHandlerFromEndpoint, "frontend virtio-blk")
 	registerGatewayHandler(ctx, mux, endpoint, opts, pb.RegisterFrontendVirtioScsiServiceHandlerFromEndpoint, "frontend virtio-scsi")
 	registerGatewayHandler(ctx, mux, endpoint, opts, pb.RegisterFrontendNvmeServiceHandlerFromEndpoint, "frontend nvme")
+	registerGatewayHandler(ctx, mux, endpoint, opts, mb.RegisterNvmeMetadataServiceHandlerFromEndpoint, "frontend nvme metadata")
+	registerGatewayHandler(ctx, mux, endpoint, opts, mb.RegisterNvmeCapacityServiceHandlerFromEndpoint, "frontend nvme capacity")
+	registerGatewayHandler(ctx, mux, endpoint, opts, mb.RegisterNvmeStateServiceHandlerFromEndpoint, "frontend nvme state")
+	if cfg.Features.Watch {
+		registerGatewayHandler(ctx, mux, endpoint, opts, mb.RegisterNvmeWatchServiceHandlerFromEndpoint, "frontend nvme watch")
+	}
+	registerGatewayHandler(ctx, mux, endpoint, opts, registerOperationsHandlerFromEndpoint, "operations")
+	registerGatewayHandler(ctx, mux, endpoint, opts, healthHandlersFromEndpoint(cfg.Health.CheckTimeout), "health")
+
+	if cfg.Metrics.Port == 0 {
+		registerMetricsHandler(mux, registry)
+	}
+
+	tlsConfig, err := gatewayServerTLSConfig(cfg)
+	if err != nil {
+		log.Panicf("cannot configure the gateway TLS: %v", err)
+	}
 
 	// Start HTTP server (and proxy calls to gRPC server endpoint)
-	log.Printf("HTTP Server listening at %v", httpPort)
+	logger.Info("HTTP Server listening", "port", cfg.HTTP.Port, "tls", tlsConfig != nil, "backend", endpoint)
 	server := &http.Server{
-		Addr:         fmt.Sprintf(":%d", httpPort),
+		Addr:         fmt.Sprintf(":%d", cfg.HTTP.Port),
 		Handler:      mux,
-		ReadTimeout:  5 * time.Second,
-		WriteTimeout: 10 * time.Second,
-	}
-	err := server.ListenAndServe()
-	if err != nil {
-		log.Panic("cannot start HTTP gateway server")
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		TLSConfig:    tlsConfig,
 	}
+	lc.onShutdown("HTTP gateway", func(ctx context.Context) error {
+		defer cancel()
+		return server.Shutdown(ctx)
+	})
+	go func() {
+		var err error
+		if tlsConfig != nil {
+			// the certificates are already in TLSConfig
+			err = server.ListenAndServeTLS("", "")
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("cannot start HTTP gateway server: %w", err)
+		}
+	}()
 }
 
 type registerHandlerFunc func(context.Context, *runtime.ServeMux, string, []grpc.DialOption) error
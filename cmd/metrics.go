@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetricgrpc"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	sdkresource "go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+)
+
+// initMeterProvider exports OTel metrics to the same OTLP collector as the traces
+func initMeterProvider(service string) *sdkmetric.MeterProvider {
+	exporter, err := otlpmetricgrpc.New(context.Background())
+	if err != nil {
+		log.Panicf("OTLP Metric gRPC Creation: %v", err)
+	}
+	mp := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(sdkmetric.NewPeriodicReader(exporter)),
+		sdkmetric.WithResource(sdkresource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String(service),
+		)),
+	)
+	otel.SetMeterProvider(mp)
+	return mp
+}
+
+// newMetricsRegistry creates the registry served on /metrics
+func newMetricsRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return registry
+}
+
+// registerMetricsHandler serves /metrics next to the HTTP gateway
+func registerMetricsHandler(mux *runtime.ServeMux, registry *prometheus.Registry) {
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	err := mux.HandlePath(http.MethodGet, "/metrics", func(w http.ResponseWriter, r *http.Request, _ map[string]string) {
+		handler.ServeHTTP(w, r)
+	})
+	if err != nil {
+		log.Panicf("cannot register metrics handler: %v", err)
+	}
+}
+
+// runMetricsServer starts serving /metrics on its own port, serving errors
+// are sent to errs
+func runMetricsServer(lc *lifecycle, errs chan<- error, metricsPort int, registry *prometheus.Registry) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	logger.Info("Metrics server listening", "port", metricsPort)
+	server := &http.Server{
+		Addr:         fmt.Sprintf(":%d", metricsPort),
+		Handler:      mux,
+		ReadTimeout:  5 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	lc.onShutdown("metrics server", server.Shutdown)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- fmt.Errorf("cannot start metrics server: %w", err)
+		}
+	}()
+}
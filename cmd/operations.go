@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// main is the main package of the application
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+)
+
+// registerOperationsHandlerFromEndpoint exposes google.longrunning.Operations
+// through the gateway. The generated longrunning package has no gateway
+// handlers, so the routes are registered by hand.
+func registerOperationsHandlerFromEndpoint(ctx context.Context, mux *runtime.ServeMux, endpoint string, opts []grpc.DialOption) error {
+	conn, err := grpc.DialContext(ctx, endpoint, opts...)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		if err := conn.Close(); err != nil {
+			logger.Error("Failed to close conn", "endpoint", endpoint, "error", err)
+		}
+	}()
+	client := longrunningpb.NewOperationsClient(conn)
+
+	routes := []struct {
+		method  string
+		pattern string
+		rpc     string
+		call    func(context.Context, *http.Request, map[string]string) (proto.Message, error)
+	}{
+		{http.MethodGet, "/v1/operations", "/google.longrunning.Operations/ListOperations", func(ctx context.Context, r *http.Request, _ map[string]string) (proto.Message, error) {
+			query := r.URL.Query()
+			pageSize, err := strconv.ParseInt(query.Get("page_size"), 10, 32)
+			if err != nil && query.Get("page_size") != "" {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid page_size: %v", err)
+			}
+			return client.ListOperations(ctx, &longrunningpb.ListOperationsRequest{
+				Name:      "operations",
+				Filter:    query.Get("filter"),
+				PageSize:  int32(pageSize),
+				PageToken: query.Get("page_token"),
+			})
+		}},
+		{http.MethodGet, "/v1/{name=operations/*}", "/google.longrunning.Operations/GetOperation", func(ctx context.Context, _ *http.Request, params map[string]string) (proto.Message, error) {
+			return client.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: params["name"]})
+		}},
+		{http.MethodDelete, "/v1/{name=operations/*}", "/google.longrunning.Operations/DeleteOperation", func(ctx context.Context, _ *http.Request, params map[string]string) (proto.Message, error) {
+			return client.DeleteOperation(ctx, &longrunningpb.DeleteOperationRequest{Name: params["name"]})
+		}},
+		{http.MethodPost, "/v1/{name=operations/*}:cancel", "/google.longrunning.Operations/CancelOperation", func(ctx context.Context, _ *http.Request, params map[string]string) (proto.Message, error) {
+			return client.CancelOperation(ctx, &longrunningpb.CancelOperationRequest{Name: params["name"]})
+		}},
+		{http.MethodPost, "/v1/{name=operations/*}:wait", "/google.longrunning.Operations/WaitOperation", func(ctx context.Context, r *http.Request, params map[string]string) (proto.Message, error) {
+			in := &longrunningpb.WaitOperationRequest{Name: params["name"]}
+			if value := r.URL.Query().Get("timeout"); value != "" {
+				timeout, err := time.ParseDuration(value)
+				if err != nil {
+					return nil, status.Errorf(codes.InvalidArgument, "invalid timeout: %v", err)
+				}
+				in.Timeout = durationpb.New(timeout)
+			}
+			return client.WaitOperation(ctx, in)
+		}},
+	}
+	for _, route := range routes {
+		call, rpc := route.call, route.rpc
+		err := mux.HandlePath(route.method, route.pattern, func(w http.ResponseWriter, r *http.Request, params map[string]string) {
+			ctx, cancel := context.WithCancel(r.Context())
+			defer cancel()
+			_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+			ctx, err := runtime.AnnotateContext(ctx, mux, r, rpc)
+			if err != nil {
+				runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+				return
+			}
+			resp, err := call(ctx, r, params)
+			if err != nil {
+				runtime.HTTPError(ctx, mux, outboundMarshaler, w, r, err)
+				return
+			}
+			runtime.ForwardResponseMessage(ctx, mux, outboundMarshaler, w, r, resp)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
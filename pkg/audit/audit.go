@@ -0,0 +1,158 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit records the mutating storage operations in a tamper-evident,
+// hash-chained log
+package audit
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Record is a single audit log entry. Every record carries the hash of its
+// predecessor, so that removing or modifying a record breaks the chain.
+type Record struct {
+	Sequence uint64          `json:"sequence"`
+	Time     time.Time       `json:"time"`
+	Caller   string          `json:"caller"`
+	Method   string          `json:"method"`
+	Resource string          `json:"resource,omitempty"`
+	Request  json.RawMessage `json:"request,omitempty"`
+	SdkCalls []SdkCall       `json:"sdk_calls,omitempty"`
+	Code     string          `json:"code"`
+	Error    string          `json:"error,omitempty"`
+	PrevHash string          `json:"prev_hash"`
+	Hash     string          `json:"hash"`
+}
+
+// SdkCall is a Marvell SDK call issued while serving the audited RPC
+type SdkCall struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Status *int64          `json:"status,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+// Sink stores the encoded audit records
+type Sink interface {
+	// Write appends a single encoded record
+	Write(record []byte) error
+	// Last returns the last stored record, nil if there is none
+	Last() (*Record, error)
+	// Close releases the sink resources
+	Close() error
+}
+
+// Logger chains the audit records and writes them to all sinks
+type Logger struct {
+	mutex    sync.Mutex
+	sinks    []Sink
+	sequence uint64
+	hash     string
+}
+
+// NewLogger creates a logger continuing the chain of the first sink holding records
+func NewLogger(sinks ...Sink) (*Logger, error) {
+	l := &Logger{sinks: sinks}
+	for _, sink := range sinks {
+		last, err := sink.Last()
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			l.sequence, l.hash = last.Sequence, last.Hash
+			break
+		}
+	}
+	return l, nil
+}
+
+// Emit links record to the chain and writes it to all sinks
+func (l *Logger) Emit(record *Record) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.sequence++
+	record.Sequence = l.sequence
+	record.PrevHash = l.hash
+	hash, err := hashRecord(record)
+	if err != nil {
+		return err
+	}
+	record.Hash = hash
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	// the chain moves on even if a sink fails, the gap is reported by Verify
+	l.hash = hash
+	var errs []error
+	for _, sink := range l.sinks {
+		if err := sink.Write(line); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes all sinks
+func (l *Logger) Close() error {
+	var errs []error
+	for _, sink := range l.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// hashRecord returns the hex encoded SHA-256 of the record without its own hash
+func hashRecord(record *Record) (string, error) {
+	unhashed := *record
+	unhashed.Hash = ""
+	data, err := json.Marshal(&unhashed)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Verify checks the chain of the records read from r, one JSON record per
+// line. prevHash is the hash of the record preceding the first one, empty
+// at the start of the log. It returns the hash of the last record, so that
+// rotated files can be verified from the oldest to the newest.
+func Verify(r io.Reader, prevHash string) (string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRecordSize)
+	line := 0
+	for scanner.Scan() {
+		line++
+		record := new(Record)
+		if err := json.Unmarshal(scanner.Bytes(), record); err != nil {
+			return "", fmt.Errorf("line %d: %w", line, err)
+		}
+		if record.PrevHash != prevHash {
+			return "", fmt.Errorf("line %d: record %d is not chained to the previous record", line, record.Sequence)
+		}
+		hash, err := hashRecord(record)
+		if err != nil {
+			return "", fmt.Errorf("line %d: %w", line, err)
+		}
+		if hash != record.Hash {
+			return "", fmt.Errorf("line %d: record %d was modified", line, record.Sequence)
+		}
+		prevHash = record.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return prevHash, nil
+}
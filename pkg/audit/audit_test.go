@@ -0,0 +1,407 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit records the mutating storage operations in a tamper-evident,
+// hash-chained log
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/auth"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+// memorySink keeps the records in memory
+type memorySink struct {
+	lines [][]byte
+}
+
+func (m *memorySink) Write(record []byte) error {
+	m.lines = append(m.lines, append([]byte(nil), record...))
+	return nil
+}
+
+func (m *memorySink) Last() (*Record, error) {
+	if len(m.lines) == 0 {
+		return nil, nil
+	}
+	record := new(Record)
+	return record, json.Unmarshal(m.lines[len(m.lines)-1], record)
+}
+
+func (m *memorySink) Close() error {
+	return nil
+}
+
+func (m *memorySink) records(t *testing.T) []*Record {
+	records := []*Record{}
+	for _, line := range m.lines {
+		record := new(Record)
+		if err := json.Unmarshal(line, record); err != nil {
+			t.Fatal(err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func emitRecords(t *testing.T, l *Logger, resources ...string) {
+	for _, resource := range resources {
+		if err := l.Emit(&Record{Method: "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeSubsystem", Resource: resource, Code: "OK"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAudit_Verify(t *testing.T) {
+	tests := map[string]struct {
+		tamper  func(lines [][]byte) [][]byte
+		wantErr string
+	}{
+		"valid chain": {
+			tamper:  func(lines [][]byte) [][]byte { return lines },
+			wantErr: "",
+		},
+		"modified record": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte("subsys1"), []byte("subsys9"), 1)
+				return lines
+			},
+			wantErr: "line 2: record 2 was modified",
+		},
+		"removed record": {
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+			wantErr: "line 2: record 3 is not chained to the previous record",
+		},
+		"reordered records": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1], lines[2] = lines[2], lines[1]
+				return lines
+			},
+			wantErr: "line 2: record 3 is not chained to the previous record",
+		},
+		"invalid record": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[0] = []byte("{")
+				return lines
+			},
+			wantErr: "line 1: unexpected end of JSON input",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sink := &memorySink{}
+			l, err := NewLogger(sink)
+			if err != nil {
+				t.Fatal(err)
+			}
+			emitRecords(t, l, "nvmeSubsystems/subsys0", "nvmeSubsystems/subsys1", "nvmeSubsystems/subsys2")
+			last := sink.records(t)[2].Hash
+
+			lines := tt.tamper(sink.lines)
+			hash, err := Verify(bytes.NewReader(append(bytes.Join(lines, []byte("\n")), '\n')), "")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Error("expected no error, received", err)
+				}
+				if hash != last {
+					t.Error("hash: expected", last, "received", hash)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+		})
+	}
+}
+
+func TestAudit_FileSink(t *testing.T) {
+	tests := map[string]struct {
+		maxSize    int64
+		maxBackups int
+		records    int
+		files      []string
+	}{
+		"no rotation": {
+			maxSize:    0,
+			maxBackups: 2,
+			records:    5,
+			files:      []string{"audit.log"},
+		},
+		"rotation": {
+			maxSize:    1000,
+			maxBackups: 10,
+			records:    5,
+			files:      []string{"audit.log.4", "audit.log.3", "audit.log.2", "audit.log.1", "audit.log"},
+		},
+		"rotation beyond backups": {
+			maxSize:    500,
+			maxBackups: 1,
+			records:    5,
+			files:      []string{"audit.log.1", "audit.log"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "audit.log")
+
+			// reopen the log in between, the chain must go on
+			for i := 0; i < 2; i++ {
+				sink, err := NewFileSink(path, tt.maxSize, tt.maxBackups)
+				if err != nil {
+					t.Fatal(err)
+				}
+				l, err := NewLogger(sink)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for j := 0; j < tt.records; j++ {
+					emitRecords(t, l, strings.Repeat("x", 100))
+				}
+				if err := l.Close(); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != len(tt.files) {
+				t.Error("files: expected", tt.files, "received", entries)
+			}
+			sequence := uint64(0)
+			for _, name := range tt.files {
+				data, err := os.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+					record := new(Record)
+					if err := json.Unmarshal(line, record); err != nil {
+						t.Fatal(err)
+					}
+					if sequence != 0 && record.Sequence != sequence+1 {
+						t.Error("sequence: expected", sequence+1, "received", record.Sequence)
+					}
+					sequence = record.Sequence
+				}
+			}
+			if sequence != uint64(2*tt.records) {
+				t.Error("last sequence: expected", 2*tt.records, "received", sequence)
+			}
+			if tt.maxBackups >= len(tt.files) {
+				prevHash := ""
+				for _, name := range tt.files {
+					f, err := os.Open(filepath.Join(dir, name))
+					if err != nil {
+						t.Fatal(err)
+					}
+					prevHash, err = Verify(f, prevHash)
+					_ = f.Close()
+					if err != nil {
+						t.Error("expected valid chain in", name, "received", err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestAudit_UnaryServerInterceptor(t *testing.T) {
+	tests := map[string]struct {
+		method  string
+		ctx     context.Context
+		req     interface{}
+		handler grpc.UnaryHandler
+		out     *Record
+	}{
+		"not mutating": {
+			method: "/opi_api.storage.v1.FrontendNvmeService/GetNvmeSubsystem",
+			ctx:    context.Background(),
+			req:    &pb.GetNvmeSubsystemRequest{Name: "nvmeSubsystems/subsys0"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &pb.NvmeSubsystem{Name: "nvmeSubsystems/subsys0"}, nil
+			},
+			out: nil,
+		},
+		"not storage": {
+			method: "/google.longrunning.Operations/DeleteOperation",
+			ctx:    context.Background(),
+			req:    &emptypb.Empty{},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &emptypb.Empty{}, nil
+			},
+			out: nil,
+		},
+		"create with handler hooks": {
+			method: "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeController",
+			ctx:    metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret")),
+			req: &pb.CreateNvmeControllerRequest{
+				Parent:         "nvmeSubsystems/subsys0",
+				NvmeController: &pb.NvmeController{Spec: &pb.NvmeControllerSpec{NvmeControllerId: proto.Int32(17)}},
+			},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				SetResource(ctx, "nvmeSubsystems/subsys0/nvmeControllers/ctrl0")
+				sdkStatus := int64(1)
+				AddSdkCall(ctx, "mrvl_nvm_ctrlr_create", map[string]int{"pf_id": 0}, &sdkStatus, nil)
+				return nil, status.Error(codes.InvalidArgument, "Could not create CTRL: 17")
+			},
+			out: &Record{
+				Caller:   "token:sha256:bffde20413347b7a",
+				Method:   "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeController",
+				Resource: "nvmeSubsystems/subsys0/nvmeControllers/ctrl0",
+				Request:  json.RawMessage(`{"parent":"nvmeSubsystems/subsys0","nvmeController":{"spec":{"nvmeControllerId":17}}}`),
+				SdkCalls: []SdkCall{{Method: "mrvl_nvm_ctrlr_create", Params: json.RawMessage(`{"pf_id":0}`), Status: proto.Int64(1)}},
+				Code:     "InvalidArgument",
+				Error:    "Could not create CTRL: 17",
+			},
+		},
+		"bridge specific": {
+			method: "/opi_marvell_bridge.v1.NvmeStateService/DisableNvmeNamespace",
+			ctx:    context.Background(),
+			req:    &mb.DisableNvmeNamespaceRequest{Name: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				SetResource(ctx, "nvmeSubsystems/subsys0/nvmeNamespaces/ns0")
+				return &pb.NvmeNamespace{Name: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0"}, nil
+			},
+			out: &Record{
+				Caller:   "unknown",
+				Method:   "/opi_marvell_bridge.v1.NvmeStateService/DisableNvmeNamespace",
+				Resource: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0",
+				Request:  json.RawMessage(`{"name":"nvmeSubsystems/subsys0/nvmeNamespaces/ns0"}`),
+				Code:     "OK",
+			},
+		},
+		"authenticated caller": {
+			method: "/opi_api.storage.v1.FrontendNvmeService/DeleteNvmeNamespace",
+			ctx: auth.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer secret")),
+				&auth.Identity{Method: "token", Subject: "alice", Roles: []string{"admin"}}),
+			req: &pb.DeleteNvmeNamespaceRequest{Name: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &emptypb.Empty{}, nil
+			},
+			out: &Record{
+				Caller:   "token:alice",
+				Method:   "/opi_api.storage.v1.FrontendNvmeService/DeleteNvmeNamespace",
+				Resource: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0",
+				Request:  json.RawMessage(`{"name":"nvmeSubsystems/subsys0/nvmeNamespaces/ns0"}`),
+				Code:     "OK",
+			},
+		},
+		"delete with failed SDK call": {
+			method: "/opi_api.storage.v1.FrontendNvmeService/DeleteNvmeNamespace",
+			ctx:    context.Background(),
+			req:    &pb.DeleteNvmeNamespaceRequest{Name: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0"},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				AddSdkCall(ctx, "mrvl_nvm_subsys_remove_ns", nil, nil, errors.New("myopierr"))
+				return nil, errors.New("myopierr")
+			},
+			out: &Record{
+				Caller:   "unknown",
+				Method:   "/opi_api.storage.v1.FrontendNvmeService/DeleteNvmeNamespace",
+				Resource: "nvmeSubsystems/subsys0/nvmeNamespaces/ns0",
+				Request:  json.RawMessage(`{"name":"nvmeSubsystems/subsys0/nvmeNamespaces/ns0"}`),
+				SdkCalls: []SdkCall{{Method: "mrvl_nvm_subsys_remove_ns", Error: "myopierr"}},
+				Code:     "Unknown",
+				Error:    "myopierr",
+			},
+		},
+		"bridge service": {
+			method: "/opi_marvell_bridge.v1.NvmeMetadataService/UpdateNvmeResourceMetadata",
+			ctx:    context.Background(),
+			req: &mb.UpdateNvmeResourceMetadataRequest{
+				NvmeResourceMetadata: &mb.NvmeResourceMetadata{Name: "nvmeSubsystems/subsys0", Labels: map[string]string{"vm": "vm-1"}},
+			},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				SetResource(ctx, "nvmeSubsystems/subsys0")
+				return &mb.NvmeResourceMetadata{Name: "nvmeSubsystems/subsys0", Labels: map[string]string{"vm": "vm-1"}}, nil
+			},
+			out: &Record{
+				Caller:   "unknown",
+				Method:   "/opi_marvell_bridge.v1.NvmeMetadataService/UpdateNvmeResourceMetadata",
+				Resource: "nvmeSubsystems/subsys0",
+				Request:  json.RawMessage(`{"nvmeResourceMetadata":{"name":"nvmeSubsystems/subsys0","labels":{"vm":"vm-1"}}}`),
+				Code:     "OK",
+			},
+		},
+		"resource from response": {
+			method: "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeSubsystem",
+			ctx:    context.Background(),
+			req:    &pb.CreateNvmeSubsystemRequest{NvmeSubsystem: &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi3"}}},
+			handler: func(ctx context.Context, req interface{}) (interface{}, error) {
+				return &pb.NvmeSubsystem{Name: "nvmeSubsystems/subsys0"}, nil
+			},
+			out: &Record{
+				Caller:   "unknown",
+				Method:   "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeSubsystem",
+				Resource: "nvmeSubsystems/subsys0",
+				Request:  json.RawMessage(`{"nvmeSubsystem":{"spec":{"nqn":"nqn.2022-09.io.spdk:opi3"}}}`),
+				Code:     "OK",
+			},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sink := &memorySink{}
+			l, err := NewLogger(sink)
+			if err != nil {
+				t.Fatal(err)
+			}
+			interceptor := l.UnaryServerInterceptor()
+			_, _ = interceptor(tt.ctx, tt.req, &grpc.UnaryServerInfo{FullMethod: tt.method}, tt.handler)
+
+			records := sink.records(t)
+			if tt.out == nil {
+				if len(records) != 0 {
+					t.Error("expected no record, received", records)
+				}
+				return
+			}
+			if len(records) != 1 {
+				t.Fatal("records: expected 1 received", len(records))
+			}
+			record := records[0]
+			if record.Time.IsZero() || record.Sequence != 1 || record.PrevHash != "" || record.Hash == "" {
+				t.Error("chain fields not set in", record)
+			}
+			record.Time, record.Sequence, record.Hash = tt.out.Time, 0, ""
+			compact := new(bytes.Buffer)
+			if err := json.Compact(compact, record.Request); err != nil {
+				t.Fatal(err)
+			}
+			record.Request = compact.Bytes()
+			if !reflect.DeepEqual(record, tt.out) {
+				t.Errorf("record: expected %+v received %+v", tt.out, record)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit records the mutating storage operations in a tamper-evident,
+// hash-chained log
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/auth"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var logger = logging.Logger("audit")
+
+// auditedServicePrefixes select the audited services: the OPI storage
+// services and the bridge specific ones
+var auditedServicePrefixes = []string{"/opi_api.storage.", "/opi_marvell_bridge."}
+
+// mutatingPrefixes are the method name prefixes of the audited RPCs
+var mutatingPrefixes = []string{"Create", "Update", "Delete", "Enable", "Disable"}
+
+// entry collects what the handlers report about the audited RPC
+type entry struct {
+	mutex    sync.Mutex
+	resource string
+	calls    []SdkCall
+}
+
+type entryContextKey struct{}
+
+func entryFromContext(ctx context.Context) *entry {
+	e, _ := ctx.Value(entryContextKey{}).(*entry)
+	return e
+}
+
+// SetResource records the name of the resource the audited RPC acts on,
+// for handlers which compute it, e.g. from a client provided ID
+func SetResource(ctx context.Context, name string) {
+	if e := entryFromContext(ctx); e != nil {
+		e.mutex.Lock()
+		e.resource = name
+		e.mutex.Unlock()
+	}
+}
+
+// AddSdkCall records an SDK call issued while serving the audited RPC.
+// status is nil when the call failed before returning one.
+func AddSdkCall(ctx context.Context, method string, params interface{}, status *int64, err error) {
+	e := entryFromContext(ctx)
+	if e == nil {
+		return
+	}
+	call := SdkCall{Method: method, Status: status}
+	if data, merr := json.Marshal(params); merr == nil && string(data) != "null" {
+		call.Params = data
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	e.mutex.Lock()
+	e.calls = append(e.calls, call)
+	e.mutex.Unlock()
+}
+
+func isMutating(fullMethod string) bool {
+	audited := false
+	for _, prefix := range auditedServicePrefixes {
+		audited = audited || strings.HasPrefix(fullMethod, prefix)
+	}
+	if !audited {
+		return false
+	}
+	name := path.Base(fullMethod)
+	for _, prefix := range mutatingPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// UnaryServerInterceptor emits an audit record for every mutating storage RPC
+func (l *Logger) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !isMutating(info.FullMethod) {
+			return handler(ctx, req)
+		}
+		e := &entry{}
+		start := time.Now()
+		resp, err := handler(context.WithValue(ctx, entryContextKey{}, e), req)
+
+		e.mutex.Lock()
+		record := &Record{
+			Time:     start.UTC(),
+			Caller:   Caller(ctx),
+			Method:   info.FullMethod,
+			Resource: e.resource,
+			SdkCalls: e.calls,
+			Code:     status.Code(err).String(),
+		}
+		e.mutex.Unlock()
+		if record.Resource == "" {
+			record.Resource = resourceOf(resp, "name")
+		}
+		if record.Resource == "" {
+			record.Resource = resourceOf(req, "name", "parent")
+		}
+		if msg, ok := req.(proto.Message); ok {
+			if data, merr := protojson.Marshal(logging.Redact(msg)); merr == nil {
+				record.Request = data
+			}
+		}
+		if err != nil {
+			record.Error = status.Convert(err).Message()
+		}
+		if aerr := l.Emit(record); aerr != nil {
+			logger.ErrorContext(ctx, "unable to write audit record", "method", info.FullMethod, "resource", record.Resource, "error", aerr)
+		}
+		return resp, err
+	}
+}
+
+// Caller identifies the client of ctx by its authenticated identity, by its
+// TLS client certificate subject, by a fingerprint of its bearer token or,
+// at last, by its address
+func Caller(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok {
+		return identity.String()
+	}
+	p, ok := peer.FromContext(ctx)
+	if ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.PeerCertificates) > 0 {
+			return "tls:" + info.State.PeerCertificates[0].Subject.String()
+		}
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			// never store the token itself, only enough to correlate the calls
+			sum := sha256.Sum256([]byte(values[0]))
+			return "token:sha256:" + hex.EncodeToString(sum[:8])
+		}
+	}
+	if ok && p.Addr != nil {
+		return "peer:" + p.Addr.String()
+	}
+	return "unknown"
+}
+
+// resourceOf returns the first set string field of a request or response
+func resourceOf(v interface{}, fields ...protoreflect.Name) string {
+	msg, ok := v.(proto.Message)
+	if !ok || msg == nil || !msg.ProtoReflect().IsValid() {
+		return ""
+	}
+	m := msg.ProtoReflect()
+	for _, field := range fields {
+		fd := m.Descriptor().Fields().ByName(field)
+		if fd != nil && !fd.IsList() && !fd.IsMap() && m.Has(fd) {
+			if name, ok := m.Get(fd).Interface().(string); ok {
+				return name
+			}
+		}
+	}
+	return ""
+}
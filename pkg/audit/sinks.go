@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package audit records the mutating storage operations in a tamper-evident,
+// hash-chained log
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/go-redis/redis"
+)
+
+// maxRecordSize bounds a single encoded record
+const maxRecordSize = 4 * 1024 * 1024
+
+// FileSink appends records to a local file, rotating it to path.1 ...
+// path.N once it grows over the maximum size
+type FileSink struct {
+	mutex      sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	size       int64
+}
+
+// NewFileSink opens or creates the audit file at path. A maxSize of 0
+// disables the rotation.
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	f := &FileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *FileSink) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	f.file, f.size = file, info.Size()
+	return nil
+}
+
+// Write appends a record, the file is synced so that no record is lost on a crash
+func (f *FileSink) Write(record []byte) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(record))+1 > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := f.file.Write(append(record, '\n'))
+	f.size += int64(n)
+	if err != nil {
+		return err
+	}
+	return f.file.Sync()
+}
+
+func (f *FileSink) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	if f.maxBackups <= 0 {
+		if err := os.Remove(f.path); err != nil {
+			return err
+		}
+		return f.open()
+	}
+	for i := f.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(f.backupPath(i), f.backupPath(i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	if err := os.Rename(f.path, f.backupPath(1)); err != nil {
+		return err
+	}
+	return f.open()
+}
+
+func (f *FileSink) backupPath(i int) string {
+	return fmt.Sprintf("%s.%d", f.path, i)
+}
+
+// Last returns the last record of the current file, or of the newest backup
+// right after a rotation
+func (f *FileSink) Last() (*Record, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	for _, path := range []string{f.path, f.backupPath(1)} {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		data = bytes.TrimRight(data, "\n")
+		if len(data) == 0 {
+			continue
+		}
+		record := new(Record)
+		if err := json.Unmarshal(data[bytes.LastIndexByte(data, '\n')+1:], record); err != nil {
+			return nil, fmt.Errorf("unable to decode the last audit record of %s: %w", path, err)
+		}
+		return record, nil
+	}
+	return nil, nil
+}
+
+// Close closes the file
+func (f *FileSink) Close() error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return f.file.Close()
+}
+
+// RedisSink adds records to a Redis stream
+type RedisSink struct {
+	client redis.UniversalClient
+	stream string
+	maxLen int64
+}
+
+// recordField is the stream entry field holding the encoded record
+const recordField = "record"
+
+// NewRedisSink creates a sink adding records to stream, trimmed to about
+// maxLen entries, 0 keeps all entries
+func NewRedisSink(client redis.UniversalClient, stream string, maxLen int64) *RedisSink {
+	return &RedisSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+// Write adds a record to the stream
+func (r *RedisSink) Write(record []byte) error {
+	return r.client.XAdd(&redis.XAddArgs{
+		Stream:       r.stream,
+		MaxLenApprox: r.maxLen,
+		Values:       map[string]interface{}{recordField: string(record)},
+	}).Err()
+}
+
+// Last returns the newest record of the stream
+func (r *RedisSink) Last() (*Record, error) {
+	messages, err := r.client.XRevRangeN(r.stream, "+", "-", 1).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(messages) == 0 {
+		return nil, nil
+	}
+	value, ok := messages[0].Values[recordField].(string)
+	if !ok {
+		return nil, fmt.Errorf("stream entry %s has no audit record", messages[0].ID)
+	}
+	record := new(Record)
+	if err := json.Unmarshal([]byte(value), record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// Close closes the Redis client
+func (r *RedisSink) Close() error {
+	return r.client.Close()
+}
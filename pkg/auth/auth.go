@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// ForwardedClientCertKey is the metadata key holding the common name of
+// the client certificate verified by a trusted proxy, e.g. the HTTP gateway
+const ForwardedClientCertKey = "x-opi-forwarded-client-cert"
+
+// errNoCredentials is returned by authenticators finding none of their
+// credentials, so that the next authenticator is tried
+var errNoCredentials = errors.New("no credentials")
+
+// Identity is an authenticated caller
+type Identity struct {
+	// Method is mtls, token or jwt
+	Method  string
+	Subject string
+	Roles   []string
+	// Tenant owns the resources created by the caller, empty for the
+	// operators of the bridge
+	Tenant string
+}
+
+// String returns the method and the subject, e.g. jwt:alice
+func (i *Identity) String() string {
+	return i.Method + ":" + i.Subject
+}
+
+// Authenticator authenticates the caller of ctx, it returns
+// errNoCredentials if the caller did not present its kind of credentials
+type Authenticator interface {
+	Authenticate(ctx context.Context) (*Identity, error)
+}
+
+type identityContextKey struct{}
+
+// NewContext returns a copy of ctx carrying the identity
+func NewContext(ctx context.Context, identity *Identity) context.Context {
+	return context.WithValue(ctx, identityContextKey{}, identity)
+}
+
+// FromContext returns the identity of the authenticated caller of ctx
+func FromContext(ctx context.Context) (*Identity, bool) {
+	identity, ok := ctx.Value(identityContextKey{}).(*Identity)
+	return identity, ok
+}
+
+// bearerToken returns the bearer token of the authorization metadata
+func bearerToken(ctx context.Context) (string, bool) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, value := range md.Get("authorization") {
+		scheme, token, found := strings.Cut(value, " ")
+		if found && strings.EqualFold(scheme, "bearer") && token != "" {
+			return token, true
+		}
+	}
+	return "", false
+}
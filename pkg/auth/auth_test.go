@@ -0,0 +1,408 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func withToken(ctx context.Context, token string) context.Context {
+	return metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func withClientCert(ctx context.Context, commonName string) context.Context {
+	return peer.NewContext(ctx, &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 4242},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{{Subject: pkix.Name{CommonName: commonName}}}},
+		}},
+	})
+}
+
+func TestAuth_Policy(t *testing.T) {
+	policy, err := NewPolicy(DefaultRoles(), []string{"grpc.health.v1.Health/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string]struct {
+		roles  []string
+		method string
+		public bool
+		out    bool
+	}{
+		"viewer gets": {
+			roles:  []string{"viewer"},
+			method: "/opi_api.storage.v1.FrontendNvmeService/GetNvmeController",
+			out:    true,
+		},
+		"viewer stats": {
+			roles:  []string{"viewer"},
+			method: "/opi_api.storage.v1.FrontendNvmeService/StatsNvmeController",
+			out:    true,
+		},
+		"viewer creates": {
+			roles:  []string{"viewer"},
+			method: "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeController",
+			out:    false,
+		},
+		"admin deletes": {
+			roles:  []string{"viewer", "admin"},
+			method: "/opi_api.storage.v1.FrontendNvmeService/DeleteNvmeController",
+			out:    true,
+		},
+		"unknown role": {
+			roles:  []string{"operator"},
+			method: "/opi_api.storage.v1.FrontendNvmeService/GetNvmeController",
+			out:    false,
+		},
+		"public health": {
+			method: "/grpc.health.v1.Health/Check",
+			public: true,
+			out:    false,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			if public := policy.Public(tt.method); public != tt.public {
+				t.Errorf("expected public %v, received %v", tt.public, public)
+			}
+			if allowed := policy.Allowed(tt.roles, tt.method); allowed != tt.out {
+				t.Errorf("expected allowed %v, received %v", tt.out, allowed)
+			}
+		})
+	}
+}
+
+func TestAuth_NewPolicy(t *testing.T) {
+	tests := map[string]struct {
+		roles  map[string][]string
+		public []string
+		errMsg string
+	}{
+		"no method": {
+			roles:  map[string][]string{"viewer": {"opi_api.storage.v1.FrontendNvmeService"}},
+			errMsg: `role viewer: pattern "opi_api.storage.v1.FrontendNvmeService" is not service/method`,
+		},
+		"bad pattern": {
+			public: []string{"grpc.health.v1.Health/[Check"},
+			errMsg: `public methods: pattern "grpc.health.v1.Health/[Check": syntax error in pattern`,
+		},
+		"valid": {
+			roles:  map[string][]string{"operator": {"*/Get*", "opi_api.storage.v1.FrontendNvmeService/*"}},
+			public: []string{"*"},
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			_, err := NewPolicy(tt.roles, tt.public)
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tt.errMsg {
+				t.Errorf("expected error %q, received %q", tt.errMsg, errMsg)
+			}
+		})
+	}
+}
+
+func TestAuth_Authenticators(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	jwks, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+		{Key: key.Public(), KeyID: "k1", Algorithm: string(jose.ES256), Use: "sig"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwksFile := filepath.Join(dir, "jwks.json")
+	if err := os.WriteFile(jwksFile, jwks, 0600); err != nil {
+		t.Fatal(err)
+	}
+	tokensFile := filepath.Join(dir, "tokens.yaml")
+	tokens := "- subject: ci\n  sha256: " + sha256Hex("s3cret") + "\n  roles: [admin]\n  tenant: tenant-a\n"
+	if err := os.WriteFile(tokensFile, []byte(tokens), 0600); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+	sign := func(signingKey *ecdsa.PrivateKey, kid string, claims jwt.Claims, custom interface{}) string {
+		signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: signingKey},
+			(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", kid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		token, err := jwt.Signed(signer).Claims(claims).Claims(custom).Serialize()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := jwt.Claims{
+		Issuer:   "https://idp.example.com",
+		Subject:  "alice",
+		Audience: jwt.Audience{"opi-marvell-bridge"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+	}
+	expired := valid
+	expired.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+	otherAudience := valid
+	otherAudience.Audience = jwt.Audience{"another-service"}
+	nestedClaims := map[string]interface{}{"realm_access": map[string]interface{}{"roles": []string{"viewer"}}, "org": map[string]interface{}{"id": "tenant-b"}}
+
+	tokenAuthenticator, err := LoadTokenAuthenticator(tokensFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtAuthenticator, err := LoadJWTAuthenticator(jwksFile, valid.Issuer, "opi-marvell-bridge", "realm_access.roles", "org.id", time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jwtAuthenticator.now = func() time.Time { return now }
+	mtlsAuthenticator := NewMTLSAuthenticator(map[string]Identity{
+		"operator":  {Roles: []string{"admin"}},
+		"dashboard": {Roles: []string{"viewer"}, Tenant: "tenant-c"},
+	}, []string{"gateway"})
+
+	tests := map[string]struct {
+		authenticator Authenticator
+		ctx           context.Context
+		out           *Identity
+		errMsg        string
+	}{
+		"static token": {
+			authenticator: tokenAuthenticator,
+			ctx:           withToken(context.Background(), "s3cret"),
+			out:           &Identity{Method: "token", Subject: "ci", Roles: []string{"admin"}, Tenant: "tenant-a"},
+		},
+		"unknown static token": {
+			authenticator: tokenAuthenticator,
+			ctx:           withToken(context.Background(), "guess"),
+			errMsg:        "unknown bearer token",
+		},
+		"no static token": {
+			authenticator: tokenAuthenticator,
+			ctx:           context.Background(),
+			errMsg:        errNoCredentials.Error(),
+		},
+		"jwt": {
+			authenticator: jwtAuthenticator,
+			ctx:           withToken(context.Background(), sign(key, "k1", valid, nestedClaims)),
+			out:           &Identity{Method: "jwt", Subject: "alice", Roles: []string{"viewer"}, Tenant: "tenant-b"},
+		},
+		"expired jwt": {
+			authenticator: jwtAuthenticator,
+			ctx:           withToken(context.Background(), sign(key, "k1", expired, nestedClaims)),
+			errMsg:        "invalid JWT: go-jose/go-jose/jwt: validation failed, token is expired (exp)",
+		},
+		"jwt of another audience": {
+			authenticator: jwtAuthenticator,
+			ctx:           withToken(context.Background(), sign(key, "k1", otherAudience, nestedClaims)),
+			errMsg:        "invalid JWT: go-jose/go-jose/jwt: validation failed, invalid audience claim (aud)",
+		},
+		"jwt signed by another key": {
+			authenticator: jwtAuthenticator,
+			ctx:           withToken(context.Background(), sign(otherKey, "k1", valid, nestedClaims)),
+			errMsg:        "invalid JWT: signature not verified by the JWKS",
+		},
+		"jwt of unknown key": {
+			authenticator: jwtAuthenticator,
+			ctx:           withToken(context.Background(), sign(key, "k2", valid, nestedClaims)),
+			errMsg:        "invalid JWT: signature not verified by the JWKS",
+		},
+		"client certificate": {
+			authenticator: mtlsAuthenticator,
+			ctx:           withClientCert(context.Background(), "operator"),
+			out:           &Identity{Method: "mtls", Subject: "operator", Roles: []string{"admin"}},
+		},
+		"unknown client certificate": {
+			authenticator: mtlsAuthenticator,
+			ctx:           withClientCert(context.Background(), "intruder"),
+			errMsg:        "unknown client certificate intruder",
+		},
+		"client certificate forwarded by trusted proxy": {
+			authenticator: mtlsAuthenticator,
+			ctx: withClientCert(metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(ForwardedClientCertKey, "dashboard")), "gateway"),
+			out: &Identity{Method: "mtls", Subject: "dashboard", Roles: []string{"viewer"}, Tenant: "tenant-c"},
+		},
+		"client certificate forwarded by untrusted caller": {
+			authenticator: mtlsAuthenticator,
+			ctx: withClientCert(metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(ForwardedClientCertKey, "operator")), "dashboard"),
+			out: &Identity{Method: "mtls", Subject: "dashboard", Roles: []string{"viewer"}, Tenant: "tenant-c"},
+		},
+		"trusted proxy without forwarded certificate": {
+			authenticator: mtlsAuthenticator,
+			ctx:           withClientCert(context.Background(), "gateway"),
+			errMsg:        errNoCredentials.Error(),
+		},
+		"client certificate forwarded over unix socket": {
+			authenticator: mtlsAuthenticator,
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(),
+				metadata.Pairs(ForwardedClientCertKey, "operator")), &peer.Peer{Addr: &net.UnixAddr{Name: "@", Net: "unix"}}),
+			out: &Identity{Method: "mtls", Subject: "operator", Roles: []string{"admin"}},
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			identity, err := tt.authenticator.Authenticate(tt.ctx)
+			errMsg := ""
+			if err != nil {
+				errMsg = err.Error()
+			}
+			if errMsg != tt.errMsg {
+				t.Errorf("expected error %q, received %q", tt.errMsg, errMsg)
+			}
+			if !reflect.DeepEqual(identity, tt.out) {
+				t.Errorf("expected identity %+v, received %+v", tt.out, identity)
+			}
+		})
+	}
+}
+
+func TestAuth_Interceptor(t *testing.T) {
+	tokens, err := NewTokenAuthenticator([]StaticToken{
+		{Subject: "ci", SHA256: sha256Hex("admin-token"), Roles: []string{"admin"}},
+		{Subject: "grafana", SHA256: sha256Hex("viewer-token"), Roles: []string{"viewer"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewPolicy(DefaultRoles(), []string{"grpc.health.v1.Health/*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mtls := NewMTLSAuthenticator(map[string]Identity{"operator": {Roles: []string{"admin"}}}, nil)
+	interceptor := NewInterceptor(policy, mtls, tokens)
+
+	tests := map[string]struct {
+		ctx    context.Context
+		method string
+		caller string
+		code   codes.Code
+	}{
+		"admin creates": {
+			ctx:    withToken(context.Background(), "admin-token"),
+			method: "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeController",
+			caller: "token:ci",
+			code:   codes.OK,
+		},
+		"viewer creates": {
+			ctx:    withToken(context.Background(), "viewer-token"),
+			method: "/opi_api.storage.v1.FrontendNvmeService/CreateNvmeController",
+			code:   codes.PermissionDenied,
+		},
+		"viewer lists": {
+			ctx:    withToken(context.Background(), "viewer-token"),
+			method: "/opi_api.storage.v1.FrontendNvmeService/ListNvmeControllers",
+			caller: "token:grafana",
+			code:   codes.OK,
+		},
+		"anonymous lists": {
+			ctx:    context.Background(),
+			method: "/opi_api.storage.v1.FrontendNvmeService/ListNvmeControllers",
+			code:   codes.Unauthenticated,
+		},
+		"wrong token": {
+			ctx:    withToken(context.Background(), "guess"),
+			method: "/opi_api.storage.v1.FrontendNvmeService/ListNvmeControllers",
+			code:   codes.Unauthenticated,
+		},
+		"unmapped certificate with token": {
+			ctx:    withClientCert(withToken(context.Background(), "viewer-token"), "unmapped"),
+			method: "/opi_api.storage.v1.FrontendNvmeService/ListNvmeControllers",
+			caller: "token:grafana",
+			code:   codes.OK,
+		},
+		"anonymous health check": {
+			ctx:    context.Background(),
+			method: "/grpc.health.v1.Health/Check",
+			code:   codes.OK,
+		},
+	}
+
+	for testName, tt := range tests {
+		t.Run(testName, func(t *testing.T) {
+			caller := ""
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if identity, ok := FromContext(ctx); ok {
+					caller = identity.String()
+				}
+				return req, nil
+			}
+			_, err := interceptor.UnaryServerInterceptor()(tt.ctx, nil, &grpc.UnaryServerInfo{FullMethod: tt.method}, handler)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("expected code %v, received %v (%v)", tt.code, code, err)
+			}
+			if caller != tt.caller {
+				t.Errorf("expected caller %q, received %q", tt.caller, caller)
+			}
+
+			caller = ""
+			streamHandler := func(srv interface{}, ss grpc.ServerStream) error {
+				_, err := handler(ss.Context(), nil)
+				return err
+			}
+			err = interceptor.StreamServerInterceptor()(nil, &fakeStream{ctx: tt.ctx}, &grpc.StreamServerInfo{FullMethod: tt.method}, streamHandler)
+			if code := status.Code(err); code != tt.code {
+				t.Errorf("expected stream code %v, received %v (%v)", tt.code, code, err)
+			}
+			if caller != tt.caller {
+				t.Errorf("expected stream caller %q, received %q", tt.caller, caller)
+			}
+		})
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *fakeStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,138 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var logger = logging.Logger("auth")
+
+// Interceptor authenticates the callers and authorizes their calls
+type Interceptor struct {
+	authenticators []Authenticator
+	policy         *Policy
+}
+
+// NewInterceptor creates an interceptor trying the authenticators in
+// order and authorizing the calls with the policy
+func NewInterceptor(policy *Policy, authenticators ...Authenticator) *Interceptor {
+	if policy == nil {
+		log.Panic("nil for policy is not allowed")
+	}
+	return &Interceptor{authenticators: authenticators, policy: policy}
+}
+
+// New creates the interceptor of the configuration
+func New(c *config.AuthConfig) (*Interceptor, error) {
+	roles := c.Roles
+	if len(roles) == 0 {
+		roles = DefaultRoles()
+	}
+	policy, err := NewPolicy(roles, c.PublicMethods)
+	if err != nil {
+		return nil, err
+	}
+	var authenticators []Authenticator
+	if len(c.MTLS) != 0 {
+		identities := make(map[string]Identity, len(c.MTLS))
+		for _, identity := range c.MTLS {
+			identities[identity.CommonName] = Identity{Roles: identity.Roles, Tenant: identity.Tenant}
+		}
+		authenticators = append(authenticators, NewMTLSAuthenticator(identities, c.TrustedProxies))
+	}
+	if c.TokensFile != "" {
+		a, err := LoadTokenAuthenticator(c.TokensFile)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if c.JWT.JWKSFile != "" {
+		a, err := LoadJWTAuthenticator(c.JWT.JWKSFile, c.JWT.Issuer, c.JWT.Audience, c.JWT.RolesClaim, c.JWT.TenantClaim, c.JWT.Leeway)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	return NewInterceptor(policy, authenticators...), nil
+}
+
+// authorize returns ctx carrying the identity of the caller allowed to
+// call fullMethod
+func (i *Interceptor) authorize(ctx context.Context, fullMethod string) (context.Context, error) {
+	if i.policy.Public(fullMethod) {
+		return ctx, nil
+	}
+	var identity *Identity
+	var failure error
+	for _, a := range i.authenticators {
+		id, err := a.Authenticate(ctx)
+		if err == nil {
+			identity = id
+			break
+		}
+		if !errors.Is(err, errNoCredentials) {
+			// another authenticator may still accept the caller
+			failure = err
+		}
+	}
+	if identity == nil {
+		if failure == nil {
+			failure = errNoCredentials
+		}
+		logger.WarnContext(ctx, "unauthenticated call", "method", fullMethod, "error", failure)
+		return nil, status.Error(codes.Unauthenticated, "authentication required")
+	}
+	if !i.policy.Allowed(identity.Roles, fullMethod) {
+		logger.WarnContext(ctx, "unauthorized call", "method", fullMethod, "caller", identity.String(), "roles", identity.Roles)
+		return nil, status.Errorf(codes.PermissionDenied, "%s is not allowed to call %s", identity, fullMethod)
+	}
+	logger.DebugContext(ctx, "authorized call", "method", fullMethod, "caller", identity.String())
+	return NewContext(ctx, identity), nil
+}
+
+// UnaryServerInterceptor authorizes the unary calls
+func (i *Interceptor) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := i.authorize(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor authorizes the streaming calls, e.g. Watch
+func (i *Interceptor) StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := i.authorize(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authorizedStream carries the identity in the stream context
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// jwtAlgorithms are the accepted signature algorithms, never "none"
+var jwtAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512,
+	jose.PS256, jose.PS384, jose.PS512,
+	jose.ES256, jose.ES384, jose.ES512,
+	jose.EdDSA,
+}
+
+// JWTAuthenticator verifies JWT bearer tokens, e.g. issued by an OIDC
+// provider, against a local JWKS
+type JWTAuthenticator struct {
+	keys        *jose.JSONWebKeySet
+	expected    jwt.Expected
+	rolesClaim  []string
+	tenantClaim []string
+	leeway      time.Duration
+	now         func() time.Time
+}
+
+// LoadJWTAuthenticator reads the JWKS file verifying the tokens of issuer
+// for audience, the roles and the tenant are read from the dotted
+// rolesClaim and tenantClaim paths
+func LoadJWTAuthenticator(jwksFile string, issuer string, audience string, rolesClaim string, tenantClaim string, leeway time.Duration) (*JWTAuthenticator, error) {
+	data, err := os.ReadFile(filepath.Clean(jwksFile))
+	if err != nil {
+		return nil, err
+	}
+	keys := &jose.JSONWebKeySet{}
+	if err := json.Unmarshal(data, keys); err != nil {
+		return nil, fmt.Errorf("%s: %w", jwksFile, err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("%s: no keys", jwksFile)
+	}
+	return &JWTAuthenticator{
+		keys:        keys,
+		expected:    jwt.Expected{Issuer: issuer, AnyAudience: jwt.Audience{audience}},
+		rolesClaim:  strings.Split(rolesClaim, "."),
+		tenantClaim: strings.Split(tenantClaim, "."),
+		leeway:      leeway,
+		now:         time.Now,
+	}, nil
+}
+
+// Authenticate implements Authenticator
+func (a *JWTAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	token, ok := bearerToken(ctx)
+	if !ok || !isJWT(token) {
+		return nil, errNoCredentials
+	}
+	parsed, err := jwt.ParseSigned(token, jwtAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	keys := a.keys.Keys
+	if kid := parsed.Headers[0].KeyID; kid != "" {
+		keys = a.keys.Key(kid)
+	}
+	var claims jwt.Claims
+	var custom map[string]interface{}
+	verified := false
+	for _, key := range keys {
+		if err = parsed.Claims(key.Public().Key, &claims, &custom); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return nil, errors.New("invalid JWT: signature not verified by the JWKS")
+	}
+	if claims.Expiry == nil {
+		return nil, errors.New("invalid JWT: no expiration")
+	}
+	expected := a.expected
+	expected.Time = a.now()
+	if err := claims.ValidateWithLeeway(expected, a.leeway); err != nil {
+		return nil, fmt.Errorf("invalid JWT: %w", err)
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("invalid JWT: no subject")
+	}
+	tenant, _ := claim(custom, a.tenantClaim).(string)
+	return &Identity{Method: "jwt", Subject: claims.Subject, Roles: a.roles(custom), Tenant: tenant}, nil
+}
+
+// claim returns the value at the claim path, nil if missing
+func claim(claims map[string]interface{}, path []string) interface{} {
+	var value interface{} = claims
+	for _, name := range path {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+	return value
+}
+
+// roles reads the string list at the roles claim path
+func (a *JWTAuthenticator) roles(claims map[string]interface{}) []string {
+	list, _ := claim(claims, a.rolesClaim).([]interface{})
+	roles := make([]string, 0, len(list))
+	for _, role := range list {
+		if role, ok := role.(string); ok {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// MTLSAuthenticator maps the common name of the verified client
+// certificate to roles and a tenant
+type MTLSAuthenticator struct {
+	identities map[string]Identity
+	proxies    map[string]bool
+}
+
+// NewMTLSAuthenticator creates an authenticator mapping common names to
+// the roles and tenant of identities. The callers of the unix socket and of
+// the proxies, by common name, can forward the client certificate of their
+// own caller.
+func NewMTLSAuthenticator(identities map[string]Identity, proxies []string) *MTLSAuthenticator {
+	a := &MTLSAuthenticator{identities: identities, proxies: make(map[string]bool, len(proxies))}
+	for _, proxy := range proxies {
+		a.proxies[proxy] = true
+	}
+	return a
+}
+
+// Authenticate implements Authenticator
+func (a *MTLSAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, errNoCredentials
+	}
+	commonName := ""
+	trusted := false
+	if _, unix := p.Addr.(*net.UnixAddr); unix {
+		trusted = true
+	} else if info, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(info.State.VerifiedChains) != 0 && len(info.State.VerifiedChains[0]) != 0 {
+		commonName = info.State.VerifiedChains[0][0].Subject.CommonName
+		trusted = a.proxies[commonName]
+	}
+	if trusted {
+		md, _ := metadata.FromIncomingContext(ctx)
+		forwarded := md.Get(ForwardedClientCertKey)
+		if len(forwarded) == 0 {
+			// a proxy never acts on its own behalf
+			return nil, errNoCredentials
+		}
+		commonName = forwarded[0]
+	}
+	if commonName == "" {
+		return nil, errNoCredentials
+	}
+	identity, ok := a.identities[commonName]
+	if !ok {
+		return nil, fmt.Errorf("unknown client certificate %s", commonName)
+	}
+	return &Identity{Method: "mtls", Subject: commonName, Roles: identity.Roles, Tenant: identity.Tenant}, nil
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// DefaultRoles are used when no roles are configured: viewer reads the
+// resources and their stats, admin calls everything
+func DefaultRoles() map[string][]string {
+	return map[string][]string{
+		"viewer": {
+			"*/Get*",
+			"*/List*",
+			"*/Stats*",
+			"*/Watch*",
+			"google.longrunning.Operations/WaitOperation",
+			"grpc.reflection.*/*",
+		},
+		"admin": {"*"},
+	}
+}
+
+// Policy allows the methods matching the patterns of the caller roles.
+// A pattern is service/method, each part a path.Match pattern, or * for
+// all methods.
+type Policy struct {
+	roles  map[string][]string
+	public []string
+}
+
+// NewPolicy creates a policy of the roles patterns, the public methods are
+// allowed without authentication
+func NewPolicy(roles map[string][]string, public []string) (*Policy, error) {
+	for role, patterns := range roles {
+		for _, pattern := range patterns {
+			if err := validatePattern(pattern); err != nil {
+				return nil, fmt.Errorf("role %s: %w", role, err)
+			}
+		}
+	}
+	for _, pattern := range public {
+		if err := validatePattern(pattern); err != nil {
+			return nil, fmt.Errorf("public methods: %w", err)
+		}
+	}
+	return &Policy{roles: roles, public: public}, nil
+}
+
+// Public checks if fullMethod, e.g. /grpc.health.v1.Health/Check, is
+// allowed without authentication
+func (p *Policy) Public(fullMethod string) bool {
+	return matchAny(p.public, fullMethod)
+}
+
+// Allowed checks if one of the roles allows fullMethod
+func (p *Policy) Allowed(roles []string, fullMethod string) bool {
+	for _, role := range roles {
+		if matchAny(p.roles[role], fullMethod) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, fullMethod string) bool {
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	for _, pattern := range patterns {
+		if pattern == "*" {
+			return true
+		}
+		servicePattern, methodPattern, _ := strings.Cut(pattern, "/")
+		serviceMatch, _ := path.Match(servicePattern, service)
+		methodMatch, _ := path.Match(methodPattern, method)
+		if serviceMatch && methodMatch {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePattern(pattern string) error {
+	if pattern == "*" {
+		return nil
+	}
+	service, method, found := strings.Cut(pattern, "/")
+	if !found || strings.Contains(method, "/") {
+		return fmt.Errorf("pattern %q is not service/method", pattern)
+	}
+	for _, part := range []string{service, method} {
+		if _, err := path.Match(part, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
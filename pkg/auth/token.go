@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package auth authenticates the callers of the bridge, with client
+// certificates, static bearer tokens or JWTs, and authorizes their calls
+// by role
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// StaticToken is a bearer token of the tokens file, only its SHA-256 is
+// stored
+type StaticToken struct {
+	Subject string   `yaml:"subject"`
+	SHA256  string   `yaml:"sha256"`
+	Roles   []string `yaml:"roles"`
+	Tenant  string   `yaml:"tenant"`
+}
+
+// TokenAuthenticator authenticates static bearer tokens
+type TokenAuthenticator struct {
+	tokens []StaticToken
+	hashes [][]byte
+}
+
+// LoadTokenAuthenticator reads the YAML list of static tokens in file
+func LoadTokenAuthenticator(file string) (*TokenAuthenticator, error) {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return nil, err
+	}
+	var tokens []StaticToken
+	if err := yaml.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return NewTokenAuthenticator(tokens)
+}
+
+// NewTokenAuthenticator creates an authenticator of the static tokens
+func NewTokenAuthenticator(tokens []StaticToken) (*TokenAuthenticator, error) {
+	a := &TokenAuthenticator{tokens: tokens}
+	for _, token := range tokens {
+		hash, err := hex.DecodeString(strings.TrimSpace(token.SHA256))
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("token of %s: sha256 is not a hex SHA-256", token.Subject)
+		}
+		if token.Subject == "" {
+			return nil, errors.New("token without subject")
+		}
+		a.hashes = append(a.hashes, hash)
+	}
+	return a, nil
+}
+
+// Authenticate implements Authenticator
+func (a *TokenAuthenticator) Authenticate(ctx context.Context) (*Identity, error) {
+	token, ok := bearerToken(ctx)
+	if !ok || isJWT(token) {
+		return nil, errNoCredentials
+	}
+	hash := sha256.Sum256([]byte(token))
+	for i, expected := range a.hashes {
+		if subtle.ConstantTimeCompare(hash[:], expected) == 1 {
+			token := &a.tokens[i]
+			return &Identity{Method: "token", Subject: token.Subject, Roles: token.Roles, Tenant: token.Tenant}, nil
+		}
+	}
+	return nil, errors.New("unknown bearer token")
+}
+
+// isJWT checks if the token looks like a compact JWS
+func isJWT(token string) bool {
+	return strings.Count(token, ".") == 2
+}
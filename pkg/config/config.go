@@ -0,0 +1,676 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package config holds the configuration of the bridge, layered from
+// defaults, a YAML or TOML file, environment variables and flags
+package config
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+)
+
+// maxCtrlrID is the highest NVMe controller ID, higher ones are reserved
+const maxCtrlrID = 0xffef
+
+// Config is the effective configuration of the bridge
+type Config struct {
+	GRPC     GRPCConfig     `yaml:"grpc" toml:"grpc"`
+	HTTP     HTTPConfig     `yaml:"http" toml:"http"`
+	TLS      TLSConfig      `yaml:"tls" toml:"tls"`
+	Store    StoreConfig    `yaml:"store" toml:"store"`
+	SDK      SDKConfig      `yaml:"sdk" toml:"sdk"`
+	Metrics  MetricsConfig  `yaml:"metrics" toml:"metrics"`
+	Health   HealthConfig   `yaml:"health" toml:"health"`
+	Log      LogConfig      `yaml:"log" toml:"log"`
+	Audit    AuditConfig    `yaml:"audit" toml:"audit"`
+	Shutdown ShutdownConfig `yaml:"shutdown" toml:"shutdown"`
+	Features FeaturesConfig `yaml:"features" toml:"features"`
+	Frontend FrontendConfig `yaml:"frontend" toml:"frontend"`
+	Auth     AuthConfig     `yaml:"auth" toml:"auth"`
+	Tenants  TenantsConfig  `yaml:"tenants" toml:"tenants"`
+}
+
+// GRPCConfig configures the gRPC listener
+type GRPCConfig struct {
+	Port int `yaml:"port" toml:"port"`
+	// UnixSocket is an additional plaintext listener, only accessible to
+	// the bridge user, used by the HTTP gateway instead of the port
+	UnixSocket string `yaml:"unix_socket" toml:"unix_socket"`
+}
+
+// HTTP client certificate policies
+const (
+	ClientAuthNone    = "none"
+	ClientAuthRequest = "request"
+	ClientAuthRequire = "require"
+)
+
+// HTTPConfig configures the HTTP gateway listener
+type HTTPConfig struct {
+	Port         int           `yaml:"port" toml:"port"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	TLS          HTTPTLSConfig `yaml:"tls" toml:"tls"`
+	Backend      BackendConfig `yaml:"backend" toml:"backend"`
+}
+
+// HTTPTLSConfig configures HTTPS on the gateway, the files default to the
+// gRPC server ones
+type HTTPTLSConfig struct {
+	Enabled      bool   `yaml:"enabled" toml:"enabled"`
+	CertFile     string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile      string `yaml:"key_file" toml:"key_file"`
+	ClientCAFile string `yaml:"client_ca_file" toml:"client_ca_file"`
+	// ClientAuth is none, request (verified if sent) or require
+	ClientAuth string `yaml:"client_auth" toml:"client_auth"`
+}
+
+// BackendConfig configures the TLS connection of the gateway to the gRPC
+// port, the files default to the gRPC server ones
+type BackendConfig struct {
+	// ServerName is verified in the gRPC server certificate
+	ServerName string `yaml:"server_name" toml:"server_name"`
+	// CertFile and KeyFile are the client certificate of the gateway
+	CertFile string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile  string `yaml:"key_file" toml:"key_file"`
+	CAFile   string `yaml:"ca_file" toml:"ca_file"`
+}
+
+// TLSConfig holds the files of the gRPC server certificate, all empty
+// serves without TLS
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile  string `yaml:"key_file" toml:"key_file"`
+	CAFile   string `yaml:"ca_file" toml:"ca_file"`
+}
+
+// Enabled checks if the TLS files are configured
+func (c *TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || c.CAFile != ""
+}
+
+// Store types
+const (
+	StoreGomap = "gomap"
+	StoreBbolt = "bbolt"
+	StoreRedis = "redis"
+)
+
+// Redis modes
+const (
+	RedisStandalone = "standalone"
+	RedisSentinel   = "sentinel"
+	RedisCluster    = "cluster"
+)
+
+// StoreConfig configures the key-value store of the resources
+type StoreConfig struct {
+	// Type is gomap (in memory), bbolt (embedded file) or redis
+	Type  string      `yaml:"type" toml:"type"`
+	Redis RedisConfig `yaml:"redis" toml:"redis"`
+	Bbolt BboltConfig `yaml:"bbolt" toml:"bbolt"`
+}
+
+// RedisConfig configures the Redis connection
+type RedisConfig struct {
+	// Mode is standalone, sentinel or cluster
+	Mode string `yaml:"mode" toml:"mode"`
+	// Address is the standalone server
+	Address string `yaml:"address" toml:"address"`
+	// Addresses are the sentinels, or the cluster seed nodes
+	Addresses []string `yaml:"addresses" toml:"addresses"`
+	// MasterName is the master monitored by the sentinels
+	MasterName   string        `yaml:"master_name" toml:"master_name"`
+	DialTimeout  time.Duration `yaml:"dial_timeout" toml:"dial_timeout"`
+	ReadTimeout  time.Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout" toml:"write_timeout"`
+	PoolSize     int           `yaml:"pool_size" toml:"pool_size"`
+	MaxRetries   int           `yaml:"max_retries" toml:"max_retries"`
+	// Username is the ACL user, empty authenticates with Password only
+	Username string `yaml:"username" toml:"username"`
+	Password Secret `yaml:"password" toml:"password"`
+	// PasswordFile holds the password, instead of Password
+	PasswordFile string `yaml:"password_file" toml:"password_file"`
+	// DB is the database index, always 0 in cluster mode
+	DB int `yaml:"db" toml:"db"`
+	// KeyPrefix isolates the keys of bridges sharing a Redis
+	KeyPrefix string         `yaml:"key_prefix" toml:"key_prefix"`
+	TLS       RedisTLSConfig `yaml:"tls" toml:"tls"`
+}
+
+// RedisTLSConfig configures TLS to Redis
+type RedisTLSConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// CAFile verifies the server certificate, empty uses the system CAs
+	CAFile string `yaml:"ca_file" toml:"ca_file"`
+	// CertFile and KeyFile are the client certificate, if Redis requires one
+	CertFile           string `yaml:"cert_file" toml:"cert_file"`
+	KeyFile            string `yaml:"key_file" toml:"key_file"`
+	ServerName         string `yaml:"server_name" toml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify" toml:"insecure_skip_verify"`
+}
+
+// Secret is a setting never printed, e.g. a password
+type Secret string
+
+// redacted replaces the secrets in the printed configuration
+const redacted = "REDACTED"
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return redacted
+}
+
+// MarshalYAML redacts the secret
+func (s Secret) MarshalYAML() (interface{}, error) {
+	return s.String(), nil
+}
+
+// BboltConfig configures the embedded file-backed store
+type BboltConfig struct {
+	Path   string `yaml:"path" toml:"path"`
+	Bucket string `yaml:"bucket" toml:"bucket"`
+	// Timeout bounds the wait for the file lock held by another process
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// SDKConfig configures the connection to the Marvell SDK
+type SDKConfig struct {
+	Address string `yaml:"address" toml:"address"`
+}
+
+// MetricsConfig configures the Prometheus metrics
+type MetricsConfig struct {
+	Port     int           `yaml:"port" toml:"port"`
+	Interval time.Duration `yaml:"interval" toml:"interval"`
+}
+
+// HealthConfig configures the health checks
+type HealthConfig struct {
+	Interval     time.Duration `yaml:"interval" toml:"interval"`
+	CheckTimeout time.Duration `yaml:"check_timeout" toml:"check_timeout"`
+}
+
+// LogConfig configures the loggers of the bridge components
+type LogConfig struct {
+	Level          string `yaml:"level" toml:"level"`
+	Levels         string `yaml:"levels" toml:"levels"`
+	Format         string `yaml:"format" toml:"format"`
+	MaxValueLength int    `yaml:"max_value_len" toml:"max_value_len"`
+	Payloads       bool   `yaml:"payloads" toml:"payloads"`
+}
+
+// AuditConfig configures the audit log sinks
+type AuditConfig struct {
+	File        string `yaml:"file" toml:"file"`
+	MaxSizeMB   int    `yaml:"max_size_mb" toml:"max_size_mb"`
+	MaxBackups  int    `yaml:"max_backups" toml:"max_backups"`
+	RedisStream string `yaml:"redis_stream" toml:"redis_stream"`
+	RedisMaxLen int64  `yaml:"redis_max_len" toml:"redis_max_len"`
+}
+
+// ShutdownConfig configures the graceful shutdown
+type ShutdownConfig struct {
+	Timeout time.Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// FeaturesConfig toggles the optional APIs
+type FeaturesConfig struct {
+	AsyncOperations bool `yaml:"async_operations" toml:"async_operations"`
+	Watch           bool `yaml:"watch" toml:"watch"`
+	Reflection      bool `yaml:"reflection" toml:"reflection"`
+}
+
+// FrontendConfig holds the defaults passed to the Marvell SDK
+type FrontendConfig struct {
+	MinCtrlrID      int  `yaml:"min_ctrlr_id" toml:"min_ctrlr_id"`
+	MaxCtrlrID      int  `yaml:"max_ctrlr_id" toml:"max_ctrlr_id"`
+	ShareNamespaces bool `yaml:"share_namespaces" toml:"share_namespaces"`
+	// PcieFunctions is the inventory of the PCIe functions of the
+	// controllers, the endpoints omitted by the clients are assigned from it
+	PcieFunctions []PcieFunctionConfig `yaml:"pcie_functions" toml:"pcie_functions"`
+	QueueBudget   QueueBudgetConfig    `yaml:"queue_budget" toml:"queue_budget"`
+	// OUI is the IEEE OUI of the NGUIDs and EUI-64s generated for the
+	// namespaces, in xx:xx:xx format
+	OUI string `yaml:"oui" toml:"oui"`
+	// RemovalTimeout is how long the controller deletions wait for the host
+	// to release the queues, 0 removes the controllers by force right away
+	RemovalTimeout time.Duration `yaml:"removal_timeout" toml:"removal_timeout"`
+	// RemovalPollInterval is the interval of the checks of the queues
+	RemovalPollInterval time.Duration `yaml:"removal_poll_interval" toml:"removal_poll_interval"`
+	// CheckVolumes rejects the namespaces of volumes unknown to the bridge
+	// and the SDK
+	CheckVolumes bool `yaml:"check_volumes" toml:"check_volumes"`
+}
+
+// ParseOUI returns the bytes of the OUI, separated by colons, dashes or
+// nothing
+func (c *FrontendConfig) ParseOUI() ([3]byte, error) {
+	var oui [3]byte
+	digits := strings.NewReplacer(":", "", "-", "").Replace(c.OUI)
+	b, err := hex.DecodeString(digits)
+	if err != nil || len(b) != len(oui) {
+		return oui, fmt.Errorf("frontend.oui %q is not 3 bytes in xx:xx:xx format", c.OUI)
+	}
+	copy(oui[:], b)
+	return oui, nil
+}
+
+// PcieFunctionConfig is a PCIe physical function with its virtual
+// functions 1 to VirtualFunctions and the budget of queues of all its
+// controllers, zero is unlimited
+type PcieFunctionConfig struct {
+	PortID           int `yaml:"port_id" toml:"port_id"`
+	PhysicalFunction int `yaml:"physical_function" toml:"physical_function"`
+	VirtualFunctions int `yaml:"virtual_functions" toml:"virtual_functions"`
+	MaxNsq           int `yaml:"max_nsq" toml:"max_nsq"`
+	MaxNcq           int `yaml:"max_ncq" toml:"max_ncq"`
+}
+
+// QueueBudgetConfig bounds the queue resources of the controllers of the
+// device, zero is unlimited
+type QueueBudgetConfig struct {
+	MaxNsq  int `yaml:"max_nsq" toml:"max_nsq"`
+	MaxNcq  int `yaml:"max_ncq" toml:"max_ncq"`
+	MaxMqes int `yaml:"max_mqes" toml:"max_mqes"`
+}
+
+// AuthConfig configures the authentication of the callers and the roles
+// authorizing them
+type AuthConfig struct {
+	Enabled bool `yaml:"enabled" toml:"enabled"`
+	// MTLS maps the common names of client certificates to roles
+	MTLS []MTLSIdentityConfig `yaml:"mtls" toml:"mtls"`
+	// TrustedProxies are the common names of the client certificates
+	// allowed to forward the client certificate of their caller, e.g. the
+	// HTTP gateway. The unix socket is always trusted.
+	TrustedProxies []string `yaml:"trusted_proxies" toml:"trusted_proxies"`
+	// TokensFile lists the static bearer tokens, by their SHA-256
+	TokensFile string    `yaml:"tokens_file" toml:"tokens_file"`
+	JWT        JWTConfig `yaml:"jwt" toml:"jwt"`
+	// Roles maps roles to the allowed service/method patterns, empty uses
+	// the built-in viewer and admin roles
+	Roles map[string][]string `yaml:"roles" toml:"roles"`
+	// PublicMethods are allowed without authentication
+	PublicMethods []string `yaml:"public_methods" toml:"public_methods"`
+}
+
+// MTLSIdentityConfig maps a client certificate to roles
+type MTLSIdentityConfig struct {
+	CommonName string   `yaml:"common_name" toml:"common_name"`
+	Roles      []string `yaml:"roles" toml:"roles"`
+	// Tenant owns the resources created with the certificate, empty for
+	// the operators of the bridge
+	Tenant string `yaml:"tenant" toml:"tenant"`
+}
+
+// JWTConfig configures the verification of JWT bearer tokens
+type JWTConfig struct {
+	// JWKSFile holds the keys verifying the tokens, empty disables JWT
+	JWKSFile string `yaml:"jwks_file" toml:"jwks_file"`
+	Issuer   string `yaml:"issuer" toml:"issuer"`
+	Audience string `yaml:"audience" toml:"audience"`
+	// RolesClaim is the claim holding the roles, dots separate nested claims
+	RolesClaim string `yaml:"roles_claim" toml:"roles_claim"`
+	// TenantClaim is the claim holding the tenant, dots separate nested claims
+	TenantClaim string        `yaml:"tenant_claim" toml:"tenant_claim"`
+	Leeway      time.Duration `yaml:"leeway" toml:"leeway"`
+}
+
+// TenantsConfig holds the quotas of the tenants owning Nvme subsystems
+type TenantsConfig struct {
+	// DefaultQuota applies to the tenants without quota
+	DefaultQuota QuotaConfig            `yaml:"default_quota" toml:"default_quota"`
+	Quotas       map[string]QuotaConfig `yaml:"quotas" toml:"quotas"`
+}
+
+// QuotaConfig limits the Nvme resources of a tenant, zero is unlimited
+type QuotaConfig struct {
+	MaxSubsystems       int `yaml:"max_subsystems" toml:"max_subsystems"`
+	MaxNamespaces       int `yaml:"max_namespaces" toml:"max_namespaces"`
+	MaxVfControllers    int `yaml:"max_vf_controllers" toml:"max_vf_controllers"`
+	MaxSubmissionQueues int `yaml:"max_submission_queues" toml:"max_submission_queues"`
+	MaxCompletionQueues int `yaml:"max_completion_queues" toml:"max_completion_queues"`
+}
+
+// validate checks the quota of tenant, the default quota if empty
+func (q *QuotaConfig) validate(tenant string) []error {
+	prefix := "tenants.default_quota"
+	if tenant != "" {
+		prefix = fmt.Sprintf("tenants.quotas.%s", tenant)
+	}
+	var errs []error
+	limits := []struct {
+		name  string
+		value int
+	}{
+		{"max_subsystems", q.MaxSubsystems},
+		{"max_namespaces", q.MaxNamespaces},
+		{"max_vf_controllers", q.MaxVfControllers},
+		{"max_submission_queues", q.MaxSubmissionQueues},
+		{"max_completion_queues", q.MaxCompletionQueues},
+	}
+	for _, limit := range limits {
+		if limit.value < 0 {
+			errs = append(errs, fmt.Errorf("%s.%s must not be negative", prefix, limit.name))
+		}
+	}
+	return errs
+}
+
+// Default returns the configuration used when nothing is overridden
+func Default() *Config {
+	return &Config{
+		GRPC: GRPCConfig{Port: 50051},
+		HTTP: HTTPConfig{
+			Port:         8082,
+			ReadTimeout:  5 * time.Second,
+			WriteTimeout: 10 * time.Second,
+			TLS:          HTTPTLSConfig{ClientAuth: ClientAuthNone},
+			Backend:      BackendConfig{ServerName: "localhost"},
+		},
+		Store: StoreConfig{
+			Type: StoreRedis,
+			Redis: RedisConfig{
+				Mode:         RedisStandalone,
+				Address:      "127.0.0.1:6379",
+				DialTimeout:  5 * time.Second,
+				ReadTimeout:  3 * time.Second,
+				WriteTimeout: 3 * time.Second,
+				MaxRetries:   3,
+			},
+			Bbolt: BboltConfig{
+				Path:    "/var/lib/opi-marvell-bridge/store.db",
+				Bucket:  "opi",
+				Timeout: 5 * time.Second,
+			},
+		},
+		SDK: SDKConfig{Address: "/var/tmp/spdk.sock"},
+		Metrics: MetricsConfig{
+			Interval: 15 * time.Second,
+		},
+		Health: HealthConfig{
+			Interval:     10 * time.Second,
+			CheckTimeout: 2 * time.Second,
+		},
+		Log: LogConfig{
+			Level:          "info",
+			Format:         "text",
+			MaxValueLength: 1024,
+		},
+		Audit: AuditConfig{
+			MaxSizeMB:  100,
+			MaxBackups: 10,
+		},
+		Shutdown: ShutdownConfig{Timeout: 30 * time.Second},
+		Features: FeaturesConfig{
+			AsyncOperations: true,
+			Watch:           true,
+			Reflection:      true,
+		},
+		Frontend: FrontendConfig{
+			// bug in v21.01, should be 0 for now
+			MinCtrlrID:      0,
+			MaxCtrlrID:      256,
+			ShareNamespaces: true,
+			// Marvell
+			OUI:                 "00:50:43",
+			RemovalPollInterval: time.Second,
+			CheckVolumes:        true,
+		},
+		Auth: AuthConfig{
+			JWT: JWTConfig{
+				RolesClaim:  "roles",
+				TenantClaim: "tenant",
+				Leeway:      time.Minute,
+			},
+			PublicMethods: []string{"grpc.health.v1.Health/*"},
+		},
+	}
+}
+
+// Validate checks the configuration, all problems are reported at once
+func (c *Config) Validate() error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.GRPC.Port), "grpc.port %d is not a valid port", c.GRPC.Port)
+	check(validPort(c.HTTP.Port), "http.port %d is not a valid port", c.HTTP.Port)
+	check(c.Metrics.Port == 0 || validPort(c.Metrics.Port), "metrics.port %d is not a valid port", c.Metrics.Port)
+	check(c.GRPC.Port != c.HTTP.Port, "grpc.port and http.port are both %d", c.GRPC.Port)
+	check(c.Metrics.Port != c.GRPC.Port && c.Metrics.Port != c.HTTP.Port, "metrics.port %d is already used by the gRPC or HTTP server", c.Metrics.Port)
+
+	check(c.HTTP.ReadTimeout > 0, "http.read_timeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "http.write_timeout must be positive")
+	check(c.Metrics.Interval > 0, "metrics.interval must be positive")
+	check(c.Health.Interval > 0, "health.interval must be positive")
+	check(c.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+	check(c.Shutdown.Timeout > 0, "shutdown.timeout must be positive")
+
+	if c.TLS.Enabled() {
+		files := []struct{ name, path string }{
+			{"cert_file", c.TLS.CertFile},
+			{"key_file", c.TLS.KeyFile},
+			{"ca_file", c.TLS.CAFile},
+		}
+		for _, file := range files {
+			if file.path == "" {
+				errs = append(errs, fmt.Errorf("tls.%s is required when TLS is enabled", file.name))
+			} else if _, err := os.Stat(file.path); err != nil {
+				errs = append(errs, fmt.Errorf("tls.%s: %w", file.name, err))
+			}
+		}
+	}
+	errs = append(errs, c.validateGateway()...)
+	errs = append(errs, c.Auth.validate()...)
+	switch c.Store.Type {
+	case StoreGomap:
+	case StoreBbolt:
+		check(c.Store.Bbolt.Path != "", "store.bbolt.path is required")
+		check(c.Store.Bbolt.Bucket != "", "store.bbolt.bucket is required")
+		check(c.Store.Bbolt.Timeout > 0, "store.bbolt.timeout must be positive")
+	case StoreRedis:
+	default:
+		errs = append(errs, fmt.Errorf("store.type %q is not gomap, bbolt or redis", c.Store.Type))
+	}
+	if c.Store.Type == StoreRedis || c.Audit.RedisStream != "" {
+		errs = append(errs, c.Store.Redis.validate()...)
+	}
+	check(c.SDK.Address != "", "sdk.address is required")
+
+	var opts logging.Options
+	if err := opts.Level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		errs = append(errs, fmt.Errorf("log.level: %w", err))
+	}
+	if _, err := logging.ParseLevels(c.Log.Levels); err != nil {
+		errs = append(errs, fmt.Errorf("log.levels: %w", err))
+	}
+	check(c.Log.Format == "text" || c.Log.Format == "json", "log.format %q is not text or json", c.Log.Format)
+	check(c.Log.MaxValueLength >= 0, "log.max_value_len must not be negative")
+
+	check(c.Audit.MaxSizeMB >= 0, "audit.max_size_mb must not be negative")
+	check(c.Audit.MaxBackups >= 0, "audit.max_backups must not be negative")
+	check(c.Audit.RedisMaxLen >= 0, "audit.redis_max_len must not be negative")
+
+	check(c.Frontend.MinCtrlrID >= 0 && c.Frontend.MinCtrlrID <= c.Frontend.MaxCtrlrID,
+		"frontend.min_ctrlr_id %d must be between 0 and frontend.max_ctrlr_id %d", c.Frontend.MinCtrlrID, c.Frontend.MaxCtrlrID)
+	check(c.Frontend.MaxCtrlrID <= maxCtrlrID, "frontend.max_ctrlr_id %d is over %d", c.Frontend.MaxCtrlrID, maxCtrlrID)
+	pfs := make(map[[2]int]bool)
+	for i, f := range c.Frontend.PcieFunctions {
+		check(f.PortID >= 0 && f.PhysicalFunction >= 0 && f.VirtualFunctions >= 0 && f.MaxNsq >= 0 && f.MaxNcq >= 0,
+			"frontend.pcie_functions[%d] must not be negative", i)
+		pf := [2]int{f.PortID, f.PhysicalFunction}
+		check(!pfs[pf], "frontend.pcie_functions has port %d pf %d twice", f.PortID, f.PhysicalFunction)
+		pfs[pf] = true
+	}
+	b := c.Frontend.QueueBudget
+	check(b.MaxNsq >= 0 && b.MaxNcq >= 0 && b.MaxMqes >= 0, "frontend.queue_budget must not be negative")
+	if _, err := c.Frontend.ParseOUI(); err != nil {
+		errs = append(errs, err)
+	}
+	check(c.Frontend.RemovalTimeout >= 0, "frontend.removal_timeout must not be negative")
+	check(c.Frontend.RemovalPollInterval > 0, "frontend.removal_poll_interval must be positive")
+
+	errs = append(errs, c.Tenants.DefaultQuota.validate("")...)
+	tenants := make([]string, 0, len(c.Tenants.Quotas))
+	for tenant := range c.Tenants.Quotas {
+		tenants = append(tenants, tenant)
+	}
+	sort.Strings(tenants)
+	for _, tenant := range tenants {
+		quota := c.Tenants.Quotas[tenant]
+		check(tenant != "", "tenants.quotas has an empty tenant")
+		errs = append(errs, quota.validate(tenant)...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateGateway checks the HTTPS listener and the gateway connection to
+// the gRPC server, given the gRPC server TLS files
+func (c *Config) validateGateway() []error {
+	var errs []error
+	t := &c.HTTP.TLS
+	if t.Enabled {
+		if (t.CertFile == "") != (t.KeyFile == "") {
+			errs = append(errs, errors.New("http.tls.cert_file and http.tls.key_file must be set together"))
+		}
+		if t.CertFile == "" && !c.TLS.Enabled() {
+			errs = append(errs, errors.New("http.tls.cert_file is required when the gRPC server has no TLS files"))
+		}
+		switch t.ClientAuth {
+		case ClientAuthNone, ClientAuthRequest:
+		case ClientAuthRequire:
+			if t.ClientCAFile == "" && !c.TLS.Enabled() {
+				errs = append(errs, errors.New("http.tls.client_ca_file is required to require client certificates"))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("http.tls.client_auth %q is not none, request or require", t.ClientAuth))
+		}
+	}
+	b := &c.HTTP.Backend
+	if (b.CertFile == "") != (b.KeyFile == "") {
+		errs = append(errs, errors.New("http.backend.cert_file and http.backend.key_file must be set together"))
+	}
+	files := []struct{ name, path string }{
+		{"http.tls.cert_file", t.CertFile},
+		{"http.tls.key_file", t.KeyFile},
+		{"http.tls.client_ca_file", t.ClientCAFile},
+		{"http.backend.cert_file", b.CertFile},
+		{"http.backend.key_file", b.KeyFile},
+		{"http.backend.ca_file", b.CAFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", file.name, err))
+		}
+	}
+	return errs
+}
+
+func (c *AuthConfig) validate() []error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs []error
+	if len(c.MTLS) == 0 && c.TokensFile == "" && c.JWT.JWKSFile == "" {
+		errs = append(errs, errors.New("auth requires auth.mtls, auth.tokens_file or auth.jwt.jwks_file"))
+	}
+	for _, identity := range c.MTLS {
+		if identity.CommonName == "" {
+			errs = append(errs, errors.New("auth.mtls.common_name is required"))
+		}
+	}
+	if c.JWT.JWKSFile != "" && (c.JWT.Issuer == "" || c.JWT.Audience == "") {
+		errs = append(errs, errors.New("auth.jwt.issuer and auth.jwt.audience are required to verify JWTs"))
+	}
+	if c.JWT.Leeway < 0 {
+		errs = append(errs, errors.New("auth.jwt.leeway must not be negative"))
+	}
+	for _, file := range []struct{ name, path string }{
+		{"tokens_file", c.TokensFile},
+		{"jwt.jwks_file", c.JWT.JWKSFile},
+	} {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			errs = append(errs, fmt.Errorf("auth.%s: %w", file.name, err))
+		}
+	}
+	return errs
+}
+
+func (c *RedisConfig) validate() []error {
+	var errs []error
+	switch c.Mode {
+	case RedisStandalone:
+		if c.Address == "" {
+			errs = append(errs, errors.New("store.redis.address is required in standalone mode"))
+		}
+	case RedisSentinel:
+		if len(c.Addresses) == 0 || c.MasterName == "" {
+			errs = append(errs, errors.New("store.redis.addresses and store.redis.master_name are required in sentinel mode"))
+		}
+	case RedisCluster:
+		if len(c.Addresses) == 0 {
+			errs = append(errs, errors.New("store.redis.addresses are required in cluster mode"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("store.redis.mode %q is not standalone, sentinel or cluster", c.Mode))
+	}
+	if c.DialTimeout <= 0 || c.ReadTimeout <= 0 || c.WriteTimeout <= 0 {
+		errs = append(errs, errors.New("store.redis dial, read and write timeouts must be positive"))
+	}
+	if c.PoolSize < 0 || c.MaxRetries < 0 {
+		errs = append(errs, errors.New("store.redis.pool_size and store.redis.max_retries must not be negative"))
+	}
+	if c.Password != "" && c.PasswordFile != "" {
+		errs = append(errs, errors.New("store.redis.password and store.redis.password_file are mutually exclusive"))
+	}
+	if c.Username != "" && c.Password == "" && c.PasswordFile == "" {
+		errs = append(errs, errors.New("store.redis.username requires a password"))
+	}
+	if c.DB < 0 || (c.Mode == RedisCluster && c.DB != 0) {
+		errs = append(errs, fmt.Errorf("store.redis.db %d is not valid, it must be 0 in cluster mode", c.DB))
+	}
+	if c.TLS.Enabled && (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		errs = append(errs, errors.New("store.redis.tls.cert_file and store.redis.tls.key_file must be set together"))
+	}
+	files := []struct{ name, path string }{
+		{"password_file", c.PasswordFile},
+		{"tls.ca_file", c.TLS.CAFile},
+		{"tls.cert_file", c.TLS.CertFile},
+		{"tls.key_file", c.TLS.KeyFile},
+	}
+	for _, file := range files {
+		if file.path == "" {
+			continue
+		}
+		if _, err := os.Stat(file.path); err != nil {
+			errs = append(errs, fmt.Errorf("store.redis.%s: %w", file.name, err))
+		}
+	}
+	return errs
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
@@ -0,0 +1,294 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package config holds the configuration of the bridge, layered from
+// defaults, a YAML or TOML file, environment variables and flags
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfig_Load(t *testing.T) {
+	tests := map[string]struct {
+		file    string
+		content string
+		env     map[string]string
+		args    []string
+		out     func(c *Config)
+		errMsg  string
+	}{
+		"defaults": {
+			out: func(c *Config) {},
+		},
+		"yaml file": {
+			file: "bridge.yaml",
+			content: `
+grpc:
+  port: 50052
+http:
+  write_timeout: 1m
+frontend:
+  max_ctrlr_id: 512
+  share_namespaces: false
+`,
+			out: func(c *Config) {
+				c.GRPC.Port = 50052
+				c.HTTP.WriteTimeout = time.Minute
+				c.Frontend.MaxCtrlrID = 512
+				c.Frontend.ShareNamespaces = false
+			},
+		},
+		"toml file": {
+			file: "bridge.toml",
+			content: `
+[sdk]
+address = "/run/sdk.sock"
+[health]
+interval = "30s"
+`,
+			out: func(c *Config) {
+				c.SDK.Address = "/run/sdk.sock"
+				c.Health.Interval = 30 * time.Second
+			},
+		},
+		"environment overrides file": {
+			file:    "bridge.yaml",
+			content: "grpc:\n  port: 50052\nlog:\n  level: warn\n",
+			env:     map[string]string{"OPI_MARVELL_BRIDGE_GRPC_PORT": "50053"},
+			out: func(c *Config) {
+				c.GRPC.Port = 50053
+				c.Log.Level = "warn"
+			},
+		},
+		"invalid environment variable": {
+			env:    map[string]string{"OPI_MARVELL_BRIDGE_TLS": "server.crt"},
+			errMsg: `invalid value "server.crt" for OPI_MARVELL_BRIDGE_TLS`,
+		},
+		"flags override environment": {
+			env:  map[string]string{"OPI_MARVELL_BRIDGE_GRPC_PORT": "50053", "OPI_MARVELL_BRIDGE_LOG_FORMAT": "json"},
+			args: []string{"-grpc_port", "50054", "-enable_watch=false"},
+			out: func(c *Config) {
+				c.GRPC.Port = 50054
+				c.Log.Format = "json"
+				c.Features.Watch = false
+			},
+		},
+		"config file from environment": {
+			file:    "bridge.yml",
+			content: "store:\n  redis:\n    address: redis:6379\n",
+			env:     map[string]string{"OPI_MARVELL_BRIDGE_CONFIG": "bridge.yml"},
+			out: func(c *Config) {
+				c.Store.Redis.Address = "redis:6379"
+			},
+		},
+		"unknown yaml setting": {
+			file:    "bridge.yaml",
+			content: "grpc:\n  prot: 50052\n",
+			errMsg:  "field prot not found",
+		},
+		"unknown toml setting": {
+			file:    "bridge.toml",
+			content: "[grpc]\nprot = 50052\n",
+			errMsg:  "unknown settings [grpc.prot]",
+		},
+		"unsupported file extension": {
+			file:    "bridge.json",
+			content: "{}",
+			errMsg:  `unsupported config file extension ".json"`,
+		},
+		"redis sentinel": {
+			file:    "bridge.yaml",
+			content: "store:\n  redis:\n    mode: sentinel\n    master_name: opi\n",
+			args:    []string{"-redis_addrs", "sentinel-1:26379, sentinel-2:26379"},
+			out: func(c *Config) {
+				c.Store.Redis.Mode = RedisSentinel
+				c.Store.Redis.MasterName = "opi"
+				c.Store.Redis.Addresses = []string{"sentinel-1:26379", "sentinel-2:26379"}
+			},
+		},
+		"invalid store": {
+			args:   []string{"-store", "bbolt", "-bbolt_path", "", "-redis_mode", "cluster", "-audit_redis_stream", "opi-audit"},
+			errMsg: "invalid configuration: store.bbolt.path is required\nstore.redis.addresses are required in cluster mode",
+		},
+		"invalid redis authentication": {
+			args: []string{"-redis_username", "bridge", "-redis_db", "1", "-redis_mode", "cluster", "-redis_addrs", "redis-1:6379",
+				"-redis_tls", "-redis_tls_cert_file", "client.crt"},
+			errMsg: "invalid configuration: store.redis.username requires a password\n" +
+				"store.redis.db 1 is not valid, it must be 0 in cluster mode\n" +
+				"store.redis.tls.cert_file and store.redis.tls.key_file must be set together\n" +
+				"store.redis.tls.cert_file: stat client.crt: no such file or directory",
+		},
+		"gateway over unix socket": {
+			args: []string{"-grpc_unix_socket", "/run/opi/grpc.sock", "-gateway_server_name", "bridge.opi"},
+			out: func(c *Config) {
+				c.GRPC.UnixSocket = "/run/opi/grpc.sock"
+				c.HTTP.Backend.ServerName = "bridge.opi"
+			},
+		},
+		"invalid https gateway": {
+			args: []string{"-http_tls", "-http_tls_key_file", "server.key", "-http_tls_client_auth", "always"},
+			errMsg: "invalid configuration: http.tls.cert_file and http.tls.key_file must be set together\n" +
+				"http.tls.cert_file is required when the gRPC server has no TLS files\n" +
+				"http.tls.client_auth \"always\" is not none, request or require\n" +
+				"http.tls.key_file: stat server.key: no such file or directory",
+		},
+		"tenant quotas": {
+			file: "bridge.yaml",
+			content: `
+tenants:
+  default_quota:
+    max_subsystems: 1
+  quotas:
+    tenant-a:
+      max_namespaces: -1
+`,
+			args: []string{"-tenant_max_vf_controllers", "-4"},
+			errMsg: "invalid configuration: tenants.default_quota.max_vf_controllers must not be negative\n" +
+				"tenants.quotas.tenant-a.max_namespaces must not be negative",
+		},
+		"pcie functions": {
+			args: []string{"-pcie_functions", "0:0:8, 0:1:4:32:32", "-max_nsq", "64", "-max_mqes", "1024"},
+			out: func(c *Config) {
+				c.Frontend.PcieFunctions = []PcieFunctionConfig{
+					{PortID: 0, PhysicalFunction: 0, VirtualFunctions: 8},
+					{PortID: 0, PhysicalFunction: 1, VirtualFunctions: 4, MaxNsq: 32, MaxNcq: 32},
+				}
+				c.Frontend.QueueBudget = QueueBudgetConfig{MaxNsq: 64, MaxMqes: 1024}
+			},
+		},
+		"invalid pcie functions": {
+			file: "bridge.yaml",
+			content: `
+frontend:
+  pcie_functions:
+    - port_id: 1
+      physical_function: 0
+      virtual_functions: -2
+    - port_id: 1
+      physical_function: 0
+  queue_budget:
+    max_ncq: -1
+`,
+			errMsg: "invalid configuration: frontend.pcie_functions[0] must not be negative\n" +
+				"frontend.pcie_functions has port 1 pf 0 twice\n" +
+				"frontend.queue_budget must not be negative",
+		},
+		"oui": {
+			args: []string{"-oui", "00-0a-F7"},
+			out: func(c *Config) {
+				c.Frontend.OUI = "00-0a-F7"
+			},
+		},
+		"invalid oui": {
+			args:   []string{"-oui", "00:50"},
+			errMsg: `invalid configuration: frontend.oui "00:50" is not 3 bytes in xx:xx:xx format`,
+		},
+		"controller removal timeout": {
+			args: []string{"-ctrlr_removal_timeout", "30s", "-ctrlr_removal_poll_interval", "500ms"},
+			out: func(c *Config) {
+				c.Frontend.RemovalTimeout = 30 * time.Second
+				c.Frontend.RemovalPollInterval = 500 * time.Millisecond
+			},
+		},
+		"volume check disabled": {
+			args: []string{"-check_volumes=false"},
+			out: func(c *Config) {
+				c.Frontend.CheckVolumes = false
+			},
+		},
+		"invalid controller removal timeout": {
+			args:   []string{"-ctrlr_removal_timeout", "-1s"},
+			errMsg: "invalid configuration: frontend.removal_timeout must not be negative",
+		},
+		"invalid pcie functions flag": {
+			args:   []string{"-pcie_functions", "0:1"},
+			errMsg: `PCIe function "0:1" is not in port:pf:vfs[:max_nsq:max_ncq] format`,
+		},
+		"invalid configuration": {
+			args: []string{"-http_port", "50051", "-min_ctrlr_id", "300", "-log_format", "xml"},
+			errMsg: "invalid configuration: grpc.port and http.port are both 50051\n" +
+				"log.format \"xml\" is not text or json\n" +
+				"frontend.min_ctrlr_id 300 must be between 0 and frontend.max_ctrlr_id 256",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			args := tt.args
+			for key, value := range tt.env {
+				if key == "OPI_MARVELL_BRIDGE_CONFIG" {
+					value = filepath.Join(dir, value)
+				}
+				t.Setenv(key, value)
+			}
+			if tt.file != "" {
+				path := filepath.Join(dir, tt.file)
+				if err := os.WriteFile(path, []byte(tt.content), 0600); err != nil {
+					t.Fatal(err)
+				}
+				if _, ok := tt.env["OPI_MARVELL_BRIDGE_CONFIG"]; !ok {
+					args = append([]string{"-config", path}, args...)
+				}
+			}
+
+			c, err := Load(flag.NewFlagSet(name, flag.ContinueOnError), args)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatal("expected error", tt.errMsg, "received", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			expected := Default()
+			tt.out(expected)
+			if !reflect.DeepEqual(c, expected) {
+				t.Error("expected", expected, "received", c)
+			}
+		})
+	}
+}
+
+func TestConfig_String(t *testing.T) {
+	c := Default()
+	c.TLS = TLSConfig{CertFile: "server.crt", KeyFile: "server.key", CAFile: "ca.crt"}
+	c.Health.Interval = time.Minute
+	c.Store.Redis.Addresses = []string{"redis-1:6379", "redis-2:6379"}
+	c.Store.Redis.KeyPrefix = "dpu-1/"
+	c.Frontend.PcieFunctions = []PcieFunctionConfig{{PortID: 0, PhysicalFunction: 0, VirtualFunctions: 8, MaxNsq: 16}}
+	c.Auth.MTLS = []MTLSIdentityConfig{{CommonName: "admin.opi", Roles: []string{"admin"}, Tenant: "tenant-a"}}
+	c.Auth.TrustedProxies = []string{"localhost"}
+	c.Auth.Roles = map[string][]string{"viewer": {"*/Get*", "*/List*"}}
+	c.Tenants.Quotas = map[string]QuotaConfig{"tenant-a": {MaxSubsystems: 2, MaxNamespaces: 8}}
+
+	path := filepath.Join(t.TempDir(), "bridge.yaml")
+	if err := os.WriteFile(path, []byte(c.String()), 0600); err != nil {
+		t.Fatal(err)
+	}
+	parsed := Default()
+	if err := parsed.loadFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(parsed, c) {
+		t.Error("expected", c, "received", parsed)
+	}
+}
+
+func TestConfig_StringRedactsSecrets(t *testing.T) {
+	c := Default()
+	c.Store.Redis.Password = "secret"
+	printed := c.String()
+	if strings.Contains(printed, "secret") || !strings.Contains(printed, "password: REDACTED") {
+		t.Error("expected redacted password, received", printed)
+	}
+}
@@ -0,0 +1,317 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package config holds the configuration of the bridge, layered from
+// defaults, a YAML or TOML file, environment variables and flags
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvPrefix prefixes the environment variables overriding the flags, e.g.
+// OPI_MARVELL_BRIDGE_GRPC_PORT overrides -grpc_port
+const EnvPrefix = "OPI_MARVELL_BRIDGE_"
+
+// configFlag is the flag, and environment variable, naming the config file
+const configFlag = "config"
+
+// EnvName returns the environment variable overriding the flag name
+func EnvName(name string) string {
+	return EnvPrefix + strings.ToUpper(name)
+}
+
+// Load registers the flags of the configuration on fs, parses args and
+// returns the configuration layered from, by increasing precedence, the
+// defaults, the config file, the environment variables and the flags set
+// in args. Flags registered on fs by the caller take part in the layering
+// too.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	c := Default()
+	var file string
+	fs.StringVar(&file, configFlag, "", "YAML (.yaml, .yml) or TOML (.toml) configuration file, overridden by environment variables and flags")
+	c.registerFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	// the flags wrote into c, keep them to be applied last
+	set := map[string]string{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = f.Value.String()
+	})
+
+	*c = *Default()
+	if file == "" {
+		file = os.Getenv(EnvName(configFlag))
+	}
+	if file != "" {
+		if err := c.loadFile(file); err != nil {
+			return nil, err
+		}
+	}
+
+	var errs []error
+	fs.VisitAll(func(f *flag.Flag) {
+		value, ok := os.LookupEnv(EnvName(f.Name))
+		if !ok || f.Name == configFlag {
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for %s: %w", value, EnvName(f.Name), err))
+		}
+	})
+	for name, value := range set {
+		if err := fs.Set(name, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid value %q for flag -%s: %w", value, name, err))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return c, nil
+}
+
+// loadFile overrides c with the settings of the file, unknown settings are
+// rejected to catch typos
+func (c *Config) loadFile(file string) error {
+	data, err := os.ReadFile(filepath.Clean(file))
+	if err != nil {
+		return err
+	}
+	switch ext := strings.ToLower(filepath.Ext(file)); ext {
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	case ".toml":
+		md, err := toml.Decode(string(data), c)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if undecoded := md.Undecoded(); len(undecoded) != 0 {
+			return fmt.Errorf("%s: unknown settings %v", file, undecoded)
+		}
+	default:
+		return fmt.Errorf("%s: unsupported config file extension %q, expected .yaml, .yml or .toml", file, ext)
+	}
+	return nil
+}
+
+// String renders the configuration as YAML, as accepted by the config file
+func (c *Config) String() string {
+	var b strings.Builder
+	encoder := yaml.NewEncoder(&b)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c); err != nil {
+		return err.Error()
+	}
+	_ = encoder.Close()
+	return b.String()
+}
+
+func (c *Config) registerFlags(fs *flag.FlagSet) {
+	fs.IntVar(&c.GRPC.Port, "grpc_port", c.GRPC.Port, "The gRPC server port")
+	fs.StringVar(&c.GRPC.UnixSocket, "grpc_unix_socket", c.GRPC.UnixSocket, "Additional plaintext gRPC unix socket, used by the HTTP gateway instead of the gRPC port")
+	fs.IntVar(&c.HTTP.Port, "http_port", c.HTTP.Port, "The HTTP server port")
+	fs.BoolVar(&c.HTTP.TLS.Enabled, "http_tls", c.HTTP.TLS.Enabled, "Serve the HTTP gateway over HTTPS")
+	fs.StringVar(&c.HTTP.TLS.CertFile, "http_tls_cert_file", c.HTTP.TLS.CertFile, "HTTPS certificate, empty uses the gRPC server certificate")
+	fs.StringVar(&c.HTTP.TLS.KeyFile, "http_tls_key_file", c.HTTP.TLS.KeyFile, "HTTPS key, empty uses the gRPC server key")
+	fs.StringVar(&c.HTTP.TLS.ClientCAFile, "http_tls_client_ca_file", c.HTTP.TLS.ClientCAFile, "CA verifying the HTTPS client certificates, empty uses the gRPC CA")
+	fs.StringVar(&c.HTTP.TLS.ClientAuth, "http_tls_client_auth", c.HTTP.TLS.ClientAuth, "HTTPS client certificates: none, request or require")
+	fs.StringVar(&c.HTTP.Backend.ServerName, "gateway_server_name", c.HTTP.Backend.ServerName, "Name verified in the gRPC server certificate by the HTTP gateway")
+	fs.StringVar(&c.HTTP.Backend.CertFile, "gateway_cert_file", c.HTTP.Backend.CertFile, "Client certificate of the HTTP gateway to the gRPC server, empty uses the gRPC server certificate")
+	fs.StringVar(&c.HTTP.Backend.KeyFile, "gateway_key_file", c.HTTP.Backend.KeyFile, "Client key of the HTTP gateway to the gRPC server, empty uses the gRPC server key")
+	fs.StringVar(&c.HTTP.Backend.CAFile, "gateway_ca_file", c.HTTP.Backend.CAFile, "CA verifying the gRPC server for the HTTP gateway, empty uses the gRPC CA")
+	fs.DurationVar(&c.HTTP.ReadTimeout, "http_read_timeout", c.HTTP.ReadTimeout, "Maximum duration for reading an HTTP request")
+	fs.DurationVar(&c.HTTP.WriteTimeout, "http_write_timeout", c.HTTP.WriteTimeout, "Maximum duration before timing out writes of an HTTP response")
+	fs.StringVar(&c.SDK.Address, "spdk_addr", c.SDK.Address, "Points to SPDK unix socket/tcp socket to interact with")
+	fs.Var(&tlsValue{&c.TLS}, "tls", "TLS files in server_cert:server_key:ca_cert format.")
+	fs.StringVar(&c.Store.Type, "store", c.Store.Type, "Store of the resources: gomap (in memory), bbolt (embedded file) or redis")
+	fs.StringVar(&c.Store.Redis.Mode, "redis_mode", c.Store.Redis.Mode, "Redis mode: standalone, sentinel or cluster")
+	fs.StringVar(&c.Store.Redis.Address, "redis_addr", c.Store.Redis.Address, "Redis address in ip_address:port format")
+	fs.Var(&listValue{&c.Store.Redis.Addresses}, "redis_addrs", "Comma-separated Redis sentinel or cluster node addresses")
+	fs.StringVar(&c.Store.Redis.MasterName, "redis_master_name", c.Store.Redis.MasterName, "Redis master name monitored by the sentinels")
+	fs.DurationVar(&c.Store.Redis.DialTimeout, "redis_dial_timeout", c.Store.Redis.DialTimeout, "Timeout of new Redis connections")
+	fs.DurationVar(&c.Store.Redis.ReadTimeout, "redis_read_timeout", c.Store.Redis.ReadTimeout, "Timeout of Redis reads")
+	fs.DurationVar(&c.Store.Redis.WriteTimeout, "redis_write_timeout", c.Store.Redis.WriteTimeout, "Timeout of Redis writes")
+	fs.IntVar(&c.Store.Redis.PoolSize, "redis_pool_size", c.Store.Redis.PoolSize, "Maximum Redis connections per node, 0 is 10 per CPU")
+	fs.IntVar(&c.Store.Redis.MaxRetries, "redis_max_retries", c.Store.Redis.MaxRetries, "Retries of failed Redis commands")
+	fs.StringVar(&c.Store.Redis.Username, "redis_username", c.Store.Redis.Username, "Redis ACL username, empty authenticates with the password only")
+	fs.StringVar((*string)(&c.Store.Redis.Password), "redis_password", string(c.Store.Redis.Password), "Redis password, prefer -redis_password_file or the environment")
+	fs.StringVar(&c.Store.Redis.PasswordFile, "redis_password_file", c.Store.Redis.PasswordFile, "File holding the Redis password")
+	fs.IntVar(&c.Store.Redis.DB, "redis_db", c.Store.Redis.DB, "Redis database index")
+	fs.StringVar(&c.Store.Redis.KeyPrefix, "redis_key_prefix", c.Store.Redis.KeyPrefix, "Prefix of the Redis keys and audit stream of this bridge, e.g. dpu-1/")
+	fs.BoolVar(&c.Store.Redis.TLS.Enabled, "redis_tls", c.Store.Redis.TLS.Enabled, "Connect to Redis over TLS")
+	fs.StringVar(&c.Store.Redis.TLS.CAFile, "redis_tls_ca_file", c.Store.Redis.TLS.CAFile, "CA certificate verifying Redis, empty uses the system CAs")
+	fs.StringVar(&c.Store.Redis.TLS.CertFile, "redis_tls_cert_file", c.Store.Redis.TLS.CertFile, "Client certificate presented to Redis")
+	fs.StringVar(&c.Store.Redis.TLS.KeyFile, "redis_tls_key_file", c.Store.Redis.TLS.KeyFile, "Client key presented to Redis")
+	fs.StringVar(&c.Store.Redis.TLS.ServerName, "redis_tls_server_name", c.Store.Redis.TLS.ServerName, "Name verified in the Redis certificate, empty uses the host of the address")
+	fs.BoolVar(&c.Store.Redis.TLS.InsecureSkipVerify, "redis_tls_insecure_skip_verify", c.Store.Redis.TLS.InsecureSkipVerify, "Do not verify the Redis certificate, for testing only")
+	fs.StringVar(&c.Store.Bbolt.Path, "bbolt_path", c.Store.Bbolt.Path, "File of the bbolt store")
+	fs.StringVar(&c.Store.Bbolt.Bucket, "bbolt_bucket", c.Store.Bbolt.Bucket, "Bucket of the resources in the bbolt store")
+	fs.DurationVar(&c.Store.Bbolt.Timeout, "bbolt_timeout", c.Store.Bbolt.Timeout, "Time to wait for the lock of the bbolt file held by another process")
+
+	fs.IntVar(&c.Metrics.Port, "metrics_port", c.Metrics.Port, "The Prometheus metrics port, 0 serves /metrics on the HTTP server port")
+	fs.DurationVar(&c.Metrics.Interval, "metrics_interval", c.Metrics.Interval, "Interval between scrapes of the controller and namespace stats")
+	fs.DurationVar(&c.Health.Interval, "health_interval", c.Health.Interval, "Interval between health checks of the Marvell SDK, the store and the reconciliation state")
+	fs.DurationVar(&c.Health.CheckTimeout, "health_check_timeout", c.Health.CheckTimeout, "Timeout of the gRPC health checks behind /healthz and /readyz")
+
+	fs.StringVar(&c.Log.Level, "log_level", c.Log.Level, "Log level: debug, info, warn or error")
+	fs.StringVar(&c.Log.Levels, "log_levels", c.Log.Levels, "Log levels per component in component=level,... format, e.g. spdk=debug,grpc=warn")
+	fs.StringVar(&c.Log.Format, "log_format", c.Log.Format, "Log format: text or json")
+	fs.IntVar(&c.Log.MaxValueLength, "log_max_value_len", c.Log.MaxValueLength, "Truncate logged values longer than this, 0 disables truncation")
+	fs.BoolVar(&c.Log.Payloads, "log_payloads", c.Log.Payloads, "Log the (redacted) gRPC request and response payloads")
+
+	fs.StringVar(&c.Audit.File, "audit_file", c.Audit.File, "Audit log file of the mutating storage operations, empty disables it")
+	fs.IntVar(&c.Audit.MaxSizeMB, "audit_max_size_mb", c.Audit.MaxSizeMB, "Rotate the audit log file once it grows over this size in megabytes, 0 disables rotation")
+	fs.IntVar(&c.Audit.MaxBackups, "audit_max_backups", c.Audit.MaxBackups, "Number of rotated audit log files to keep")
+	fs.StringVar(&c.Audit.RedisStream, "audit_redis_stream", c.Audit.RedisStream, "Redis stream to also add the audit records to, on the Redis of the store options, empty disables it")
+	fs.Int64Var(&c.Audit.RedisMaxLen, "audit_redis_max_len", c.Audit.RedisMaxLen, "Trim the audit Redis stream to about this many records, 0 keeps all records")
+
+	fs.DurationVar(&c.Shutdown.Timeout, "shutdown_timeout", c.Shutdown.Timeout, "Time to drain in-flight calls and operations on SIGINT or SIGTERM before they are cancelled")
+
+	fs.BoolVar(&c.Features.AsyncOperations, "enable_async_operations", c.Features.AsyncOperations, "Allow clients to run calls as long-running operations")
+	fs.BoolVar(&c.Features.Watch, "enable_watch", c.Features.Watch, "Serve the NvmeWatchService")
+	fs.BoolVar(&c.Features.Reflection, "enable_reflection", c.Features.Reflection, "Serve the gRPC reflection service")
+
+	fs.BoolVar(&c.Auth.Enabled, "auth", c.Auth.Enabled, "Authenticate the callers and authorize them by role")
+	fs.Var(&listValue{&c.Auth.TrustedProxies}, "auth_trusted_proxies", "Comma-separated common names of the client certificates allowed to forward the client certificate of their caller")
+	fs.StringVar(&c.Auth.TokensFile, "auth_tokens_file", c.Auth.TokensFile, "YAML file of the static bearer tokens, by SHA-256, and their roles")
+	fs.StringVar(&c.Auth.JWT.JWKSFile, "auth_jwks_file", c.Auth.JWT.JWKSFile, "JWKS file verifying the JWT bearer tokens")
+	fs.StringVar(&c.Auth.JWT.Issuer, "auth_jwt_issuer", c.Auth.JWT.Issuer, "Expected issuer of the JWT bearer tokens")
+	fs.StringVar(&c.Auth.JWT.Audience, "auth_jwt_audience", c.Auth.JWT.Audience, "Expected audience of the JWT bearer tokens")
+	fs.StringVar(&c.Auth.JWT.RolesClaim, "auth_jwt_roles_claim", c.Auth.JWT.RolesClaim, "Claim of the JWT bearer tokens holding the roles, e.g. realm_access.roles")
+	fs.StringVar(&c.Auth.JWT.TenantClaim, "auth_jwt_tenant_claim", c.Auth.JWT.TenantClaim, "Claim of the JWT bearer tokens holding the tenant")
+
+	fs.IntVar(&c.Frontend.MinCtrlrID, "min_ctrlr_id", c.Frontend.MinCtrlrID, "Lowest controller ID of the subsystems created without x-opi-ctrlr-id-range metadata")
+	fs.IntVar(&c.Frontend.MaxCtrlrID, "max_ctrlr_id", c.Frontend.MaxCtrlrID, "Highest controller ID of the subsystems created without x-opi-ctrlr-id-range metadata")
+	fs.BoolVar(&c.Frontend.ShareNamespaces, "share_namespaces", c.Frontend.ShareNamespaces, "Allow namespaces to be attached to several controllers")
+	fs.Var(&pcieFunctionsValue{&c.Frontend.PcieFunctions}, "pcie_functions", "Inventory of the PCIe functions of the controllers in port:pf:vfs[:max_nsq:max_ncq],... format")
+	fs.IntVar(&c.Frontend.QueueBudget.MaxNsq, "max_nsq", c.Frontend.QueueBudget.MaxNsq, "Budget of submission queues of the controllers of the device, 0 is unlimited")
+	fs.IntVar(&c.Frontend.QueueBudget.MaxNcq, "max_ncq", c.Frontend.QueueBudget.MaxNcq, "Budget of completion queues of the controllers of the device, 0 is unlimited")
+	fs.IntVar(&c.Frontend.QueueBudget.MaxMqes, "max_mqes", c.Frontend.QueueBudget.MaxMqes, "Maximum queue entries of a controller, 0 is unlimited")
+	fs.StringVar(&c.Frontend.OUI, "oui", c.Frontend.OUI, "IEEE OUI of the NGUIDs and EUI-64s generated for the namespaces, in xx:xx:xx format")
+	fs.DurationVar(&c.Frontend.RemovalTimeout, "ctrlr_removal_timeout", c.Frontend.RemovalTimeout, "Time the controller deletions wait for the host to release the queues, 0 removes the controllers by force right away")
+	fs.DurationVar(&c.Frontend.RemovalPollInterval, "ctrlr_removal_poll_interval", c.Frontend.RemovalPollInterval, "Interval between checks of the queues released by the host on controller deletion")
+	fs.BoolVar(&c.Frontend.CheckVolumes, "check_volumes", c.Frontend.CheckVolumes, "Reject the namespaces of volumes unknown to the bridge and the SDK")
+
+	q := &c.Tenants.DefaultQuota
+	fs.IntVar(&q.MaxSubsystems, "tenant_max_subsystems", q.MaxSubsystems, "Default maximum number of subsystems of a tenant, 0 is unlimited")
+	fs.IntVar(&q.MaxNamespaces, "tenant_max_namespaces", q.MaxNamespaces, "Default maximum number of namespaces of a tenant, 0 is unlimited")
+	fs.IntVar(&q.MaxVfControllers, "tenant_max_vf_controllers", q.MaxVfControllers, "Default maximum number of VF controllers of a tenant, 0 is unlimited")
+	fs.IntVar(&q.MaxSubmissionQueues, "tenant_max_submission_queues", q.MaxSubmissionQueues, "Default maximum number of submission queues of a tenant, 0 is unlimited")
+	fs.IntVar(&q.MaxCompletionQueues, "tenant_max_completion_queues", q.MaxCompletionQueues, "Default maximum number of completion queues of a tenant, 0 is unlimited")
+}
+
+// tlsValue is the -tls flag, in server_cert:server_key:ca_cert format
+type tlsValue struct {
+	c *TLSConfig
+}
+
+func (v *tlsValue) String() string {
+	if v.c == nil || !v.c.Enabled() {
+		return ""
+	}
+	return strings.Join([]string{v.c.CertFile, v.c.KeyFile, v.c.CAFile}, ":")
+}
+
+func (v *tlsValue) Set(s string) error {
+	if s == "" {
+		*v.c = TLSConfig{}
+		return nil
+	}
+	files, err := utils.ParseTLSFiles(s)
+	if err != nil {
+		return err
+	}
+	*v.c = TLSConfig{CertFile: files.ServerCertPath, KeyFile: files.ServerKeyPath, CAFile: files.CaCertPath}
+	return nil
+}
+
+// listValue is a comma-separated list flag
+type listValue struct {
+	list *[]string
+}
+
+func (v *listValue) String() string {
+	if v.list == nil {
+		return ""
+	}
+	return strings.Join(*v.list, ",")
+}
+
+func (v *listValue) Set(s string) error {
+	*v.list = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*v.list = append(*v.list, item)
+		}
+	}
+	return nil
+}
+
+// pcieFunctionsValue is the -pcie_functions flag, in
+// port:pf:vfs[:max_nsq:max_ncq],... format
+type pcieFunctionsValue struct {
+	list *[]PcieFunctionConfig
+}
+
+func (v *pcieFunctionsValue) String() string {
+	if v.list == nil {
+		return ""
+	}
+	items := make([]string, 0, len(*v.list))
+	for _, f := range *v.list {
+		item := fmt.Sprintf("%d:%d:%d", f.PortID, f.PhysicalFunction, f.VirtualFunctions)
+		if f.MaxNsq != 0 || f.MaxNcq != 0 {
+			item += fmt.Sprintf(":%d:%d", f.MaxNsq, f.MaxNcq)
+		}
+		items = append(items, item)
+	}
+	return strings.Join(items, ",")
+}
+
+func (v *pcieFunctionsValue) Set(s string) error {
+	*v.list = nil
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		fields := strings.Split(item, ":")
+		values := make([]int, len(fields))
+		for i, field := range fields {
+			value, err := strconv.Atoi(field)
+			if err != nil {
+				values = nil
+				break
+			}
+			values[i] = value
+		}
+		if len(values) != 3 && len(values) != 5 {
+			return fmt.Errorf("PCIe function %q is not in port:pf:vfs[:max_nsq:max_ncq] format", item)
+		}
+		f := PcieFunctionConfig{PortID: values[0], PhysicalFunction: values[1], VirtualFunctions: values[2]}
+		if len(values) == 5 {
+			f.MaxNsq, f.MaxNcq = values[3], values[4]
+		}
+		*v.list = append(*v.list, f)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// forceKey is the request metadata key of the cascading deletion of the
+// children of a resource, see https://google.aip.dev/135#cascading-delete
+const forceKey = "x-opi-force"
+
+// forceRequested checks if the client opted in to a cascading deletion
+func forceRequested(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, v := range md.Get(forceKey) {
+		if force, err := strconv.ParseBool(v); err == nil && force {
+			return true
+		}
+	}
+	return false
+}
+
+// subsystemNamespaces fetches all namespaces created in the given subsystem
+func (s *Server) subsystemNamespaces(subsys *pb.NvmeSubsystem) ([]*pb.NvmeNamespace, error) {
+	namespaces := []*pb.NvmeNamespace{}
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, subsys.Name+"/nvmeNamespaces/") {
+			continue
+		}
+		namespace := new(pb.NvmeNamespace)
+		found, err := s.store.Get(key, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		namespaces = append(namespaces, namespace)
+	}
+	sortNvmeNamespaces(namespaces)
+	return namespaces, nil
+}
+
+// subsystemChildren holds the controllers and namespaces of a subsystem,
+// with their labels and annotations to restore them
+type subsystemChildren struct {
+	controllers []*pb.NvmeController
+	namespaces  []*pb.NvmeNamespace
+	metadata    map[string]*mb.NvmeResourceMetadata
+}
+
+// checkSubsystemEmpty returns FailedPrecondition if the subsystem still has
+// controllers or namespaces, unless they are deleted by force
+func (s *Server) checkSubsystemEmpty(subsys *pb.NvmeSubsystem, force bool) (*subsystemChildren, error) {
+	controllers, err := s.subsystemControllers(subsys)
+	if err != nil {
+		return nil, err
+	}
+	namespaces, err := s.subsystemNamespaces(subsys)
+	if err != nil {
+		return nil, err
+	}
+	if len(controllers) == 0 && len(namespaces) == 0 {
+		return nil, nil
+	}
+	if !force {
+		return nil, status.Errorf(codes.FailedPrecondition, "%s still has %d controllers and %d namespaces, delete them first or set %s",
+			subsys.Name, len(controllers), len(namespaces), forceKey)
+	}
+	children := &subsystemChildren{
+		controllers: controllers,
+		namespaces:  namespaces,
+		metadata:    make(map[string]*mb.NvmeResourceMetadata),
+	}
+	for _, c := range controllers {
+		if children.metadata[c.Name], err = s.resourceMetadata(c.Name); err != nil {
+			return nil, err
+		}
+	}
+	for _, namespace := range namespaces {
+		if children.metadata[namespace.Name], err = s.resourceMetadata(namespace.Name); err != nil {
+			return nil, err
+		}
+	}
+	return children, nil
+}
+
+// deleteSubsystemChildren deletes the namespaces and then the controllers
+// of a subsystem, the latter concurrently following the removal policy, and
+// recreates the deleted ones if any deletion fails
+func (s *Server) deleteSubsystemChildren(ctx context.Context, subsys *pb.NvmeSubsystem, children *subsystemChildren, policy removalPolicy) error {
+	deleted := &subsystemChildren{metadata: children.metadata}
+	for _, namespace := range children.namespaces {
+		err := checkOperationCanceled(ctx)
+		if err == nil {
+			_, err = s.deleteNvmeNamespace(ctx, &pb.DeleteNvmeNamespaceRequest{Name: namespace.Name})
+		}
+		if err != nil {
+			s.restoreSubsystemChildren(ctx, subsys, deleted)
+			return err
+		}
+		deleted.namespaces = append(deleted.namespaces, namespace)
+	}
+	// the removals wait for the hosts at the same time, up to one
+	// RemovalTimeout in total
+	errs := make([]error, len(children.controllers))
+	var wg sync.WaitGroup
+	for i, c := range children.controllers {
+		wg.Add(1)
+		go func(i int, c *pb.NvmeController) {
+			defer wg.Done()
+			errs[i] = checkOperationCanceled(ctx)
+			if errs[i] == nil {
+				_, errs[i] = s.deleteNvmeController(ctx, &pb.DeleteNvmeControllerRequest{Name: c.Name}, policy)
+			}
+		}(i, c)
+	}
+	wg.Wait()
+	var failed error
+	for i, c := range children.controllers {
+		if errs[i] == nil {
+			deleted.controllers = append(deleted.controllers, c)
+		} else if failed == nil {
+			failed = errs[i]
+		}
+	}
+	if failed != nil {
+		s.restoreSubsystemChildren(ctx, subsys, deleted)
+		return failed
+	}
+	return nil
+}
+
+// restoreSubsystemChildren recreates the controllers and then the namespaces
+// of a subsystem deleted by a failed cascading deletion, with the same IDs
+// and enabled state
+func (s *Server) restoreSubsystemChildren(ctx context.Context, subsys *pb.NvmeSubsystem, children *subsystemChildren) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	logger.WarnContext(ctx, "Rolling back failed NvmeSubsystem deletion", "name", subsys.Name)
+	for _, c := range children.controllers {
+		in := &pb.CreateNvmeControllerRequest{
+			Parent:           subsys.Name,
+			NvmeController:   utils.ProtoClone(c),
+			NvmeControllerId: path.Base(c.Name),
+		}
+		if _, err := s.createNvmeController(ctx, in, subsys, children.metadata[c.Name]); err != nil {
+			logger.ErrorContext(ctx, "Could not recreate CTRL on rollback", "name", c.Name, "error", err)
+		}
+	}
+	for _, namespace := range children.namespaces {
+		bdev, err := s.namespaceBdev(ctx, namespace.Spec)
+		if err == nil {
+			in := &pb.CreateNvmeNamespaceRequest{
+				Parent:          subsys.Name,
+				NvmeNamespace:   utils.ProtoClone(namespace),
+				NvmeNamespaceId: path.Base(namespace.Name),
+			}
+			_, err = s.createNvmeNamespace(ctx, in, children.metadata[namespace.Name], bdev)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Could not recreate NS on rollback", "name", namespace.Name, "error", err)
+		}
+	}
+	// the children are recreated enabled, disable again the disabled ones
+	for _, namespace := range children.namespaces {
+		if namespaceEnabled(namespace) {
+			continue
+		}
+		if _, err := s.setNvmeNamespaceEnabled(ctx, namespace.Name, false); err != nil {
+			logger.ErrorContext(ctx, "Could not disable NS on rollback", "name", namespace.Name, "error", err)
+		}
+	}
+	for _, c := range children.controllers {
+		if controllerActive(c) {
+			continue
+		}
+		if _, err := s.setNvmeControllerActive(ctx, c.Name, false, removalForce); err != nil {
+			logger.ErrorContext(ctx, "Could not disable CTRL on rollback", "name", c.Name, "error", err)
+		}
+	}
+}
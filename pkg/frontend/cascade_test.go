@@ -0,0 +1,268 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/gospdk/spdk"
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_DeleteNvmeSubsystemCascade(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	tests := map[string]struct {
+		force   bool
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+		exist   bool
+	}{
+		"children without force": {
+			force:   false,
+			spdk:    []string{},
+			errCode: codes.FailedPrecondition,
+			errMsg:  fmt.Sprintf("%v still has 1 controllers and 1 namespaces, delete them first or set x-opi-force", testSubsystemName),
+			exist:   true,
+		},
+		"children deleted by force": {
+			force: true,
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+		},
+		"children restored on controller failure": {
+			force: true,
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 22}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+			},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not delete CTRL: %v", testControllerName),
+			exist:   true,
+		},
+		"children restored on subsystem failure": {
+			force: true,
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 22}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+			},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not delete NQN: %v", testSubsystem.Spec.Nqn),
+			exist:   true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, &testControllerWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Spec.Nguid = "0x25f9cbc45d0f976fb9c1a14ff5aed4b0"
+			namespace.Spec.Uuid = "1b4e28ba-2fa1-11d2-883f-b9a761bde3fb"
+			namespace.Spec.Eui64 = 1967554867335598546
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+			s.ListHelper[testNamespaceName] = false
+			if _, err := s.allocateCtrlrID(testSubsystemName, testControllerName, testController.Spec.NvmeControllerId); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.allocateNsid(testSubsystemName, testNamespaceName, namespace.Spec.HostNsid); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.reserveNamespaceIdentifiers(testNamespaceName, namespace.Spec); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := testEnv.ctx
+			if tt.force {
+				ctx = metadata.AppendToOutgoingContext(ctx, forceKey, "true")
+			}
+			_, err := testEnv.client.DeleteNvmeSubsystem(ctx, &pb.DeleteNvmeSubsystemRequest{Name: testSubsystemName})
+
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+			for key, value := range map[string]proto.Message{
+				testSubsystemName:  new(pb.NvmeSubsystem),
+				testControllerName: new(pb.NvmeController),
+				testNamespaceName:  new(pb.NvmeNamespace),
+			} {
+				found, err := s.store.Get(key, value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if found != tt.exist {
+					t.Error(key, "exists: expected", tt.exist, "received", found)
+				}
+			}
+			restored := new(pb.NvmeNamespace)
+			if _, err := s.store.Get(testNamespaceName, restored); err != nil {
+				t.Fatal(err)
+			}
+			if tt.exist && restored.GetSpec().GetNguid() != namespace.Spec.Nguid {
+				t.Error("Nguid: expected", namespace.Spec.Nguid, "received", restored.GetSpec().GetNguid())
+			}
+		})
+	}
+}
+
+// concurrentRemovalJSONRPC holds the controller removals until all of them
+// are in flight, and fails them if they are issued one after another. The
+// test SPDK server answers one call at a time, so they are then passed on
+// one after another.
+type concurrentRemovalJSONRPC struct {
+	spdk.JSONRPC
+	pending sync.WaitGroup
+	calls   sync.Mutex
+}
+
+func (r *concurrentRemovalJSONRPC) Call(ctx context.Context, method string, args, result interface{}) error {
+	if method == "mrvl_nvm_subsys_remove_ctrlr" {
+		r.pending.Done()
+		all := make(chan struct{})
+		go func() {
+			r.pending.Wait()
+			close(all)
+		}()
+		select {
+		case <-all:
+		case <-time.After(time.Second):
+			return errors.New("controllers removed one after another")
+		}
+	}
+	r.calls.Lock()
+	defer r.calls.Unlock()
+	return r.JSONRPC.Call(ctx, method, args, result)
+}
+
+func TestFrontEnd_DeleteNvmeSubsystemCascadeConcurrently(t *testing.T) {
+	removed := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	testEnv := createTestEnvironment([]string{removed, removed, removed})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	otherControllerName := utils.ResourceIDToControllerName(testSubsystemID, "controller-other")
+
+	if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+		t.Fatal(err)
+	}
+	other := utils.ProtoClone(&testControllerWithStatus)
+	other.Name = otherControllerName
+	other.Spec.NvmeControllerId = proto.Int32(18)
+	for _, c := range []*pb.NvmeController{&testControllerWithStatus, other} {
+		if err := s.store.Set(c.Name, c); err != nil {
+			t.Fatal(err)
+		}
+		s.ListHelper[c.Name] = false
+		if _, err := s.allocateCtrlrID(testSubsystemName, c.Name, c.Spec.NvmeControllerId); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s.ListHelper[testSubsystemName] = false
+	rpc := &concurrentRemovalJSONRPC{JSONRPC: s.rpc}
+	rpc.pending.Add(2)
+	s.rpc = rpc
+
+	ctx := metadata.AppendToOutgoingContext(testEnv.ctx, forceKey, "true")
+	if _, err := testEnv.client.DeleteNvmeSubsystem(ctx, &pb.DeleteNvmeSubsystemRequest{Name: testSubsystemName}); err != nil {
+		t.Fatal("expected no error, received", err)
+	}
+	for _, name := range []string{testSubsystemName, testControllerName, otherControllerName} {
+		found, err := s.store.Get(name, new(pb.NvmeController))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found {
+			t.Error(name, "exists: expected false, received", found)
+		}
+	}
+}
+
+// unreachableJSONRPC fails the calls to the SDK
+type unreachableJSONRPC struct {
+	spdk.JSONRPC
+}
+
+func (unreachableJSONRPC) Call(ctx context.Context, method string, args, result interface{}) error {
+	return errors.New("SDK unreachable")
+}
+
+func TestFrontEnd_DeleteNvmeSubsystemAfterRestart(t *testing.T) {
+	testEnv := createTestEnvironment([]string{})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+
+	for key, value := range map[string]proto.Message{
+		testSubsystemName:  &testSubsystemWithStatus,
+		testControllerName: &testControllerWithStatus,
+		testNamespaceName:  &testNamespaceWithStatus,
+	} {
+		if err := s.store.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the next instance of the server shares the store
+	store := keyListingStore{
+		Store: s.store.(*watchedStore).Store,
+		keys:  []string{testSubsystemName, testControllerName, testNamespaceName, metadataKey(testSubsystemName)},
+	}
+	restarted := NewServer(unreachableJSONRPC{testEnv.jsonRPC}, store)
+
+	_, err := restarted.DeleteNvmeSubsystem(testEnv.ctx, &pb.DeleteNvmeSubsystemRequest{Name: testSubsystemName})
+	checkTenantError(t, err, codes.FailedPrecondition,
+		fmt.Sprintf("%v still has 1 controllers and 1 namespaces, delete them first or set x-opi-force", testSubsystemName))
+
+	users, err := restarted.volumeUsers(testNamespace.Spec.VolumeNameRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{testNamespaceName}; !reflect.DeepEqual(users, want) {
+		t.Error("volume users: expected", want, "received", users)
+	}
+}
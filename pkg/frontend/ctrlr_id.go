@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// ctrlrIDRangeKey is the request metadata key of the controller ID range
+// of a created subsystem, in min-max format
+const ctrlrIDRangeKey = "x-opi-ctrlr-id-range"
+
+// autoCtrlrID requests the allocation of a controller ID like an omitted
+// one, as autoCtrlrIDAllocation of the SDK
+const autoCtrlrID = -1
+
+// maxNvmeCtrlrID is the highest NVMe controller ID, higher ones are reserved
+const maxNvmeCtrlrID = 0xffef
+
+// ctrlrIDRangeStoreKey is the store key of the controller ID range of a
+// subsystem
+func ctrlrIDRangeStoreKey(subsysName string) string {
+	return "ctrlrIDRanges/" + subsysName
+}
+
+// ctrlrIDKey is the store key of the allocation of a controller ID of a
+// subsystem to a controller
+func ctrlrIDKey(subsysName string, id int) string {
+	return fmt.Sprintf("ctrlrIDs/%s/%d", subsysName, id)
+}
+
+// requestCtrlrIDRange returns the controller ID range set in the request
+// metadata, the one of the options if none
+func (s *Server) requestCtrlrIDRange(ctx context.Context) (*mb.NvmeControllerIdRange, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(ctrlrIDRangeKey)
+	if len(values) == 0 {
+		return &mb.NvmeControllerIdRange{MinCtrlrId: int32(s.opts.MinCtrlrID), MaxCtrlrId: int32(s.opts.MaxCtrlrID)}, nil
+	}
+	minID, maxID, ok := strings.Cut(values[len(values)-1], "-")
+	low, lerr := strconv.Atoi(minID)
+	high, herr := strconv.Atoi(maxID)
+	if !ok || lerr != nil || herr != nil || low < 0 || low > high || high > maxNvmeCtrlrID {
+		msg := fmt.Sprintf("%s value (%s) is not a min-max range between 0 and %d", ctrlrIDRangeKey, values[len(values)-1], maxNvmeCtrlrID)
+		return nil, status.Errorf(codes.InvalidArgument, msg)
+	}
+	return &mb.NvmeControllerIdRange{MinCtrlrId: int32(low), MaxCtrlrId: int32(high)}, nil
+}
+
+// sendCtrlrIDRangeHeader returns the controller ID range of a subsystem in
+// the response header
+func sendCtrlrIDRangeHeader(ctx context.Context, r *mb.NvmeControllerIdRange) {
+	md := metadata.Pairs(ctrlrIDRangeKey, fmt.Sprintf("%d-%d", r.MinCtrlrId, r.MaxCtrlrId))
+	if err := grpc.SetHeader(ctx, md); err != nil {
+		logger.DebugContext(ctx, "cannot send the controller ID range", "error", err)
+	}
+}
+
+// subsystemCtrlrIDRange returns the controller ID range of a subsystem, the
+// one of the options for the subsystems created without range
+func (s *Server) subsystemCtrlrIDRange(subsysName string) (*mb.NvmeControllerIdRange, error) {
+	r := new(mb.NvmeControllerIdRange)
+	found, err := s.store.Get(ctrlrIDRangeStoreKey(subsysName), r)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &mb.NvmeControllerIdRange{MinCtrlrId: int32(s.opts.MinCtrlrID), MaxCtrlrId: int32(s.opts.MaxCtrlrID)}, nil
+	}
+	return r, nil
+}
+
+// ctrlrIDUser returns the controller allocated a controller ID of a
+// subsystem, empty if it is free
+func (s *Server) ctrlrIDUser(subsysName string, id int) (string, error) {
+	user := new(wrapperspb.StringValue)
+	if _, err := s.store.Get(ctrlrIDKey(subsysName, id), user); err != nil {
+		return "", err
+	}
+	return user.Value, nil
+}
+
+// allocateCtrlrID allocates the controller ID requested for the controller
+// name of a subsystem, or the lowest free one of the subsystem range if
+// nil or autoCtrlrID, and returns it
+func (s *Server) allocateCtrlrID(subsysName string, name string, requested *int32) (int, error) {
+	s.ctrlrIDMutex.Lock()
+	defer s.ctrlrIDMutex.Unlock()
+	r, err := s.subsystemCtrlrIDRange(subsysName)
+	if err != nil {
+		return 0, err
+	}
+	id := -1
+	if requested != nil && *requested != autoCtrlrID {
+		id = int(*requested)
+		if id < int(r.MinCtrlrId) || id > int(r.MaxCtrlrId) {
+			msg := fmt.Sprintf("Controller ID (%d) is not in the range %d-%d of %s", id, r.MinCtrlrId, r.MaxCtrlrId, subsysName)
+			return 0, status.Errorf(codes.InvalidArgument, msg)
+		}
+		user, err := s.ctrlrIDUser(subsysName, id)
+		if err != nil {
+			return 0, err
+		}
+		if user != "" && user != name {
+			return 0, status.Errorf(codes.AlreadyExists, "Controller ID (%d) of %s is already used by %s", id, subsysName, user)
+		}
+	} else {
+		for candidate := int(r.MinCtrlrId); candidate <= int(r.MaxCtrlrId); candidate++ {
+			user, err := s.ctrlrIDUser(subsysName, candidate)
+			if err != nil {
+				return 0, err
+			}
+			if user == "" || user == name {
+				id = candidate
+				break
+			}
+		}
+		if id < 0 {
+			return 0, status.Errorf(codes.ResourceExhausted, "no free controller ID in the range %d-%d of %s", r.MinCtrlrId, r.MaxCtrlrId, subsysName)
+		}
+	}
+	if err := s.store.Set(ctrlrIDKey(subsysName, id), wrapperspb.String(name)); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// releaseCtrlrID frees the controller ID of a subsystem allocated to the
+// controller name
+func (s *Server) releaseCtrlrID(subsysName string, name string, id int) error {
+	s.ctrlrIDMutex.Lock()
+	defer s.ctrlrIDMutex.Unlock()
+	user, err := s.ctrlrIDUser(subsysName, id)
+	if err != nil {
+		return err
+	}
+	if user != name {
+		return nil
+	}
+	return s.store.Delete(ctrlrIDKey(subsysName, id))
+}
+
+// releaseCtrlrIDOrLog frees the controller ID of a subsystem allocated to
+// the controller name, only logging the failures
+func (s *Server) releaseCtrlrIDOrLog(ctx context.Context, subsysName string, name string, id int) {
+	if err := s.releaseCtrlrID(subsysName, name, id); err != nil {
+		logger.ErrorContext(ctx, "Could not release the controller ID", "name", name, "error", err)
+	}
+}
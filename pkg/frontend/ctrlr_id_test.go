@@ -0,0 +1,256 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_CtrlrIDs(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	newControllerName := utils.ResourceIDToControllerName(testSubsystemID, "controller-new")
+	newSubsystemName := utils.ResourceIDToSubsystemName("subsystem-new")
+	controllerSpec := func(id *int32) *pb.NvmeControllerSpec {
+		return &pb.NvmeControllerSpec{
+			Endpoint:         &pb.NvmeControllerSpec_PcieId{PcieId: pcieEndpoint{port: 0, pf: 0, vf: 1}.proto()},
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: id,
+		}
+	}
+	createController := func(id *int32) func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+		return func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+			return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: &pb.NvmeController{Spec: controllerSpec(id)}, NvmeControllerId: "controller-new"})
+		}
+	}
+
+	tests := map[string]struct {
+		ctrlrIDs  *mb.NvmeControllerIdRange
+		ctrlrID   string
+		call      func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out       proto.Message
+		spdk      []string
+		errCode   codes.Code
+		errMsg    string
+		allocated map[int]string
+	}{
+		"lowest free ID": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call:     createController(nil),
+			out: &pb.NvmeController{
+				Name:   newControllerName,
+				Spec:   controllerSpec(proto.Int32(16)),
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":16}}`},
+			errCode:   codes.OK,
+			errMsg:    "",
+			allocated: map[int]string{16: newControllerName, 17: testControllerName},
+		},
+		"automatic ID": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call:     createController(proto.Int32(-1)),
+			out: &pb.NvmeController{
+				Name:   newControllerName,
+				Spec:   controllerSpec(proto.Int32(16)),
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":16}}`},
+			errCode:   codes.OK,
+			errMsg:    "",
+			allocated: map[int]string{16: newControllerName, 17: testControllerName},
+		},
+		"chosen ID": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call:     createController(proto.Int32(18)),
+			out: &pb.NvmeController{
+				Name:   newControllerName,
+				Spec:   controllerSpec(proto.Int32(18)),
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`},
+			errCode:   codes.OK,
+			errMsg:    "",
+			allocated: map[int]string{17: testControllerName, 18: newControllerName},
+		},
+		"ID already used": {
+			ctrlrIDs:  &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call:      createController(proto.Int32(17)),
+			out:       nil,
+			spdk:      []string{},
+			errCode:   codes.AlreadyExists,
+			errMsg:    fmt.Sprintf("Controller ID (17) of %v is already used by %v", testSubsystemName, testControllerName),
+			allocated: map[int]string{17: testControllerName},
+		},
+		"ID out of the range": {
+			ctrlrIDs:  &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call:      createController(proto.Int32(20)),
+			out:       nil,
+			spdk:      []string{},
+			errCode:   codes.InvalidArgument,
+			errMsg:    fmt.Sprintf("Controller ID (20) is not in the range 16-18 of %v", testSubsystemName),
+			allocated: map[int]string{17: testControllerName},
+		},
+		"no free ID": {
+			ctrlrIDs:  &mb.NvmeControllerIdRange{MinCtrlrId: 17, MaxCtrlrId: 17},
+			call:      createController(nil),
+			out:       nil,
+			spdk:      []string{},
+			errCode:   codes.ResourceExhausted,
+			errMsg:    fmt.Sprintf("no free controller ID in the range 17-17 of %v", testSubsystemName),
+			allocated: map[int]string{17: testControllerName},
+		},
+		"release on SDK failure": {
+			ctrlrIDs:  &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call:      createController(nil),
+			out:       nil,
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":1}}`},
+			errCode:   codes.InvalidArgument,
+			errMsg:    fmt.Sprintf("Could not create CTRL: %v", newControllerName),
+			allocated: map[int]string{17: testControllerName},
+		},
+		"change the ID": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				controller := &pb.NvmeController{Name: testControllerName, Spec: utils.ProtoClone(testController.Spec)}
+				controller.Spec.NvmeControllerId = proto.Int32(18)
+				return c.UpdateNvmeController(ctx, &pb.UpdateNvmeControllerRequest{NvmeController: controller})
+			},
+			out: &pb.NvmeController{
+				Name: testControllerName,
+				Spec: &pb.NvmeControllerSpec{
+					Endpoint:         testController.Spec.Endpoint,
+					Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+					NvmeControllerId: proto.Int32(18),
+				},
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`},
+			errCode:   codes.OK,
+			errMsg:    "",
+			allocated: map[int]string{18: testControllerName},
+		},
+		"release on delete": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.DeleteNvmeController(ctx, &pb.DeleteNvmeControllerRequest{Name: testControllerName})
+			},
+			out:       nil,
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0}}`},
+			errCode:   codes.OK,
+			errMsg:    "",
+			allocated: map[int]string{},
+		},
+		"subsystem range": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			ctrlrID:  "32-63",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{
+					NvmeSubsystem: &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5"}}, NvmeSubsystemId: "subsystem-new"})
+			},
+			out: &pb.NvmeSubsystem{
+				Name:   newSubsystemName,
+				Spec:   &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5"},
+				Status: &pb.NvmeSubsystemStatus{FirmwareRevision: "SPDK v20.10"},
+			},
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v20.10","fields":{"major":20,"minor":10,"patch":0,"suffix":""}}}`,
+			},
+			errCode:   codes.OK,
+			errMsg:    "",
+			allocated: map[int]string{17: testControllerName},
+		},
+		"invalid subsystem range": {
+			ctrlrIDs: &mb.NvmeControllerIdRange{MinCtrlrId: 16, MaxCtrlrId: 18},
+			ctrlrID:  "63-32",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{
+					NvmeSubsystem: &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5"}}, NvmeSubsystemId: "subsystem-new"})
+			},
+			out:       nil,
+			spdk:      []string{},
+			errCode:   codes.InvalidArgument,
+			errMsg:    fmt.Sprintf("%s value (63-32) is not a min-max range between 0 and %d", ctrlrIDRangeKey, maxNvmeCtrlrID),
+			allocated: map[int]string{17: testControllerName},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, &testControllerWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(ctrlrIDRangeStoreKey(testSubsystemName), tt.ctrlrIDs); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+			if _, err := s.allocateCtrlrID(testSubsystemName, testControllerName, testController.Spec.NvmeControllerId); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := testEnv.ctx
+			if tt.ctrlrID != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, ctrlrIDRangeKey, tt.ctrlrID)
+			}
+			response, err := tt.call(ctx, testEnv.client)
+
+			if tt.out != nil && !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+
+			allocated := map[int]string{}
+			for id := 0; id <= 64; id++ {
+				user, err := s.ctrlrIDUser(testSubsystemName, id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if user != "" {
+					allocated[id] = user
+				}
+			}
+			if !reflect.DeepEqual(allocated, tt.allocated) {
+				t.Error("controller IDs: expected", tt.allocated, "received", allocated)
+			}
+			if tt.ctrlrID != "" && tt.errCode == codes.OK {
+				ctrlrIDs, err := s.subsystemCtrlrIDRange(newSubsystemName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if expected := (&mb.NvmeControllerIdRange{MinCtrlrId: 32, MaxCtrlrId: 63}); !proto.Equal(ctrlrIDs, expected) {
+					t.Error("controller ID range: expected", expected, "received", ctrlrIDs)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// removalPolicyKey is the request metadata key of the removal policy of
+// the deleted controllers
+const removalPolicyKey = "x-opi-removal-policy"
+
+// removalPolicy tells how a controller is removed while the host still
+// uses its queues
+type removalPolicy string
+
+const (
+	// removalForce removes the controller by force right away
+	removalForce removalPolicy = "force"
+	// removalGraceful waits for the host to release the queues, and fails
+	// if it does not in time
+	removalGraceful removalPolicy = "graceful"
+	// removalGracefulThenForce waits for the host to release the queues,
+	// and removes the controller by force if it does not in time
+	removalGracefulThenForce removalPolicy = "graceful-then-force"
+)
+
+// requestRemovalPolicy returns the removal policy set in the request
+// metadata, graceful-then-force by default when the options set a removal
+// timeout and force otherwise
+func (s *Server) requestRemovalPolicy(ctx context.Context) (removalPolicy, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(removalPolicyKey)
+	if len(values) == 0 {
+		if s.opts.RemovalTimeout > 0 {
+			return removalGracefulThenForce, nil
+		}
+		return removalForce, nil
+	}
+	switch policy := removalPolicy(values[len(values)-1]); policy {
+	case removalForce, removalGraceful, removalGracefulThenForce:
+		return policy, nil
+	default:
+		msg := fmt.Sprintf("%s value (%s) is not one of %s, %s or %s", removalPolicyKey, policy, removalForce, removalGraceful, removalGracefulThenForce)
+		return "", status.Errorf(codes.InvalidArgument, msg)
+	}
+}
+
+// removeCtrlr asks the SDK to remove a controller, and reports whether it
+// did. Only the failures of the forced removal are errors.
+func (s *Server) removeCtrlr(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController, force bool) (bool, error) {
+	params := models.MrvlNvmSubsysRemoveCtrlrParams{
+		Subnqn:  subsys.Spec.Nqn,
+		CtrlrID: int(controller.Spec.GetNvmeControllerId()),
+	}
+	if force {
+		params.Force = 1
+	}
+	var result models.MrvlNvmSubsysRemoveCtrlrResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_subsys_remove_ctrlr", &params, &result)
+	if err != nil {
+		return false, err
+	}
+	if result.Status != 0 {
+		if force {
+			msg := fmt.Sprintf("Could not delete CTRL: %s", controller.Name)
+			return false, status.Errorf(codes.InvalidArgument, msg)
+		}
+		return false, nil
+	}
+	return true, nil
+}
+
+// activeQueues returns the submission and completion queues of a
+// controller still used by the host
+func (s *Server) activeQueues(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController) (int, int, error) {
+	params := models.MrvlNvmGetCtrlrInfoParams{
+		Subnqn:  subsys.Spec.Nqn,
+		CtrlrID: int(controller.Spec.GetNvmeControllerId()),
+	}
+	var result models.MrvlNvmGetCtrlrInfoResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_ctrlr_get_info", &params, &result)
+	if err != nil {
+		return 0, 0, err
+	}
+	if result.Status != 0 {
+		msg := fmt.Sprintf("Could not get CTRL: %s", controller.Name)
+		return 0, 0, status.Errorf(codes.InvalidArgument, msg)
+	}
+	return result.ActiveNsq, result.ActiveNcq, nil
+}
+
+// removeNvmeController removes a controller from the SDK following the
+// removal policy: the graceful removal first tries without force, then
+// waits up to RemovalTimeout for the host to release the queues
+func (s *Server) removeNvmeController(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController, policy removalPolicy) error {
+	if policy == removalForce {
+		_, err := s.removeCtrlr(ctx, subsys, controller, true)
+		return err
+	}
+	removed, err := s.removeCtrlr(ctx, subsys, controller, false)
+	if err != nil || removed {
+		return err
+	}
+	released, nsq, ncq, err := s.waitQueuesReleased(ctx, subsys, controller, func() (bool, error) {
+		return s.removeCtrlr(ctx, subsys, controller, false)
+	})
+	if err != nil || released {
+		return err
+	}
+	if policy == removalGracefulThenForce {
+		logger.WarnContext(ctx, "Host did not release the controller in time, removing it by force",
+			"name", controller.Name, "active_nsq", nsq, "active_ncq", ncq)
+		_, err := s.removeCtrlr(ctx, subsys, controller, true)
+		return err
+	}
+	return s.queuesKeptError(controller, nsq, ncq)
+}
+
+// waitQueuesReleased polls the queues of a controller every
+// RemovalPollInterval, up to RemovalTimeout, until the host releases them
+// and done succeeds. It returns the queues still active otherwise.
+func (s *Server) waitQueuesReleased(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController, done func() (bool, error)) (bool, int, int, error) {
+	deadline := time.Now().Add(s.opts.RemovalTimeout)
+	for {
+		nsq, ncq, err := s.activeQueues(ctx, subsys, controller)
+		if err != nil {
+			return false, 0, 0, err
+		}
+		if nsq == 0 && ncq == 0 {
+			released, err := done()
+			if err != nil || released {
+				return released, 0, 0, err
+			}
+		}
+		if !time.Now().Before(deadline) {
+			return false, nsq, ncq, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, 0, 0, status.FromContextError(ctx.Err()).Err()
+		case <-time.After(s.opts.RemovalPollInterval):
+		}
+	}
+}
+
+// queuesKeptError is the error of a controller whose queues the host did
+// not release in time
+func (s *Server) queuesKeptError(controller *pb.NvmeController, nsq int, ncq int) error {
+	return status.Errorf(codes.FailedPrecondition, "%s still has %d active submission and %d completion queues after %v",
+		controller.Name, nsq, ncq, s.opts.RemovalTimeout)
+}
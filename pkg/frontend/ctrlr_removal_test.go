@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+)
+
+func TestFrontEnd_ControllerRemoval(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	removed := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	refused := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`
+	queues := func(nsq, ncq int) string {
+		return fmt.Sprintf(`{"id":%%d,"error":{"code":0,"message":""},"result":{"status": 0, "active_nsq": %d, "active_ncq": %d}}`, nsq, ncq)
+	}
+	tests := map[string]struct {
+		timeout time.Duration
+		policy  string
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+		exist   bool
+	}{
+		"force without removal timeout": {
+			timeout: 0,
+			policy:  "",
+			spdk:    []string{removed},
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+		},
+		"graceful removal": {
+			timeout: time.Second,
+			policy:  "",
+			spdk:    []string{removed},
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+		},
+		"queues released by the host": {
+			timeout: time.Second,
+			policy:  "",
+			spdk:    []string{refused, queues(2, 2), queues(0, 0), removed},
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+		},
+		"queues kept by the host": {
+			timeout: 0,
+			policy:  "graceful",
+			spdk:    []string{refused, queues(2, 1)},
+			errCode: codes.FailedPrecondition,
+			errMsg:  fmt.Sprintf("%v still has 2 active submission and 1 completion queues after 0s", testControllerName),
+			exist:   true,
+		},
+		"escalated to force": {
+			timeout: 0,
+			policy:  "graceful-then-force",
+			spdk:    []string{refused, queues(2, 1), removed},
+			errCode: codes.OK,
+			errMsg:  "",
+			exist:   false,
+		},
+		"escalated force refused": {
+			timeout: 0,
+			policy:  "graceful-then-force",
+			spdk:    []string{refused, queues(2, 1), refused},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not delete CTRL: %v", testControllerName),
+			exist:   true,
+		},
+		"invalid policy": {
+			timeout: time.Second,
+			policy:  "later",
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "x-opi-removal-policy value (later) is not one of force, graceful or graceful-then-force",
+			exist:   true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+			s.opts.RemovalTimeout = tt.timeout
+			s.opts.RemovalPollInterval = time.Millisecond
+
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, &testControllerWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+
+			ctx := testEnv.ctx
+			if tt.policy != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, removalPolicyKey, tt.policy)
+			}
+			_, err := testEnv.client.DeleteNvmeController(ctx, &pb.DeleteNvmeControllerRequest{Name: testControllerName})
+
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+			found, err := s.store.Get(testControllerName, new(pb.NvmeController))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tt.exist {
+				t.Error("exists: expected", tt.exist, "received", found)
+			}
+		})
+	}
+}
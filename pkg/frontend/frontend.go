@@ -8,34 +8,215 @@ package frontend
 
 import (
 	"log"
+	"strings"
+	"sync"
+	"time"
 
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
 	"github.com/philippgille/gokv"
+	"go.opentelemetry.io/otel"
 
 	"github.com/opiproject/gospdk/spdk"
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+	bridgestore "github.com/opiproject/opi-marvell-bridge/pkg/store"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
 )
 
+var logger = logging.Logger("frontend")
+
 // Server contains frontend related OPI services
 type Server struct {
 	pb.UnimplementedFrontendNvmeServiceServer
-	ListHelper map[string]bool
-	Pagination map[string]int
-	store      gokv.Store
-	rpc        spdk.JSONRPC
+	longrunningpb.UnimplementedOperationsServer
+	mb.UnimplementedNvmeWatchServiceServer
+	mb.UnimplementedNvmeMetadataServiceServer
+	mb.UnimplementedNvmeCapacityServiceServer
+	mb.UnimplementedNvmeStateServiceServer
+	ListHelper      map[string]bool
+	Pagination      map[string]int
+	listMutex       sync.RWMutex
+	store           gokv.Store
+	rpc             spdk.JSONRPC
+	operations      map[string]*operation
+	opMutex         sync.Mutex
+	pcieMutex       sync.Mutex
+	ctrlrIDMutex    sync.Mutex
+	identifierMutex sync.Mutex
+	nsidMutex       sync.Mutex
+	quotaMutex      sync.Mutex
+	reservations    map[string]quotaReservation
+	queueMutex      sync.Mutex
+	queues          map[string]queueReservation
+	stopping        bool
+	watcher         *watcher
+	opts            Options
+}
+
+// Options holds the defaults of the Server passed to the Marvell SDK and
+// the optional behaviors
+type Options struct {
+	// MinCtrlrID and MaxCtrlrID bound the controller IDs of new subsystems
+	MinCtrlrID int
+	MaxCtrlrID int
+	// ShareNamespaces allows namespaces to be attached to several controllers
+	ShareNamespaces bool
+	// AsyncOperations allows clients to opt in to long-running operations
+	AsyncOperations bool
+	// Quotas limit the resources of the tenants, the tenants without
+	// quota get DefaultQuota
+	Quotas       map[string]Quota
+	DefaultQuota Quota
+	// PcieFunctions is the inventory of the PCIe functions of the
+	// controllers, empty allows any function and assigns none
+	PcieFunctions []PcieFunction
+	QueueBudget   QueueBudget
+	// OUI is the IEEE OUI of the NGUIDs and EUI-64s generated for the
+	// namespaces created without them
+	OUI [3]byte
+	// Volumes resolves the backing volumes of the namespaces, nil passes
+	// their VolumeNameRef to the SDK as is
+	Volumes VolumeResolver
+	// RemovalTimeout is how long the controller deletions wait for the host
+	// to release the queues, 0 removes the controllers by force right away
+	RemovalTimeout time.Duration
+	// RemovalPollInterval is the interval of the checks of the queues
+	RemovalPollInterval time.Duration
+}
+
+// DefaultOptions returns the options used by NewServer
+func DefaultOptions() Options {
+	return Options{
+		MinCtrlrID:          0, // bug in v21.01, should be 0 for now
+		MaxCtrlrID:          256,
+		ShareNamespaces:     true,
+		AsyncOperations:     true,
+		OUI:                 [3]byte{0x00, 0x50, 0x43}, // Marvell
+		RemovalPollInterval: time.Second,
+	}
 }
 
 // NewServer creates initialized instance of Nvme server
 func NewServer(jsonRPC spdk.JSONRPC, store gokv.Store) *Server {
+	return NewServerWithOptions(jsonRPC, store, DefaultOptions())
+}
+
+// NewServerWithOptions creates initialized instance of Nvme server with opts
+func NewServerWithOptions(jsonRPC spdk.JSONRPC, store gokv.Store, opts Options) *Server {
 	if jsonRPC == nil {
 		log.Panic("nil for JSONRPC is not allowed")
 	}
 	if store == nil {
 		log.Panic("nil for Store is not allowed")
 	}
-	return &Server{
-		ListHelper: make(map[string]bool),
-		Pagination: make(map[string]int),
-		store:      store,
-		rpc:        jsonRPC,
+	watcher := newWatcher()
+	s := &Server{
+		ListHelper:   make(map[string]bool),
+		Pagination:   make(map[string]int),
+		store:        &watchedStore{Store: store, watcher: watcher},
+		rpc:          newTracedJSONRPC(jsonRPC, otel.GetTracerProvider(), otel.GetMeterProvider()),
+		operations:   make(map[string]*operation),
+		reservations: make(map[string]quotaReservation),
+		queues:       make(map[string]queueReservation),
+		watcher:      watcher,
+		opts:         opts,
+	}
+	if err := watcher.persist(store); err != nil {
+		logger.Error("Could not continue the resource versions published before a restart", "error", err)
 	}
+	if err := s.restoreListed(store); err != nil {
+		logger.Error("Could not list the resources stored before a restart", "error", err)
+	}
+	if err := s.failInterruptedOperations(); err != nil {
+		logger.Error("Could not fail the operations interrupted by a restart", "error", err)
+	}
+	if err := s.backfillNsids(store); err != nil {
+		logger.Error("Could not backfill the NSIDs of the stored namespaces", "error", err)
+	}
+	return s
+}
+
+// restoreListed records the keys of the subsystems, controllers and
+// namespaces stored before a restart, found if the store lists its keys
+func (s *Server) restoreListed(store gokv.Store) error {
+	lister, ok := store.(bridgestore.KeyLister)
+	if !ok {
+		return nil
+	}
+	keys, err := lister.Keys("nvmeSubsystems/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		s.addListed(key)
+	}
+	return nil
+}
+
+// The operations run in the background write ListHelper and Pagination as
+// well, so that they are only accessed through the following helpers.
+
+// addListed records the key of a new resource listed by the List calls
+func (s *Server) addListed(key string) {
+	s.listMutex.Lock()
+	defer s.listMutex.Unlock()
+	s.ListHelper[key] = false
+}
+
+// deleteListed forgets the key of a deleted resource
+func (s *Server) deleteListed(key string) {
+	s.listMutex.Lock()
+	defer s.listMutex.Unlock()
+	delete(s.ListHelper, key)
+}
+
+// isListed checks if the key of a resource is recorded
+func (s *Server) isListed(key string) bool {
+	s.listMutex.RLock()
+	defer s.listMutex.RUnlock()
+	_, ok := s.ListHelper[key]
+	return ok
+}
+
+// listedKeys returns a snapshot of the keys of all recorded resources
+func (s *Server) listedKeys() []string {
+	s.listMutex.RLock()
+	defer s.listMutex.RUnlock()
+	keys := make([]string, 0, len(s.ListHelper))
+	for key := range s.ListHelper {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// storedKeys returns the keys of the store starting with prefix, the listed
+// ones if the store does not list its keys
+func (s *Server) storedKeys(prefix string) ([]string, error) {
+	if watched, ok := s.store.(*watchedStore); ok {
+		if lister, ok := watched.Store.(bridgestore.KeyLister); ok {
+			return lister.Keys(prefix)
+		}
+	}
+	keys := []string{}
+	for _, key := range s.listedKeys() {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// extractPagination returns the size and offset of the page of a List call
+func (s *Server) extractPagination(pageSize int32, pageToken string) (int, int, error) {
+	s.listMutex.RLock()
+	defer s.listMutex.RUnlock()
+	return utils.ExtractPagination(pageSize, pageToken, s.Pagination)
+}
+
+// setPageToken records the offset of the next page of a List call
+func (s *Server) setPageToken(token string, offset int) {
+	s.listMutex.Lock()
+	defer s.listMutex.Unlock()
+	s.Pagination[token] = offset
 }
@@ -12,21 +12,27 @@ import (
 	"net"
 	"os"
 
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/test/bufconn"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/wrapperspb"
 
-	"github.com/philippgille/gokv/gomap"
-
 	"github.com/opiproject/gospdk/spdk"
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	bridgestore "github.com/opiproject/opi-marvell-bridge/pkg/store"
 	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
 )
 
 type frontendClient struct {
 	pb.FrontendNvmeServiceClient
+	longrunningpb.OperationsClient
+	mb.NvmeWatchServiceClient
+	mb.NvmeMetadataServiceClient
+	mb.NvmeCapacityServiceClient
+	mb.NvmeStateServiceClient
 }
 
 type testEnv struct {
@@ -51,10 +57,7 @@ func createTestEnvironment(spdkResponses []string) *testEnv {
 	env := &testEnv{}
 	env.testSocket = utils.GenerateSocketName("frontend")
 	env.ln, env.jsonRPC = utils.CreateTestSpdkServer(env.testSocket, spdkResponses)
-	options := gomap.DefaultOptions
-	options.Codec = utils.ProtoCodec{}
-	store := gomap.NewStore(options)
-	env.opiSpdkServer = NewServer(env.jsonRPC, store)
+	env.opiSpdkServer = NewServer(env.jsonRPC, bridgestore.NewGomapStore(utils.ProtoCodec{}))
 
 	ctx := context.Background()
 	conn, err := grpc.DialContext(ctx,
@@ -69,6 +72,11 @@ func createTestEnvironment(spdkResponses []string) *testEnv {
 
 	env.client = &frontendClient{
 		pb.NewFrontendNvmeServiceClient(env.conn),
+		longrunningpb.NewOperationsClient(env.conn),
+		mb.NewNvmeWatchServiceClient(env.conn),
+		mb.NewNvmeMetadataServiceClient(env.conn),
+		mb.NewNvmeCapacityServiceClient(env.conn),
+		mb.NewNvmeStateServiceClient(env.conn),
 	}
 
 	return env
@@ -78,6 +86,11 @@ func dialer(opiSpdkServer *Server) func(context.Context, string) (net.Conn, erro
 	listener := bufconn.Listen(1024 * 1024)
 	server := grpc.NewServer()
 	pb.RegisterFrontendNvmeServiceServer(server, opiSpdkServer)
+	longrunningpb.RegisterOperationsServer(server, opiSpdkServer)
+	mb.RegisterNvmeWatchServiceServer(server, opiSpdkServer)
+	mb.RegisterNvmeMetadataServiceServer(server, opiSpdkServer)
+	mb.RegisterNvmeCapacityServiceServer(server, opiSpdkServer)
+	mb.RegisterNvmeStateServiceServer(server, opiSpdkServer)
 
 	go func() {
 		if err := server.Serve(listener); err != nil {
@@ -0,0 +1,189 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+var healthLogger = logging.Logger("health")
+
+const (
+	// HealthServiceSdk reports if the Marvell SDK answers JSON-RPC calls
+	HealthServiceSdk = "opi.marvell.sdk"
+	// HealthServiceStore reports if the key-value store answers
+	HealthServiceStore = "opi.marvell.store"
+	// HealthServiceReconciliation reports if all subsystems of the store
+	// exist in the Marvell SDK, the missing ones are replayed into it
+	HealthServiceReconciliation = "opi.marvell.reconciliation"
+
+	// healthProbeKey is read to probe the store, it is never written
+	healthProbeKey = "health/probe"
+)
+
+// HealthServices are the services checked by HealthChecker, the overall
+// status ("" service) is serving only if all of them are
+var HealthServices = []string{HealthServiceSdk, HealthServiceStore, HealthServiceReconciliation}
+
+// HealthChecker periodically probes the dependencies of the bridge and
+// reports them through the gRPC health service
+type HealthChecker struct {
+	server   *Server
+	health   *health.Server
+	interval time.Duration
+
+	mutex      sync.Mutex
+	subsystems map[string]string
+	failures   map[string]error
+}
+
+// NewHealthChecker creates a checker setting the statuses of hs every
+// interval, all services are not serving until the first check
+func NewHealthChecker(server *Server, hs *health.Server, interval time.Duration) *HealthChecker {
+	if server == nil {
+		log.Panic("nil for Server is not allowed")
+	}
+	if hs == nil {
+		log.Panic("nil for health Server is not allowed")
+	}
+	if interval <= 0 {
+		log.Panic("non-positive health check interval is not allowed")
+	}
+	c := &HealthChecker{
+		server:     server,
+		health:     hs,
+		interval:   interval,
+		subsystems: make(map[string]string),
+		failures:   make(map[string]error),
+	}
+	hs.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	for _, service := range HealthServices {
+		hs.SetServingStatus(service, grpc_health_v1.HealthCheckResponse_NOT_SERVING)
+	}
+	server.listenStored(c.observe)
+	return c
+}
+
+// observe keeps track of the subsystems to reconcile
+func (c *HealthChecker) observe(event *mb.NvmeResourceEvent) {
+	r, ok := event.Resource.(*mb.NvmeResourceEvent_NvmeSubsystem)
+	if !ok {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if event.Type == mb.NvmeResourceEvent_EVENT_TYPE_DELETED {
+		delete(c.subsystems, event.Name)
+	} else {
+		c.subsystems[event.Name] = r.NvmeSubsystem.GetSpec().GetNqn()
+	}
+}
+
+// Run checks the services every interval until ctx is done
+func (c *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *HealthChecker) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, c.interval)
+	defer cancel()
+
+	failures := map[string]error{}
+	_, err := c.server.store.Get(healthProbeKey, new(emptypb.Empty))
+	failures[HealthServiceStore] = err
+
+	var result models.MrvlNvmGetSubsysListResult
+	err = c.server.rpc.Call(ctx, "mrvl_nvm_get_subsys_list", nil, &result)
+	if err == nil && result.Status != 0 {
+		err = fmt.Errorf("could not list subsystems: status %d", result.Status)
+	}
+	failures[HealthServiceSdk] = err
+	if err != nil {
+		failures[HealthServiceReconciliation] = fmt.Errorf("unable to reconcile with unreachable SDK")
+	} else {
+		failures[HealthServiceReconciliation] = c.reconcile(ctx, &result)
+	}
+
+	overall := grpc_health_v1.HealthCheckResponse_SERVING
+	for _, service := range HealthServices {
+		status := grpc_health_v1.HealthCheckResponse_SERVING
+		if failures[service] != nil {
+			status = grpc_health_v1.HealthCheckResponse_NOT_SERVING
+			overall = status
+		}
+		c.health.SetServingStatus(service, status)
+	}
+	c.health.SetServingStatus("", overall)
+	c.logTransitions(ctx, failures)
+}
+
+// reconcile checks that the subsystems of the store exist in the SDK, and
+// replays the missing ones, e.g. after an SDK restart
+func (c *HealthChecker) reconcile(ctx context.Context, result *models.MrvlNvmGetSubsysListResult) error {
+	present := make(map[string]bool, len(result.SubsysList))
+	for _, subsys := range result.SubsysList {
+		present[subsys.Subnqn] = true
+	}
+	c.mutex.Lock()
+	missing := map[string]string{}
+	for name, nqn := range c.subsystems {
+		if !present[nqn] {
+			missing[name] = nqn
+		}
+	}
+	c.mutex.Unlock()
+	failed := []string{}
+	for name, nqn := range missing {
+		if err := c.server.replayNvmeSubsystem(ctx, name); err != nil {
+			healthLogger.ErrorContext(ctx, "Could not replay the subsystem missing in the SDK", "name", name, "error", err)
+			failed = append(failed, nqn)
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	sort.Strings(failed)
+	return fmt.Errorf("subsystems missing in the SDK: %s", strings.Join(failed, ", "))
+}
+
+// logTransitions logs the services changing their status
+func (c *HealthChecker) logTransitions(ctx context.Context, failures map[string]error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, service := range HealthServices {
+		previous, known := c.failures[service]
+		err := failures[service]
+		switch {
+		case err != nil && (!known || previous == nil || previous.Error() != err.Error()):
+			healthLogger.WarnContext(ctx, "Service is not serving", "service", service, "error", err)
+		case err == nil && (!known || previous != nil):
+			healthLogger.InfoContext(ctx, "Service is serving", "service", service)
+		}
+	}
+	c.failures = failures
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestFrontEnd_HealthChecker(t *testing.T) {
+	serving := grpc_health_v1.HealthCheckResponse_SERVING
+	notServing := grpc_health_v1.HealthCheckResponse_NOT_SERVING
+	tests := map[string]struct {
+		spdk      []string
+		resources bool
+		out       map[string]grpc_health_v1.HealthCheckResponse_ServingStatus
+	}{
+		"all serving": {
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[{"subnqn":"nqn.2022-09.io.spdk:opi3"}]}}`},
+			resources: true,
+			out: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":                          serving,
+				HealthServiceSdk:            serving,
+				HealthServiceStore:          serving,
+				HealthServiceReconciliation: serving,
+			},
+		},
+		"subsystem replayed into SDK": {
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[{"subnqn":"nqn.2022-09.io.spdk:opi4"}]}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0}}`,
+			},
+			resources: true,
+			out: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":                          serving,
+				HealthServiceSdk:            serving,
+				HealthServiceStore:          serving,
+				HealthServiceReconciliation: serving,
+			},
+		},
+		"subsystem missing in SDK": {
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[{"subnqn":"nqn.2022-09.io.spdk:opi4"}]}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":1}}`,
+			},
+			resources: true,
+			out: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":                          notServing,
+				HealthServiceSdk:            serving,
+				HealthServiceStore:          serving,
+				HealthServiceReconciliation: notServing,
+			},
+		},
+		"no resources": {
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[]}}`},
+			resources: false,
+			out: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":                          serving,
+				HealthServiceSdk:            serving,
+				HealthServiceStore:          serving,
+				HealthServiceReconciliation: serving,
+			},
+		},
+		"valid request with invalid SPDK response": {
+			spdk:      []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":1}}`},
+			resources: false,
+			out: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":                          notServing,
+				HealthServiceSdk:            notServing,
+				HealthServiceStore:          serving,
+				HealthServiceReconciliation: notServing,
+			},
+		},
+		"SDK call error": {
+			spdk:      []string{`{"id":%d,"error":{"code":1,"message":"myopierr"},"result":{"status":0}}`},
+			resources: false,
+			out: map[string]grpc_health_v1.HealthCheckResponse_ServingStatus{
+				"":                          notServing,
+				HealthServiceSdk:            notServing,
+				HealthServiceStore:          serving,
+				HealthServiceReconciliation: notServing,
+			},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+
+			hs := health.NewServer()
+			checker := NewHealthChecker(testEnv.opiSpdkServer, hs, time.Minute)
+			if tt.resources {
+				_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystem)
+			}
+			checker.check(testEnv.ctx)
+
+			for service, expected := range tt.out {
+				resp, err := hs.Check(testEnv.ctx, &grpc_health_v1.HealthCheckRequest{Service: service})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.Status != expected {
+					t.Error("service", service, "expected", expected, "received", resp.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestFrontEnd_HealthCheckerAfterRestart(t *testing.T) {
+	testEnv := createTestEnvironment([]string{
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[]}}`,
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status":1}}`,
+	})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	_ = s.store.Set(testSubsystemName, &testSubsystem)
+
+	// the next instance of the server shares the store, the SDK lost the
+	// subsystem and fails to create it again
+	store := keyListingStore{Store: s.store.(*watchedStore).Store, keys: []string{testSubsystemName}}
+	restarted := NewServer(testEnv.jsonRPC, store)
+	hs := health.NewServer()
+	checker := NewHealthChecker(restarted, hs, time.Minute)
+	checker.check(testEnv.ctx)
+
+	resp, err := hs.Check(testEnv.ctx, &grpc_health_v1.HealthCheckRequest{Service: HealthServiceReconciliation})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != grpc_health_v1.HealthCheckResponse_NOT_SERVING {
+		t.Error("expected", grpc_health_v1.HealthCheckResponse_NOT_SERVING, "received", resp.Status)
+	}
+}
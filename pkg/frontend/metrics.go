@@ -0,0 +1,362 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const metricsNamespace = "opi_marvell_bridge"
+
+var metricsLogger = logging.Logger("metrics")
+
+var (
+	controllerLabels = []string{"subsystem_nqn", "controller_id", "pf", "vf", "controller"}
+	namespaceLabels  = []string{"subsystem_nqn", "namespace_id", "volume", "namespace"}
+
+	controllerStatsDescs = []struct {
+		desc  *prometheus.Desc
+		value func(*models.MrvlNvmGetCtrlrStatsResult) int
+	}{
+		{newStatsDesc("nvme_controller", "read_commands_total", "Read commands processed by the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumReadCmds }},
+		{newStatsDesc("nvme_controller", "read_bytes_total", "Bytes read through the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumReadBytes }},
+		{newStatsDesc("nvme_controller", "write_commands_total", "Write commands processed by the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumWriteCmds }},
+		{newStatsDesc("nvme_controller", "write_bytes_total", "Bytes written through the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumWriteBytes }},
+		{newStatsDesc("nvme_controller", "errors_total", "IO errors of the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumErrors }},
+		{newStatsDesc("nvme_controller", "admin_commands_total", "Admin commands processed by the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumAdminCmds }},
+		{newStatsDesc("nvme_controller", "admin_command_errors_total", "Admin command errors of the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumAdminCmdErrors }},
+		{newStatsDesc("nvme_controller", "async_events_total", "Asynchronous events sent by the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.NumAsyncEvents }},
+		{newStatsDesc("nvme_controller", "read_latency_microseconds_total", "Total latency of read commands of the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.TotalReadLatencyInUs }},
+		{newStatsDesc("nvme_controller", "write_latency_microseconds_total", "Total latency of write commands of the controller", controllerLabels),
+			func(r *models.MrvlNvmGetCtrlrStatsResult) int { return r.TotalWriteLatencyInUs }},
+	}
+
+	namespaceStatsDescs = []struct {
+		desc  *prometheus.Desc
+		value func(*models.MrvlNvmGetNsStatsResult) int
+	}{
+		{newStatsDesc("nvme_namespace", "read_commands_total", "Read commands processed by the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.NumReadCmds }},
+		{newStatsDesc("nvme_namespace", "read_bytes_total", "Bytes read from the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.NumReadBytes }},
+		{newStatsDesc("nvme_namespace", "write_commands_total", "Write commands processed by the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.NumWriteCmds }},
+		{newStatsDesc("nvme_namespace", "write_bytes_total", "Bytes written to the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.NumWriteBytes }},
+		{newStatsDesc("nvme_namespace", "errors_total", "IO errors of the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.NumErrors }},
+		{newStatsDesc("nvme_namespace", "read_latency_microseconds_total", "Total latency of read commands of the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.TotalReadLatencyInUs }},
+		{newStatsDesc("nvme_namespace", "write_latency_microseconds_total", "Total latency of write commands of the namespace", namespaceLabels),
+			func(r *models.MrvlNvmGetNsStatsResult) int { return r.TotalWriteLatencyInUs }},
+	}
+
+	resourcesDesc  = newStatsDesc("", "nvme_resources", "Nvme resources known to the bridge", []string{"kind"})
+	operationsDesc = newStatsDesc("", "operations_in_progress", "Long-running operations not done yet", nil)
+	watchersDesc   = newStatsDesc("", "watchers", "Open WatchNvmeResources streams", nil)
+	pcieVfsDesc    = newStatsDesc("", "pcie_virtual_functions", "PCIe virtual functions of the inventory", []string{"port_id", "physical_function", "state"})
+)
+
+func newStatsDesc(subsystem, name, help string, labels []string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, subsystem, name), help, labels, nil)
+}
+
+// MetricsCollector periodically scrapes the stats of every known Nvme
+// controller and namespace and exports them as Prometheus metrics
+type MetricsCollector struct {
+	server   *Server
+	interval time.Duration
+
+	mutex           sync.Mutex
+	subsystems      map[string]*pb.NvmeSubsystem
+	controllers     map[string]*pb.NvmeController
+	namespaces      map[string]*pb.NvmeNamespace
+	controllerStats map[string]*models.MrvlNvmGetCtrlrStatsResult
+	namespaceStats  map[string]*models.MrvlNvmGetNsStatsResult
+
+	scrapeDuration prometheus.Histogram
+	scrapeErrors   *prometheus.CounterVec
+	lastScrape     prometheus.Gauge
+}
+
+// NewMetricsCollector creates a collector scraping the resources of server every interval
+func NewMetricsCollector(server *Server, interval time.Duration) *MetricsCollector {
+	if server == nil {
+		log.Panic("nil for Server is not allowed")
+	}
+	if interval <= 0 {
+		log.Panic("non-positive metrics interval is not allowed")
+	}
+	c := &MetricsCollector{
+		server:          server,
+		interval:        interval,
+		subsystems:      make(map[string]*pb.NvmeSubsystem),
+		controllers:     make(map[string]*pb.NvmeController),
+		namespaces:      make(map[string]*pb.NvmeNamespace),
+		controllerStats: make(map[string]*models.MrvlNvmGetCtrlrStatsResult),
+		namespaceStats:  make(map[string]*models.MrvlNvmGetNsStatsResult),
+		scrapeDuration: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "scrape_duration_seconds",
+			Help:      "Duration of scraping the stats of all Nvme resources",
+		}),
+		scrapeErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "scrape_errors_total",
+			Help:      "Failed stats calls to the Marvell SDK",
+		}, []string{"kind"}),
+		lastScrape: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: metricsNamespace,
+			Name:      "last_scrape_timestamp_seconds",
+			Help:      "Time of the last finished scrape",
+		}),
+	}
+	server.listenStored(c.observe)
+	return c
+}
+
+// observe keeps track of the resources to scrape
+func (c *MetricsCollector) observe(event *mb.NvmeResourceEvent) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	deleted := event.Type == mb.NvmeResourceEvent_EVENT_TYPE_DELETED
+	switch r := event.Resource.(type) {
+	case *mb.NvmeResourceEvent_NvmeSubsystem:
+		if deleted {
+			delete(c.subsystems, event.Name)
+		} else {
+			c.subsystems[event.Name] = r.NvmeSubsystem
+		}
+	case *mb.NvmeResourceEvent_NvmeController:
+		if deleted {
+			delete(c.controllers, event.Name)
+			delete(c.controllerStats, event.Name)
+		} else {
+			c.controllers[event.Name] = r.NvmeController
+		}
+	case *mb.NvmeResourceEvent_NvmeNamespace:
+		if deleted {
+			delete(c.namespaces, event.Name)
+			delete(c.namespaceStats, event.Name)
+		} else {
+			c.namespaces[event.Name] = r.NvmeNamespace
+		}
+	}
+}
+
+// Run scrapes the stats every interval until ctx is done
+func (c *MetricsCollector) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.scrape(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+type scrapeTarget struct {
+	name string
+	nqn  string
+	id   int
+}
+
+// targets returns the controllers and namespaces to scrape, sorted by name
+func (c *MetricsCollector) targets() ([]scrapeTarget, []scrapeTarget) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	controllers := []scrapeTarget{}
+	for name, controller := range c.controllers {
+		subsys, ok := c.subsystems[subsystemNameOf(name)]
+		if !ok || controller.GetSpec().NvmeControllerId == nil {
+			continue
+		}
+		controllers = append(controllers, scrapeTarget{name, subsys.GetSpec().GetNqn(), int(controller.Spec.GetNvmeControllerId())})
+	}
+	namespaces := []scrapeTarget{}
+	for name, namespace := range c.namespaces {
+		subsys, ok := c.subsystems[subsystemNameOf(name)]
+		if !ok {
+			continue
+		}
+		id, err := c.server.nsInstanceID(namespace)
+		if err != nil {
+			metricsLogger.Warn("Could not get the SDK ns_instance_id", "namespace", name, "error", err)
+			continue
+		}
+		namespaces = append(namespaces, scrapeTarget{name, subsys.GetSpec().GetNqn(), id})
+	}
+	sort.Slice(controllers, func(i, j int) bool { return controllers[i].name < controllers[j].name })
+	sort.Slice(namespaces, func(i, j int) bool { return namespaces[i].name < namespaces[j].name })
+	return controllers, namespaces
+}
+
+func (c *MetricsCollector) scrape(ctx context.Context) {
+	start := time.Now()
+	controllers, namespaces := c.targets()
+
+	controllerStats := make(map[string]*models.MrvlNvmGetCtrlrStatsResult)
+	for _, target := range controllers {
+		params := models.MrvlNvmGetCtrlrStatsParams{
+			Subnqn:  target.nqn,
+			CtrlrID: target.id,
+		}
+		var result models.MrvlNvmGetCtrlrStatsResult
+		err := c.server.rpc.Call(ctx, "mrvl_nvm_get_ctrlr_stats", &params, &result)
+		if err != nil || result.Status != 0 {
+			metricsLogger.WarnContext(ctx, "Could not scrape stats", "controller", target.name, "error", err, "status", result.Status)
+			c.scrapeErrors.WithLabelValues("controller").Inc()
+			continue
+		}
+		controllerStats[target.name] = &result
+	}
+
+	namespaceStats := make(map[string]*models.MrvlNvmGetNsStatsResult)
+	for _, target := range namespaces {
+		params := models.MrvlNvmGetNsStatsParams{
+			SubNqn:       target.nqn,
+			NsInstanceID: target.id,
+		}
+		var result models.MrvlNvmGetNsStatsResult
+		err := c.server.rpc.Call(ctx, "mrvl_nvm_get_ns_stats", &params, &result)
+		if err != nil || result.Status != 0 {
+			metricsLogger.WarnContext(ctx, "Could not scrape stats", "namespace", target.name, "error", err, "status", result.Status)
+			c.scrapeErrors.WithLabelValues("namespace").Inc()
+			continue
+		}
+		namespaceStats[target.name] = &result
+	}
+
+	c.mutex.Lock()
+	c.controllerStats = controllerStats
+	c.namespaceStats = namespaceStats
+	c.mutex.Unlock()
+
+	c.scrapeDuration.Observe(time.Since(start).Seconds())
+	c.lastScrape.SetToCurrentTime()
+}
+
+// Describe implements prometheus.Collector
+func (c *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range controllerStatsDescs {
+		ch <- d.desc
+	}
+	for _, d := range namespaceStatsDescs {
+		ch <- d.desc
+	}
+	ch <- resourcesDesc
+	ch <- operationsDesc
+	ch <- watchersDesc
+	ch <- pcieVfsDesc
+	c.scrapeDuration.Describe(ch)
+	c.scrapeErrors.Describe(ch)
+	c.lastScrape.Describe(ch)
+}
+
+// Collect implements prometheus.Collector, it reports the stats of the last scrape
+func (c *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	c.scrapeDuration.Collect(ch)
+	c.scrapeErrors.Collect(ch)
+	c.lastScrape.Collect(ch)
+
+	c.server.opMutex.Lock()
+	operations := len(c.server.operations)
+	c.server.opMutex.Unlock()
+	ch <- prometheus.MustNewConstMetric(operationsDesc, prometheus.GaugeValue, float64(operations))
+
+	c.server.watcher.mutex.Lock()
+	watchers := len(c.server.watcher.subscribers)
+	c.server.watcher.mutex.Unlock()
+	ch <- prometheus.MustNewConstMetric(watchersDesc, prometheus.GaugeValue, float64(watchers))
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(len(c.subsystems)), "subsystem")
+	ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(len(c.controllers)), "controller")
+	ch <- prometheus.MustNewConstMetric(resourcesDesc, prometheus.GaugeValue, float64(len(c.namespaces)), "namespace")
+
+	for _, f := range c.server.opts.PcieFunctions {
+		used := 0
+		for _, controller := range c.controllers {
+			e := pcieEndpointOf(controller.GetSpec().GetPcieId())
+			if e.port == f.PortID && e.pf == f.PhysicalFunction && e.vf >= 1 && e.vf <= f.VirtualFunctions {
+				used++
+			}
+		}
+		port, pf := strconv.Itoa(f.PortID), strconv.Itoa(f.PhysicalFunction)
+		ch <- prometheus.MustNewConstMetric(pcieVfsDesc, prometheus.GaugeValue, float64(used), port, pf, "used")
+		ch <- prometheus.MustNewConstMetric(pcieVfsDesc, prometheus.GaugeValue, float64(f.VirtualFunctions-used), port, pf, "free")
+	}
+
+	for name, stats := range c.controllerStats {
+		controller, ok := c.controllers[name]
+		if !ok {
+			continue
+		}
+		labels := []string{
+			c.subsystems[subsystemNameOf(name)].GetSpec().GetNqn(),
+			strconv.Itoa(int(controller.GetSpec().GetNvmeControllerId())),
+			optionalLabel(controller.GetSpec().GetPcieId().GetPhysicalFunction()),
+			optionalLabel(controller.GetSpec().GetPcieId().GetVirtualFunction()),
+			name,
+		}
+		for _, d := range controllerStatsDescs {
+			ch <- prometheus.MustNewConstMetric(d.desc, prometheus.CounterValue, float64(d.value(stats)), labels...)
+		}
+	}
+	for name, stats := range c.namespaceStats {
+		namespace, ok := c.namespaces[name]
+		if !ok {
+			continue
+		}
+		labels := []string{
+			c.subsystems[subsystemNameOf(name)].GetSpec().GetNqn(),
+			strconv.Itoa(int(namespace.GetSpec().GetHostNsid())),
+			namespace.GetSpec().GetVolumeNameRef(),
+			name,
+		}
+		for _, d := range namespaceStatsDescs {
+			ch <- prometheus.MustNewConstMetric(d.desc, prometheus.CounterValue, float64(d.value(stats)), labels...)
+		}
+	}
+}
+
+func subsystemNameOf(name string) string {
+	return utils.ResourceIDToSubsystemName(utils.GetSubsystemIDFromNvmeName(name))
+}
+
+func optionalLabel(value *wrapperspb.Int32Value) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(int(value.GetValue()))
+}
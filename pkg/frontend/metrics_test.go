@@ -0,0 +1,161 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestFrontEnd_MetricsCollector(t *testing.T) {
+	tests := map[string]struct {
+		spdk      []string
+		resources bool
+		inventory []PcieFunction
+		metrics   []string
+		out       string
+	}{
+		"valid stats": {
+			spdk: []string{
+				`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"num_admin_cmds":1,"num_admin_cmd_errors":2,"num_async_events":3,"num_read_cmds":4,"num_read_bytes":5,"num_write_cmds":6,"num_write_bytes":7,"num_errors":8,"total_read_latency_in_us":9,"total_write_latency_in_us":10,"Stats_time_window_in_us":11}}`,
+				`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"num_read_cmds":1,"num_read_bytes":2,"num_write_cmds":3,"num_write_bytes":4,"num_errors":5,"total_read_latency_in_us":6,"total_write_latency_in_us":7,"Stats_time_window_in_us":8}}`,
+			},
+			resources: true,
+			metrics: []string{
+				"opi_marvell_bridge_nvme_controller_admin_commands_total",
+				"opi_marvell_bridge_nvme_controller_read_commands_total",
+				"opi_marvell_bridge_nvme_namespace_write_bytes_total",
+				"opi_marvell_bridge_nvme_resources",
+			},
+			out: `
+# HELP opi_marvell_bridge_nvme_controller_admin_commands_total Admin commands processed by the controller
+# TYPE opi_marvell_bridge_nvme_controller_admin_commands_total counter
+opi_marvell_bridge_nvme_controller_admin_commands_total{controller="nvmeSubsystems/subsystem-test/nvmeControllers/controller-test",controller_id="17",pf="1",subsystem_nqn="nqn.2022-09.io.spdk:opi3",vf="2"} 1
+# HELP opi_marvell_bridge_nvme_controller_read_commands_total Read commands processed by the controller
+# TYPE opi_marvell_bridge_nvme_controller_read_commands_total counter
+opi_marvell_bridge_nvme_controller_read_commands_total{controller="nvmeSubsystems/subsystem-test/nvmeControllers/controller-test",controller_id="17",pf="1",subsystem_nqn="nqn.2022-09.io.spdk:opi3",vf="2"} 4
+# HELP opi_marvell_bridge_nvme_namespace_write_bytes_total Bytes written to the namespace
+# TYPE opi_marvell_bridge_nvme_namespace_write_bytes_total counter
+opi_marvell_bridge_nvme_namespace_write_bytes_total{namespace="nvmeSubsystems/subsystem-test/nvmeNamespaces/namespace-test",namespace_id="22",subsystem_nqn="nqn.2022-09.io.spdk:opi3",volume="Malloc0"} 4
+# HELP opi_marvell_bridge_nvme_resources Nvme resources known to the bridge
+# TYPE opi_marvell_bridge_nvme_resources gauge
+opi_marvell_bridge_nvme_resources{kind="controller"} 1
+opi_marvell_bridge_nvme_resources{kind="namespace"} 1
+opi_marvell_bridge_nvme_resources{kind="subsystem"} 1
+`,
+		},
+		"valid request with invalid SPDK response": {
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+			},
+			resources: true,
+			metrics: []string{
+				"opi_marvell_bridge_nvme_controller_read_commands_total",
+				"opi_marvell_bridge_scrape_errors_total",
+			},
+			out: `
+# HELP opi_marvell_bridge_scrape_errors_total Failed stats calls to the Marvell SDK
+# TYPE opi_marvell_bridge_scrape_errors_total counter
+opi_marvell_bridge_scrape_errors_total{kind="controller"} 1
+opi_marvell_bridge_scrape_errors_total{kind="namespace"} 1
+`,
+		},
+		"pcie virtual functions": {
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+			},
+			resources: true,
+			inventory: []PcieFunction{{PortID: 0, PhysicalFunction: 0, VirtualFunctions: 0}, {PortID: 0, PhysicalFunction: 1, VirtualFunctions: 4}},
+			metrics: []string{
+				"opi_marvell_bridge_pcie_virtual_functions",
+			},
+			out: `
+# HELP opi_marvell_bridge_pcie_virtual_functions PCIe virtual functions of the inventory
+# TYPE opi_marvell_bridge_pcie_virtual_functions gauge
+opi_marvell_bridge_pcie_virtual_functions{physical_function="0",port_id="0",state="free"} 0
+opi_marvell_bridge_pcie_virtual_functions{physical_function="0",port_id="0",state="used"} 0
+opi_marvell_bridge_pcie_virtual_functions{physical_function="1",port_id="0",state="free"} 3
+opi_marvell_bridge_pcie_virtual_functions{physical_function="1",port_id="0",state="used"} 1
+`,
+		},
+		"no resources": {
+			spdk:      []string{},
+			resources: false,
+			metrics: []string{
+				"opi_marvell_bridge_nvme_resources",
+				"opi_marvell_bridge_operations_in_progress",
+			},
+			out: `
+# HELP opi_marvell_bridge_nvme_resources Nvme resources known to the bridge
+# TYPE opi_marvell_bridge_nvme_resources gauge
+opi_marvell_bridge_nvme_resources{kind="controller"} 0
+opi_marvell_bridge_nvme_resources{kind="namespace"} 0
+opi_marvell_bridge_nvme_resources{kind="subsystem"} 0
+# HELP opi_marvell_bridge_operations_in_progress Long-running operations not done yet
+# TYPE opi_marvell_bridge_operations_in_progress gauge
+opi_marvell_bridge_operations_in_progress 0
+`,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+
+			testEnv.opiSpdkServer.opts.PcieFunctions = tt.inventory
+			collector := NewMetricsCollector(testEnv.opiSpdkServer, time.Minute)
+			if tt.resources {
+				_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystem)
+				_ = testEnv.opiSpdkServer.store.Set(testControllerName, &testController)
+				_ = testEnv.opiSpdkServer.store.Set(testNamespaceName, &testNamespace)
+			}
+			collector.scrape(testEnv.ctx)
+
+			err := testutil.CollectAndCompare(collector, strings.NewReader(tt.out), tt.metrics...)
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_MetricsCollectorAfterRestart(t *testing.T) {
+	testEnv := createTestEnvironment([]string{})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	_ = s.store.Set(testSubsystemName, &testSubsystem)
+	_ = s.store.Set(testControllerName, &testController)
+	_ = s.store.Set(testNamespaceName, &testNamespace)
+
+	// the next instance of the server shares the store
+	store := keyListingStore{
+		Store: s.store.(*watchedStore).Store,
+		keys:  []string{testSubsystemName, testControllerName, testNamespaceName},
+	}
+	restarted := NewServer(testEnv.jsonRPC, store)
+	collector := NewMetricsCollector(restarted, time.Minute)
+
+	out := `
+# HELP opi_marvell_bridge_nvme_resources Nvme resources known to the bridge
+# TYPE opi_marvell_bridge_nvme_resources gauge
+opi_marvell_bridge_nvme_resources{kind="controller"} 1
+opi_marvell_bridge_nvme_resources{kind="namespace"} 1
+opi_marvell_bridge_nvme_resources{kind="subsystem"} 1
+`
+	err := testutil.CollectAndCompare(collector, strings.NewReader(out), "opi_marvell_bridge_nvme_resources")
+	if err != nil {
+		t.Error(err)
+	}
+	if controllers, namespaces := collector.targets(); len(controllers) != 1 || len(namespaces) != 1 {
+		t.Errorf("expected the stored controller and namespace to be scraped, got %v and %v", controllers, namespaces)
+	}
+}
@@ -0,0 +1,244 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/philippgille/gokv"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	bridgestore "github.com/opiproject/opi-marvell-bridge/pkg/store"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// nsidsKey is the store key of the table of the host NSIDs of a subsystem
+// allocated to its namespaces
+func nsidsKey(subsysName string) string {
+	return "nsids/" + subsysName
+}
+
+// nsInstanceIDKey is the store key of the SDK ns_instance_id of a
+// namespace
+func nsInstanceIDKey(name string) string {
+	return "nsInstanceIDs/" + name
+}
+
+// subsystemNsids returns the namespaces allocated the host NSIDs of a
+// subsystem
+func (s *Server) subsystemNsids(subsysName string) (map[int32]string, error) {
+	table := new(structpb.Struct)
+	if _, err := s.store.Get(nsidsKey(subsysName), table); err != nil {
+		return nil, err
+	}
+	nsids := make(map[int32]string, len(table.Fields))
+	for k, v := range table.Fields {
+		nsid, err := strconv.ParseInt(k, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		nsids[int32(nsid)] = v.GetStringValue()
+	}
+	return nsids, nil
+}
+
+// setSubsystemNsids stores the table of the host NSIDs of a subsystem,
+// deleting it once empty
+func (s *Server) setSubsystemNsids(subsysName string, nsids map[int32]string) error {
+	if len(nsids) == 0 {
+		return s.store.Delete(nsidsKey(subsysName))
+	}
+	table := &structpb.Struct{Fields: make(map[string]*structpb.Value, len(nsids))}
+	for nsid, name := range nsids {
+		table.Fields[strconv.Itoa(int(nsid))] = structpb.NewStringValue(name)
+	}
+	return s.store.Set(nsidsKey(subsysName), table)
+}
+
+// nsidUser returns the namespace allocated a host NSID of a subsystem,
+// empty if it is free
+func (s *Server) nsidUser(subsysName string, nsid int32) (string, error) {
+	nsids, err := s.subsystemNsids(subsysName)
+	if err != nil {
+		return "", err
+	}
+	return nsids[nsid], nil
+}
+
+// subsystemMaxNsid returns the highest host NSID of a subsystem
+func (s *Server) subsystemMaxNsid(subsysName string) (int64, error) {
+	subsys := new(pb.NvmeSubsystem)
+	if _, err := s.store.Get(subsysName, subsys); err != nil {
+		return 0, err
+	}
+	if limit := subsys.GetSpec().GetMaxNamespaces(); limit > 0 {
+		return limit, nil
+	}
+	return maxNvmeNamespaces, nil
+}
+
+// allocateNsid allocates the host NSID requested for the namespace name of
+// a subsystem, or the lowest free one if 0, and returns it
+func (s *Server) allocateNsid(subsysName string, name string, requested int32) (int32, error) {
+	s.nsidMutex.Lock()
+	defer s.nsidMutex.Unlock()
+	nsids, err := s.subsystemNsids(subsysName)
+	if err != nil {
+		return 0, err
+	}
+	nsid := requested
+	if requested != 0 {
+		if user := nsids[requested]; user != "" && user != name {
+			return 0, status.Errorf(codes.AlreadyExists, "HostNsid value (%d) of %s is already used by %s", requested, subsysName, user)
+		}
+	} else {
+		limit, err := s.subsystemMaxNsid(subsysName)
+		if err != nil {
+			return 0, err
+		}
+		// one of the NSIDs up to one above the allocated ones is free
+		for candidate := int64(1); candidate <= limit && candidate <= int64(len(nsids))+1; candidate++ {
+			if user := nsids[int32(candidate)]; user == "" || user == name {
+				nsid = int32(candidate)
+				break
+			}
+		}
+		if nsid == 0 {
+			return 0, status.Errorf(codes.ResourceExhausted, "no free NSID in the range 1-%d of %s", limit, subsysName)
+		}
+	}
+	nsids[nsid] = name
+	if err := s.setSubsystemNsids(subsysName, nsids); err != nil {
+		return 0, err
+	}
+	return nsid, nil
+}
+
+// releaseNsid frees the host NSID of a subsystem allocated to the namespace
+// name, and forgets its SDK ns_instance_id
+func (s *Server) releaseNsid(subsysName string, name string, nsid int32) error {
+	s.nsidMutex.Lock()
+	defer s.nsidMutex.Unlock()
+	if err := s.store.Delete(nsInstanceIDKey(name)); err != nil {
+		return err
+	}
+	nsids, err := s.subsystemNsids(subsysName)
+	if err != nil {
+		return err
+	}
+	if nsids[nsid] != name {
+		return nil
+	}
+	delete(nsids, nsid)
+	return s.setSubsystemNsids(subsysName, nsids)
+}
+
+// backfillNsids allocates the host NSIDs of the namespaces stored before
+// the bridge allocated them, found if the store lists its keys
+func (s *Server) backfillNsids(store gokv.Store) error {
+	lister, ok := store.(bridgestore.KeyLister)
+	if !ok {
+		return nil
+	}
+	keys, err := lister.Keys("nvmeSubsystems/")
+	if err != nil {
+		return err
+	}
+	for _, key := range keys {
+		// nvmeSubsystems/<subsystem>/nvmeNamespaces/<namespace>
+		if segments := strings.Split(key, "/"); len(segments) != 4 || segments[2] != "nvmeNamespaces" {
+			continue
+		}
+		namespace := new(pb.NvmeNamespace)
+		found, err := s.store.Get(key, namespace)
+		if err != nil {
+			return err
+		}
+		if !found || namespace.GetSpec().GetHostNsid() == 0 {
+			continue
+		}
+		_, err = s.allocateNsid(subsystemNameOf(key), key, namespace.Spec.HostNsid)
+		if status.Code(err) == codes.AlreadyExists {
+			logger.Warn("Could not backfill the NSID", "name", key, "error", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseNsidOrLog frees the host NSID of a subsystem allocated to the
+// namespace name, only logging the failures
+func (s *Server) releaseNsidOrLog(ctx context.Context, subsysName string, name string, nsid int32) {
+	if err := s.releaseNsid(subsysName, name, nsid); err != nil {
+		logger.ErrorContext(ctx, "Could not release the NSID", "name", name, "error", err)
+	}
+}
+
+// setNsInstanceID records the SDK ns_instance_id of a namespace, when it
+// differs from its host NSID
+func (s *Server) setNsInstanceID(namespace *pb.NvmeNamespace, id int) error {
+	if id == 0 || id == int(namespace.GetSpec().GetHostNsid()) {
+		return s.store.Delete(nsInstanceIDKey(namespace.Name))
+	}
+	return s.store.Set(nsInstanceIDKey(namespace.Name), wrapperspb.Int32(int32(id)))
+}
+
+// nsInstanceID returns the SDK ns_instance_id of a namespace, its host
+// NSID unless recorded otherwise
+func (s *Server) nsInstanceID(namespace *pb.NvmeNamespace) (int, error) {
+	id := new(wrapperspb.Int32Value)
+	found, err := s.store.Get(nsInstanceIDKey(namespace.Name), id)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return int(namespace.GetSpec().GetHostNsid()), nil
+	}
+	return int(id.Value), nil
+}
+
+// subsystemHostNsids maps the SDK ns_instance_ids of the namespaces of the
+// subsystem parent to their host NSIDs, when they differ
+func (s *Server) subsystemHostNsids(parent string) (map[int]int32, error) {
+	nsids := make(map[int]int32)
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, parent+"/nvmeNamespaces/") {
+			continue
+		}
+		namespace := new(pb.NvmeNamespace)
+		found, err := s.store.Get(key, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		id, err := s.nsInstanceID(namespace)
+		if err != nil {
+			return nil, err
+		}
+		if id != int(namespace.GetSpec().GetHostNsid()) {
+			nsids[id] = namespace.GetSpec().GetHostNsid()
+		}
+	}
+	return nsids, nil
+}
+
+// hostNsid returns the host NSID of the SDK ns_instance_id id
+func hostNsid(nsids map[int]int32, id int) int32 {
+	if nsid, ok := nsids[id]; ok {
+		return nsid
+	}
+	return int32(id)
+}
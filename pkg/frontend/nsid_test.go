@@ -0,0 +1,314 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/philippgille/gokv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_Nsids(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	newNamespaceName := utils.ResourceIDToNamespaceName(testSubsystemID, "namespace-new")
+	namespaceSpec := func(nsid int32) *pb.NvmeNamespaceSpec {
+		return &pb.NvmeNamespaceSpec{
+			HostNsid:      nsid,
+			VolumeNameRef: "Malloc1",
+			Nguid:         "0x25f9cbc45d0f976fb9c1a14ff5aed4b0",
+			Uuid:          "1b4e28ba-2fa1-11d2-883f-b9a761bde3fb",
+			Eui64:         1967554867335598546,
+		}
+	}
+	createNamespace := func(nsid int32) func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+		return func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+			return c.CreateNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: &pb.NvmeNamespace{Spec: namespaceSpec(nsid)}, NvmeNamespaceId: "namespace-new"})
+		}
+	}
+	created := func(nsid int32) *pb.NvmeNamespace {
+		return &pb.NvmeNamespace{
+			Name: newNamespaceName,
+			Spec: namespaceSpec(nsid),
+			Status: &pb.NvmeNamespaceStatus{
+				State:     pb.NvmeNamespaceStatus_STATE_ENABLED,
+				OperState: pb.NvmeNamespaceStatus_OPER_STATE_ONLINE,
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		maxNamespaces int64
+		call          func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out           proto.Message
+		spdk          []string
+		errCode       codes.Code
+		errMsg        string
+		allocated     map[int32]string
+		nsInstanceID  int
+	}{
+		"lowest free NSID": {
+			maxNamespaces: 4,
+			call:          createNamespace(0),
+			out:           created(2),
+			spdk:          []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 2}}`},
+			errCode:       codes.OK,
+			errMsg:        "",
+			allocated:     map[int32]string{1: testNamespaceName, 2: newNamespaceName},
+			nsInstanceID:  2,
+		},
+		"chosen NSID": {
+			maxNamespaces: 4,
+			call:          createNamespace(4),
+			out:           created(4),
+			spdk:          []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 4}}`},
+			errCode:       codes.OK,
+			errMsg:        "",
+			allocated:     map[int32]string{1: testNamespaceName, 4: newNamespaceName},
+			nsInstanceID:  4,
+		},
+		"SDK ns_instance_id recorded": {
+			maxNamespaces: 4,
+			call:          createNamespace(3),
+			out:           created(3),
+			spdk:          []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 7}}`},
+			errCode:       codes.OK,
+			errMsg:        "",
+			allocated:     map[int32]string{1: testNamespaceName, 3: newNamespaceName},
+			nsInstanceID:  7,
+		},
+		"NSID already used": {
+			maxNamespaces: 4,
+			call:          createNamespace(1),
+			out:           nil,
+			spdk:          []string{},
+			errCode:       codes.AlreadyExists,
+			errMsg:        fmt.Sprintf("HostNsid value (1) of %v is already used by %v", testSubsystemName, testNamespaceName),
+			allocated:     map[int32]string{1: testNamespaceName},
+			nsInstanceID:  0,
+		},
+		"unlimited NSIDs": {
+			maxNamespaces: 0,
+			call:          createNamespace(0),
+			out:           created(2),
+			spdk:          []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 2}}`},
+			errCode:       codes.OK,
+			errMsg:        "",
+			allocated:     map[int32]string{1: testNamespaceName, 2: newNamespaceName},
+			nsInstanceID:  2,
+		},
+		"no free NSID": {
+			maxNamespaces: 1,
+			call:          createNamespace(0),
+			out:           nil,
+			spdk:          []string{},
+			errCode:       codes.ResourceExhausted,
+			errMsg:        fmt.Sprintf("no free NSID in the range 1-1 of %v", testSubsystemName),
+			allocated:     map[int32]string{1: testNamespaceName},
+			nsInstanceID:  0,
+		},
+		"release on SDK failure": {
+			maxNamespaces: 4,
+			call:          createNamespace(0),
+			out:           nil,
+			spdk:          []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`},
+			errCode:       codes.InvalidArgument,
+			errMsg:        fmt.Sprintf("Could not create NS: %v", newNamespaceName),
+			allocated:     map[int32]string{1: testNamespaceName},
+			nsInstanceID:  0,
+		},
+		"release on delete": {
+			maxNamespaces: 4,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.DeleteNvmeNamespace(ctx, &pb.DeleteNvmeNamespaceRequest{Name: testNamespaceName})
+			},
+			out:          nil,
+			spdk:         []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`},
+			errCode:      codes.OK,
+			errMsg:       "",
+			allocated:    map[int32]string{},
+			nsInstanceID: 0,
+		},
+		"list reports host NSIDs": {
+			maxNamespaces: 4,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeNamespaces(ctx, &pb.ListNvmeNamespacesRequest{Parent: testSubsystemName})
+			},
+			out: &pb.ListNvmeNamespacesResponse{
+				NvmeNamespaces: []*pb.NvmeNamespace{
+					{Spec: &pb.NvmeNamespaceSpec{HostNsid: 1}, Status: testNamespaceWithStatus.Status},
+					{Spec: &pb.NvmeNamespaceSpec{HostNsid: 5}},
+				},
+			},
+			spdk:         []string{`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"ns_list":[{"ns_instance_id":5,"bdev":"bdev01","ctrlr_id_list":[]},{"ns_instance_id":9,"bdev":"bdev02","ctrlr_id_list":[]}]}}`},
+			errCode:      codes.OK,
+			errMsg:       "",
+			allocated:    map[int32]string{1: testNamespaceName},
+			nsInstanceID: 0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			subsys := utils.ProtoClone(&testSubsystemWithStatus)
+			subsys.Spec.MaxNamespaces = tt.maxNamespaces
+			if err := s.store.Set(testSubsystemName, subsys); err != nil {
+				t.Fatal(err)
+			}
+			// the existing namespace of host NSID 1 is ns_instance_id 9 in the SDK
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Spec.HostNsid = 1
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testNamespaceName] = false
+			if _, err := s.allocateNsid(testSubsystemName, testNamespaceName, 1); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.setNsInstanceID(namespace, 9); err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := tt.call(testEnv.ctx, testEnv.client)
+
+			if tt.out != nil && !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+
+			allocated := map[int32]string{}
+			for nsid := int32(0); nsid <= 8; nsid++ {
+				user, err := s.nsidUser(testSubsystemName, nsid)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if user != "" {
+					allocated[nsid] = user
+				}
+			}
+			if !reflect.DeepEqual(allocated, tt.allocated) {
+				t.Error("NSIDs: expected", tt.allocated, "received", allocated)
+			}
+			if tt.nsInstanceID != 0 {
+				id, err := s.nsInstanceID(created(tt.out.(*pb.NvmeNamespace).Spec.HostNsid))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if id != tt.nsInstanceID {
+					t.Error("ns_instance_id: expected", tt.nsInstanceID, "received", id)
+				}
+			}
+		})
+	}
+}
+
+// keyListingStore lists the keys of the resources stored before a restart
+type keyListingStore struct {
+	gokv.Store
+	keys []string
+}
+
+func (s keyListingStore) Keys(prefix string) ([]string, error) {
+	var keys []string
+	for _, k := range s.keys {
+		if strings.HasPrefix(k, prefix) {
+			keys = append(keys, k)
+		}
+	}
+	return keys, nil
+}
+
+func TestFrontEnd_BackfillNsids(t *testing.T) {
+	otherNamespaceName := utils.ResourceIDToNamespaceName(testSubsystemID, "namespace-other")
+	tests := map[string]struct {
+		hostNsid  int32
+		listing   bool
+		allocated map[int32]string
+		want      map[int32]string
+	}{
+		"stored namespace backfilled": {
+			hostNsid:  3,
+			listing:   true,
+			allocated: map[int32]string{},
+			want:      map[int32]string{3: testNamespaceName},
+		},
+		"allocated NSID kept": {
+			hostNsid:  3,
+			listing:   true,
+			allocated: map[int32]string{3: otherNamespaceName},
+			want:      map[int32]string{3: otherNamespaceName},
+		},
+		"namespace without NSID skipped": {
+			hostNsid:  0,
+			listing:   true,
+			allocated: map[int32]string{},
+			want:      map[int32]string{},
+		},
+		"store without key listing": {
+			hostNsid:  3,
+			listing:   false,
+			allocated: map[int32]string{},
+			want:      map[int32]string{},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Spec.HostNsid = tt.hostNsid
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.setSubsystemNsids(testSubsystemName, tt.allocated); err != nil {
+				t.Fatal(err)
+			}
+
+			// the next instance of the server shares the store
+			var store gokv.Store = s.store.(*watchedStore).Store
+			if tt.listing {
+				store = keyListingStore{Store: store, keys: []string{testSubsystemName, testNamespaceName, nsidsKey(testSubsystemName)}}
+			}
+			restarted := NewServer(testEnv.jsonRPC, store)
+
+			nsids, err := restarted.subsystemNsids(testSubsystemName)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(nsids, tt.want) {
+				t.Error("NSIDs: expected", tt.want, "received", nsids)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// QueueBudget bounds the queue resources of the controllers of the device,
+// zero is unlimited. The budgets of the physical functions are set in the
+// PCIe functions inventory.
+type QueueBudget struct {
+	MaxNsq  int
+	MaxNcq  int
+	MaxMqes int
+}
+
+// physicalFunction identifies a PCIe physical function
+type physicalFunction struct {
+	port int
+	pf   int
+}
+
+// queueUsage counts the queues of the controllers of the device or of a
+// physical function
+type queueUsage struct {
+	nsq         int
+	ncq         int
+	controllers int
+}
+
+// queueReservation is the queues of a controller being created or resized,
+// counted in the queue budget until the controller is stored
+type queueReservation struct {
+	pf  physicalFunction
+	nsq int
+	ncq int
+}
+
+// queueBudgetEnabled checks if a queue budget is configured
+func (s *Server) queueBudgetEnabled() bool {
+	if s.opts.QueueBudget != (QueueBudget{}) {
+		return true
+	}
+	for _, f := range s.opts.PcieFunctions {
+		if f.MaxNsq != 0 || f.MaxNcq != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// queueUsageOf returns the queues used by the controllers of the device and
+// of each physical function, except the controller exclude. The queues
+// reserved replace the stored ones of their controller. The caller holds
+// queueMutex.
+func (s *Server) queueUsageOf(exclude string) (queueUsage, map[physicalFunction]queueUsage, error) {
+	device := queueUsage{}
+	pfs := make(map[physicalFunction]queueUsage)
+	count := func(pf physicalFunction, nsq int, ncq int) {
+		device.nsq += nsq
+		device.ncq += ncq
+		device.controllers++
+		usage := pfs[pf]
+		usage.nsq += nsq
+		usage.ncq += ncq
+		usage.controllers++
+		pfs[pf] = usage
+	}
+	for _, key := range s.listedKeys() {
+		if _, reserved := s.queues[key]; reserved || key == exclude || !strings.Contains(key, "/nvmeControllers/") {
+			continue
+		}
+		controller := new(pb.NvmeController)
+		found, err := s.store.Get(key, controller)
+		if err != nil {
+			return queueUsage{}, nil, err
+		}
+		if !found {
+			continue
+		}
+		e := pcieEndpointOf(controller.GetSpec().GetPcieId())
+		count(physicalFunction{port: e.port, pf: e.pf}, int(controller.GetSpec().GetMaxNsq()), int(controller.GetSpec().GetMaxNcq()))
+	}
+	for name, reservation := range s.queues {
+		if name != exclude {
+			count(reservation.pf, reservation.nsq, reservation.ncq)
+		}
+	}
+	return device, pfs, nil
+}
+
+// checkQueueBudget returns ResourceExhausted if the queues of the controller
+// name on the PCIe function endpoint would exceed the budget of the device
+// or of its physical function. The current queues of the controller are not
+// counted, so that the updates can resize them. Otherwise the queues stay
+// reserved until release is called, once the controller is stored or its
+// creation failed, so that concurrent calls do not exceed the budgets
+// together. Checking the controller again replaces its reservation.
+func (s *Server) checkQueueBudget(name string, endpoint *pb.PciEndpoint, spec *pb.NvmeControllerSpec) (release func(), err error) {
+	if !s.queueBudgetEnabled() {
+		return func() {}, nil
+	}
+	budget := s.opts.QueueBudget
+	if budget.MaxMqes > 0 && int(spec.GetSqes()) > budget.MaxMqes {
+		return nil, status.Errorf(codes.ResourceExhausted, "controller would exceed the maximum of %d queue entries with %d",
+			budget.MaxMqes, spec.GetSqes())
+	}
+	s.queueMutex.Lock()
+	defer s.queueMutex.Unlock()
+	device, pfs, err := s.queueUsageOf(name)
+	if err != nil {
+		return nil, err
+	}
+	nsq, ncq := int(spec.GetMaxNsq()), int(spec.GetMaxNcq())
+	err = checkQueueLimits("device",
+		queueLimit{"submission queues", budget.MaxNsq, device.nsq, nsq},
+		queueLimit{"completion queues", budget.MaxNcq, device.ncq, ncq},
+	)
+	if err != nil {
+		return nil, err
+	}
+	e := pcieEndpointOf(endpoint)
+	for _, f := range s.opts.PcieFunctions {
+		if f.PortID != e.port || f.PhysicalFunction != e.pf {
+			continue
+		}
+		usage := pfs[physicalFunction{port: e.port, pf: e.pf}]
+		err = checkQueueLimits(fmt.Sprintf("port %d pf %d", e.port, e.pf),
+			queueLimit{"submission queues", f.MaxNsq, usage.nsq, nsq},
+			queueLimit{"completion queues", f.MaxNcq, usage.ncq, ncq},
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+	s.queues[name] = queueReservation{pf: physicalFunction{port: e.port, pf: e.pf}, nsq: nsq, ncq: ncq}
+	return func() {
+		s.queueMutex.Lock()
+		defer s.queueMutex.Unlock()
+		delete(s.queues, name)
+	}, nil
+}
+
+// queueLimit is a queue budget with its usage and the queues to add
+type queueLimit struct {
+	resource string
+	max      int
+	used     int
+	add      int
+}
+
+// checkQueueLimits returns ResourceExhausted if adding the queues would
+// exceed one of the budgets of scope
+func checkQueueLimits(scope string, limits ...queueLimit) error {
+	for _, l := range limits {
+		if l.add > 0 && l.max > 0 && l.used+l.add > l.max {
+			return status.Errorf(codes.ResourceExhausted, "%s would exceed its budget of %d %s, %d are used",
+				scope, l.max, l.resource, l.used)
+		}
+	}
+	return nil
+}
+
+// queuesLeftToSdk checks if the controller spec leaves some of its queues to
+// the SDK defaults, so that they are only known once created
+func queuesLeftToSdk(spec *pb.NvmeControllerSpec) bool {
+	return spec.GetMaxNsq() == 0 || spec.GetMaxNcq() == 0
+}
+
+// discoverQueues fills the queues of the controller spec left to the SDK
+// defaults with the ones granted by the SDK, so that they are counted in
+// the queue budget
+func (s *Server) discoverQueues(ctx context.Context, subnqn string, spec *pb.NvmeControllerSpec) {
+	if !s.queueBudgetEnabled() || !queuesLeftToSdk(spec) {
+		return
+	}
+	params := models.MrvlNvmGetCtrlrInfoParams{
+		Subnqn:  subnqn,
+		CtrlrID: int(spec.GetNvmeControllerId()),
+	}
+	var result models.MrvlNvmGetCtrlrInfoResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_ctrlr_get_info", &params, &result)
+	if err == nil && result.Status != 0 {
+		err = status.Errorf(codes.InvalidArgument, "Could not get CTRL %d of %s", params.CtrlrID, subnqn)
+	}
+	if err != nil {
+		logger.WarnContext(ctx, "Could not discover the queues of the controller, they are not counted in the queue budget", "error", err)
+		return
+	}
+	if spec.MaxNsq == 0 {
+		spec.MaxNsq = int32(result.MaxNsq)
+	}
+	if spec.MaxNcq == 0 {
+		spec.MaxNcq = int32(result.MaxNcq)
+	}
+}
+
+// GetNvmeQueueCapacity gets the queue budget and usage of the device and of
+// the physical functions of the inventory
+func (s *Server) GetNvmeQueueCapacity(_ context.Context, _ *mb.GetNvmeQueueCapacityRequest) (*mb.NvmeQueueCapacity, error) {
+	s.queueMutex.Lock()
+	device, pfs, err := s.queueUsageOf("")
+	s.queueMutex.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	capacity := &mb.NvmeQueueCapacity{
+		Device: &mb.NvmeQueueUsage{
+			MaxNsq:      int32(s.opts.QueueBudget.MaxNsq),
+			MaxNcq:      int32(s.opts.QueueBudget.MaxNcq),
+			UsedNsq:     int32(device.nsq),
+			UsedNcq:     int32(device.ncq),
+			Controllers: int32(device.controllers),
+		},
+		MaxMqes: int32(s.opts.QueueBudget.MaxMqes),
+	}
+	for _, f := range s.opts.PcieFunctions {
+		usage := pfs[physicalFunction{port: f.PortID, pf: f.PhysicalFunction}]
+		capacity.PhysicalFunctions = append(capacity.PhysicalFunctions, &mb.NvmeQueueUsage{
+			PortId:           int32(f.PortID),
+			PhysicalFunction: int32(f.PhysicalFunction),
+			MaxNsq:           int32(f.MaxNsq),
+			MaxNcq:           int32(f.MaxNcq),
+			UsedNsq:          int32(usage.nsq),
+			UsedNcq:          int32(usage.ncq),
+			Controllers:      int32(usage.controllers),
+		})
+	}
+	return capacity, nil
+}
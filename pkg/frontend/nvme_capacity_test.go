@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_QueueBudget(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	newControllerName := utils.ResourceIDToControllerName(testSubsystemID, "controller-new")
+	controllerSpec := func(pf int32, nsq int32, ncq int32, sqes int32) *pb.NvmeControllerSpec {
+		return &pb.NvmeControllerSpec{
+			Endpoint:         &pb.NvmeControllerSpec_PcieId{PcieId: pcieEndpoint{port: 0, pf: int(pf), vf: 1}.proto()},
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: proto.Int32(18),
+			MaxNsq:           nsq,
+			MaxNcq:           ncq,
+			Sqes:             sqes,
+		}
+	}
+	createController := func(spec *pb.NvmeControllerSpec) func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+		return func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+			return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: &pb.NvmeController{Spec: spec}, NvmeControllerId: "controller-new"})
+		}
+	}
+	// the existing controller uses 4 submission and 4 completion queues of pf 1
+	existingController := &pb.NvmeController{
+		Name: testControllerName,
+		Spec: &pb.NvmeControllerSpec{
+			Endpoint:         testController.Spec.Endpoint,
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: proto.Int32(17),
+			MaxNsq:           4,
+			MaxNcq:           4,
+		},
+		Status: &pb.NvmeControllerStatus{Active: true},
+	}
+	inventory := []PcieFunction{
+		{PortID: 0, PhysicalFunction: 0, VirtualFunctions: 4},
+		{PortID: 0, PhysicalFunction: 1, VirtualFunctions: 4, MaxNsq: 8, MaxNcq: 6},
+	}
+
+	tests := map[string]struct {
+		budget  QueueBudget
+		call    func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out     proto.Message
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+	}{
+		"within budget": {
+			budget: QueueBudget{MaxNsq: 8, MaxNcq: 8},
+			call:   createController(controllerSpec(1, 4, 2, 0)),
+			out: &pb.NvmeController{
+				Name:   newControllerName,
+				Spec:   controllerSpec(1, 4, 2, 0),
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"over device submission queues": {
+			budget:  QueueBudget{MaxNsq: 6},
+			call:    createController(controllerSpec(0, 4, 2, 0)),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "device would exceed its budget of 6 submission queues, 4 are used",
+		},
+		"over pf completion queues": {
+			budget:  QueueBudget{},
+			call:    createController(controllerSpec(1, 2, 4, 0)),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "port 0 pf 1 would exceed its budget of 6 completion queues, 4 are used",
+		},
+		"other pf within its budget": {
+			budget: QueueBudget{},
+			call:   createController(controllerSpec(0, 16, 16, 0)),
+			out: &pb.NvmeController{
+				Name:   newControllerName,
+				Spec:   controllerSpec(0, 16, 16, 0),
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"over queue entries": {
+			budget:  QueueBudget{MaxMqes: 1024},
+			call:    createController(controllerSpec(0, 1, 1, 2048)),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "controller would exceed the maximum of 1024 queue entries with 2048",
+		},
+		"queues discovered from the SDK": {
+			budget: QueueBudget{MaxNsq: 16},
+			call:   createController(controllerSpec(0, 0, 0, 0)),
+			out: &pb.NvmeController{
+				Name:   newControllerName,
+				Spec:   controllerSpec(0, 2, 3, 0),
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`,
+				`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"pcie_domain_id":0,"pf_id":0,"vf_id":1,"ctrlr_id":18,"max_nsq":2,"max_ncq":3,"mqes":2048}}`,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"discovered queues over budget": {
+			budget: QueueBudget{MaxNsq: 6},
+			call:   createController(controllerSpec(0, 0, 0, 0)),
+			out:    nil,
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`,
+				`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"pcie_domain_id":0,"pf_id":0,"vf_id":1,"ctrlr_id":18,"max_nsq":4,"max_ncq":3,"mqes":2048}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0}}`,
+			},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "device would exceed its budget of 6 submission queues, 4 are used",
+		},
+		"resize with discovered queues over budget": {
+			budget: QueueBudget{},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				controller := utils.ProtoClone(existingController)
+				controller.Spec.MaxNsq = 0
+				return c.UpdateNvmeController(ctx, &pb.UpdateNvmeControllerRequest{NvmeController: controller})
+			},
+			out: nil,
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":17}}`,
+				`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"pcie_domain_id":0,"pf_id":1,"vf_id":2,"ctrlr_id":17,"max_nsq":16,"max_ncq":4,"mqes":2048}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":17}}`,
+			},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "port 0 pf 1 would exceed its budget of 8 submission queues, 0 are used",
+		},
+		"resize without counting the current queues": {
+			budget: QueueBudget{},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				controller := utils.ProtoClone(existingController)
+				controller.Spec.MaxNsq = 8
+				return c.UpdateNvmeController(ctx, &pb.UpdateNvmeControllerRequest{NvmeController: controller})
+			},
+			out: &pb.NvmeController{
+				Name: testControllerName,
+				Spec: &pb.NvmeControllerSpec{
+					Endpoint:         testController.Spec.Endpoint,
+					Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+					NvmeControllerId: proto.Int32(17),
+					MaxNsq:           8,
+					MaxNcq:           4,
+				},
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":17}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"capacity report": {
+			budget: QueueBudget{MaxNsq: 64, MaxNcq: 32, MaxMqes: 1024},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetNvmeQueueCapacity(ctx, &mb.GetNvmeQueueCapacityRequest{})
+			},
+			out: &mb.NvmeQueueCapacity{
+				Device: &mb.NvmeQueueUsage{MaxNsq: 64, MaxNcq: 32, UsedNsq: 4, UsedNcq: 4, Controllers: 1},
+				PhysicalFunctions: []*mb.NvmeQueueUsage{
+					{PortId: 0, PhysicalFunction: 0},
+					{PortId: 0, PhysicalFunction: 1, MaxNsq: 8, MaxNcq: 6, UsedNsq: 4, UsedNcq: 4, Controllers: 1},
+				},
+				MaxMqes: 1024,
+			},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+			s.opts.PcieFunctions = inventory
+			s.opts.QueueBudget = tt.budget
+
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, existingController); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+
+			response, err := tt.call(testEnv.ctx, testEnv.client)
+
+			if tt.out == nil {
+				if err == nil {
+					t.Error("response: expected error, received", response)
+				}
+			} else if !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Error("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_QueueBudgetConcurrentCreates(t *testing.T) {
+	testEnv := createTestEnvironment([]string{
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`,
+	})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	s.opts.QueueBudget = QueueBudget{MaxNsq: 6}
+	if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+		t.Fatal(err)
+	}
+	s.ListHelper[testSubsystemName] = false
+
+	// only one of the controllers fits in the budget, the other one is never
+	// passed to the SDK
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = testEnv.client.CreateNvmeController(testEnv.ctx, &pb.CreateNvmeControllerRequest{
+				Parent: testSubsystemName,
+				NvmeController: &pb.NvmeController{Spec: &pb.NvmeControllerSpec{
+					Endpoint: &pb.NvmeControllerSpec_PcieId{PcieId: pcieEndpoint{port: 0, pf: 0, vf: i + 1}.proto()},
+					Trtype:   pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+					MaxNsq:   4,
+					MaxNcq:   4,
+				}},
+				NvmeControllerId: fmt.Sprintf("controller-new-%d", i),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	exhausted := 0
+	for _, err := range errs {
+		if status.Code(err) == codes.ResourceExhausted {
+			exhausted++
+		} else if err != nil {
+			t.Error("expected no error, received", err)
+		}
+	}
+	if exhausted != 1 {
+		t.Error("controllers over budget: expected 1, received", exhausted)
+	}
+}
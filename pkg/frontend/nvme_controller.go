@@ -9,11 +9,13 @@ package frontend
 import (
 	"context"
 	"fmt"
-	"log"
 	"path"
 	"sort"
+	"strings"
 
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
 	"github.com/opiproject/opi-marvell-bridge/pkg/models"
 	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
 
@@ -27,14 +29,37 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
-const autoCtrlrIDAllocation = -1
-
 func sortNvmeControllers(controllers []*pb.NvmeController) {
 	sort.Slice(controllers, func(i int, j int) bool {
 		return *controllers[i].Spec.NvmeControllerId < *controllers[j].Spec.NvmeControllerId
 	})
 }
 
+// subsystemControllers fetches all controllers created in the given subsystem
+func (s *Server) subsystemControllers(subsys *pb.NvmeSubsystem) ([]*pb.NvmeController, error) {
+	controllers := []*pb.NvmeController{}
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, subsys.Name+"/nvmeControllers") {
+			continue
+		}
+		c := new(pb.NvmeController)
+		ok, err := s.store.Get(key, c)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			err := status.Errorf(codes.NotFound, "unable to find key %s", key)
+			return nil, err
+		}
+		if utils.GetSubsystemIDFromNvmeName(c.Name) != utils.GetSubsystemIDFromNvmeName(subsys.Name) {
+			continue
+		}
+		controllers = append(controllers, c)
+	}
+	sortNvmeControllers(controllers)
+	return controllers, nil
+}
+
 // CreateNvmeController creates an Nvme controller
 func (s *Server) CreateNvmeController(ctx context.Context, in *pb.CreateNvmeControllerRequest) (*pb.NvmeController, error) {
 	// check input correctness
@@ -44,12 +69,16 @@ func (s *Server) CreateNvmeController(ctx context.Context, in *pb.CreateNvmeCont
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.NvmeControllerId != "" {
-		log.Printf("client provided the ID of a resource %v, ignoring the name field %v", in.NvmeControllerId, in.NvmeController.Name)
+		logger.InfoContext(ctx, "client provided the ID of a resource, ignoring the name field", "id", in.NvmeControllerId, "name", in.NvmeController.Name)
 		resourceID = in.NvmeControllerId
 	}
 	in.NvmeController.Name = utils.ResourceIDToControllerName(
 		utils.GetSubsystemIDFromNvmeName(in.Parent), resourceID,
 	)
+	audit.SetResource(ctx, in.NvmeController.Name)
+	if err := s.checkOwnedByCaller(ctx, in.Parent); err != nil {
+		return nil, err
+	}
 	// idempotent API when called with same key, should return same object
 	controller := new(pb.NvmeController)
 	found, err := s.store.Get(in.NvmeController.Name, controller)
@@ -57,7 +86,7 @@ func (s *Server) CreateNvmeController(ctx context.Context, in *pb.CreateNvmeCont
 		return nil, err
 	}
 	if found {
-		log.Printf("Already existing NvmeController with id %v", in.NvmeController.Name)
+		logger.InfoContext(ctx, "Already existing NvmeController", "name", in.NvmeController.Name)
 		return controller, nil
 	}
 	// not found, so create a new one
@@ -70,16 +99,57 @@ func (s *Server) CreateNvmeController(ctx context.Context, in *pb.CreateNvmeCont
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Parent)
 		return nil, err
 	}
+	owner, err := s.subsystemOwner(in.Parent)
+	if err != nil {
+		return nil, err
+	}
+	add := tenantUsage{
+		submissionQueues: int(in.GetNvmeController().GetSpec().GetMaxNsq()),
+		completionQueues: int(in.GetNvmeController().GetSpec().GetMaxNcq()),
+	}
+	if in.GetNvmeController().GetSpec().GetPcieId().GetVirtualFunction().GetValue() > 0 ||
+		s.pcieEndpointAssignable(in.GetNvmeController().GetSpec()) {
+		// an assigned PCIe function is always a VF
+		add.vfControllers = 1
+	}
+	release, err := s.checkQuota(in.NvmeController.Name, owner, add)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	meta, err := requestMetadata(ctx, in.NvmeController.Name)
+	if err != nil {
+		return nil, err
+	}
+	return s.createNvmeController(ctx, in, subsys, meta)
+}
 
-	ctrlrID := autoCtrlrIDAllocation
-	if in.NvmeController.Spec.NvmeControllerId != nil {
-		ctrlrID = int(*in.NvmeController.Spec.NvmeControllerId)
+func (s *Server) createNvmeController(ctx context.Context, in *pb.CreateNvmeControllerRequest, subsys *pb.NvmeSubsystem, meta *mb.NvmeResourceMetadata) (*pb.NvmeController, error) {
+	ctrlrID, err := s.allocateCtrlrID(in.Parent, in.NvmeController.Name, in.NvmeController.Spec.NvmeControllerId)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err := s.reservePcieFunction(in.NvmeController.Name, in.GetNvmeController().GetSpec().GetPcieId())
+	if err != nil {
+		s.releaseCtrlrIDOrLog(ctx, in.Parent, in.NvmeController.Name, ctrlrID)
+		return nil, err
 	}
+	release := func() {
+		s.releasePcieFunctionOrLog(ctx, in.NvmeController.Name, endpoint)
+		s.releaseCtrlrIDOrLog(ctx, in.Parent, in.NvmeController.Name, ctrlrID)
+	}
+	releaseQueues, err := s.checkQueueBudget(in.NvmeController.Name, endpoint, in.NvmeController.Spec)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	defer releaseQueues()
+
 	params := models.MrvlNvmSubsysCreateCtrlrParams{
 		Subnqn:       subsys.Spec.Nqn,
-		PcieDomainID: int(in.GetNvmeController().GetSpec().GetPcieId().GetPortId().GetValue()),
-		PfID:         int(in.GetNvmeController().GetSpec().GetPcieId().GetPhysicalFunction().GetValue()),
-		VfID:         int(in.GetNvmeController().GetSpec().GetPcieId().GetVirtualFunction().GetValue()),
+		PcieDomainID: int(endpoint.GetPortId().GetValue()),
+		PfID:         int(endpoint.GetPhysicalFunction().GetValue()),
+		VfID:         int(endpoint.GetVirtualFunction().GetValue()),
 		CtrlrID:      ctrlrID,
 		MaxNsq:       int(in.GetNvmeController().GetSpec().GetMaxNsq()),
 		MaxNcq:       int(in.GetNvmeController().GetSpec().GetMaxNcq()),
@@ -87,32 +157,125 @@ func (s *Server) CreateNvmeController(ctx context.Context, in *pb.CreateNvmeCont
 	}
 	var result models.MrvlNvmSubsysCreateCtrlrResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_subsys_create_ctrlr", &params, &result)
+	if err == nil && result.Status != 0 {
+		msg := fmt.Sprintf("Could not create CTRL: %s", in.NvmeController.Name)
+		err = status.Errorf(codes.InvalidArgument, msg)
+	}
 	if err != nil {
+		release()
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
-	if result.Status != 0 {
-		msg := fmt.Sprintf("Could not create CTRL: %s", in.NvmeController.Name)
-		return nil, status.Errorf(codes.InvalidArgument, msg)
-	}
 	response := utils.ProtoClone(in.NvmeController)
-	response.Spec.NvmeControllerId = proto.Int32(int32(result.CtrlrID))
+	response.Spec.Endpoint = &pb.NvmeControllerSpec_PcieId{PcieId: endpoint}
+	response.Spec.NvmeControllerId = proto.Int32(int32(ctrlrID))
+	s.discoverQueues(ctx, subsys.Spec.Nqn, response.Spec)
 	response.Status = &pb.NvmeControllerStatus{Active: true}
+	// the SDK controller is created, remove it as well on failure
+	var attachments []attachment
+	rollback := func() {
+		s.undoAttached(ctx, subsys, attachments, true)
+		s.rollbackNvmeControllerCreate(ctx, subsys, response)
+		release()
+	}
+	if queuesLeftToSdk(in.NvmeController.Spec) {
+		// the budget is checked again with the queues granted by the SDK
+		if _, err := s.checkQueueBudget(in.NvmeController.Name, endpoint, response.Spec); err != nil {
+			rollback()
+			return nil, err
+		}
+	}
+	// the enabled namespaces of the subsystem are attached to the new
+	// controller, as they are to the active ones
+	enabled, err := s.enabledNamespaceAttachments(subsys, response)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	if err := s.setAttached(ctx, subsys, enabled, true); err != nil {
+		rollback()
+		return nil, err
+	}
+	attachments = enabled
 	// save object to the database
-	s.ListHelper[in.NvmeController.Name] = false
+	err = s.setResourceMetadata(meta)
+	if err != nil {
+		rollback()
+		return nil, err
+	}
+	s.addListed(in.NvmeController.Name)
 	err = s.store.Set(in.NvmeController.Name, response)
 	if err != nil {
+		s.deleteListed(in.NvmeController.Name)
+		if err := s.store.Delete(metadataKey(in.NvmeController.Name)); err != nil {
+			logger.ErrorContext(ctx, "Could not delete CTRL metadata on rollback", "name", in.NvmeController.Name, "error", err)
+		}
+		rollback()
 		return nil, err
 	}
 	return response, nil
 }
 
+// rollbackNvmeControllerCreate removes by force the SDK controller of a
+// failed controller creation, so that none unknown to the store is left
+func (s *Server) rollbackNvmeControllerCreate(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	logger.WarnContext(ctx, "Rolling back failed NvmeController creation", "name", controller.Name)
+	if _, err := s.removeCtrlr(ctx, subsys, controller, true); err != nil {
+		logger.ErrorContext(ctx, "Could not delete CTRL on rollback", "name", controller.Name, "error", err)
+	}
+}
+
+// storedCtrlrParams returns the parameters creating or updating the SDK
+// controller of a stored controller, with its ID and PCIe function
+func storedCtrlrParams(subsys *pb.NvmeSubsystem, controller *pb.NvmeController) models.MrvlNvmSubsysCreateCtrlrParams {
+	endpoint := controller.GetSpec().GetPcieId()
+	return models.MrvlNvmSubsysCreateCtrlrParams{
+		Subnqn:       subsys.Spec.Nqn,
+		PcieDomainID: int(endpoint.GetPortId().GetValue()),
+		PfID:         int(endpoint.GetPhysicalFunction().GetValue()),
+		VfID:         int(endpoint.GetVirtualFunction().GetValue()),
+		CtrlrID:      int(controller.GetSpec().GetNvmeControllerId()),
+		MaxNsq:       int(controller.GetSpec().GetMaxNsq()),
+		MaxNcq:       int(controller.GetSpec().GetMaxNcq()),
+		Mqes:         int(controller.GetSpec().GetSqes()),
+	}
+}
+
+// rollbackNvmeControllerUpdate updates the SDK controller back to the
+// stored controller, after a failed controller update
+func (s *Server) rollbackNvmeControllerUpdate(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	logger.WarnContext(ctx, "Rolling back failed NvmeController update", "name", controller.Name)
+	params := storedCtrlrParams(subsys, controller)
+	var result models.MrvlNvmSubsysCreateCtrlrResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_subsys_update_ctrlr", &params, &result)
+	if err == nil && result.Status != 0 {
+		err = status.Errorf(codes.InvalidArgument, "Could not update CTRL: %s", controller.Name)
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Could not update CTRL back on rollback", "name", controller.Name, "error", err)
+	}
+}
+
 // DeleteNvmeController deletes an Nvme controller
 func (s *Server) DeleteNvmeController(ctx context.Context, in *pb.DeleteNvmeControllerRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteNvmeControllerRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	policy, err := s.requestRemovalPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.deleteNvmeController(ctx, in, policy)
+}
+
+func (s *Server) deleteNvmeController(ctx context.Context, in *pb.DeleteNvmeControllerRequest, policy removalPolicy) (*emptypb.Empty, error) {
 	// fetch object from the database
 	controller := new(pb.NvmeController)
 	found, err := s.store.Get(in.Name, controller)
@@ -138,28 +301,28 @@ func (s *Server) DeleteNvmeController(ctx context.Context, in *pb.DeleteNvmeCont
 		err := status.Errorf(codes.NotFound, "unable to find key %s", subsysName)
 		return nil, err
 	}
-	// construct command with parameters
-	params := models.MrvlNvmSubsysRemoveCtrlrParams{
-		Subnqn:  subsys.Spec.Nqn,
-		CtrlrID: int(*controller.Spec.NvmeControllerId),
-		Force:   1,
-	}
-	var result models.MrvlNvmSubsysRemoveCtrlrResult
-	err = s.rpc.Call(ctx, "mrvl_nvm_subsys_remove_ctrlr", &params, &result)
+	err = s.removeNvmeController(ctx, subsys, controller, policy)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
-	if result.Status != 0 {
-		msg := fmt.Sprintf("Could not delete CTRL: %s", controller.Name)
-		return nil, status.Errorf(codes.InvalidArgument, msg)
-	}
 	// remove from the Database
-	delete(s.ListHelper, controller.Name)
+	s.deleteListed(controller.Name)
 	err = s.store.Delete(controller.Name)
 	if err != nil {
 		return nil, err
 	}
+	err = s.releasePcieFunction(controller.Name, controller.GetSpec().GetPcieId())
+	if err != nil {
+		return nil, err
+	}
+	err = s.releaseCtrlrID(subsystemNameOf(controller.Name), controller.Name, int(controller.Spec.GetNvmeControllerId()))
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Delete(metadataKey(controller.Name))
+	if err != nil {
+		return nil, err
+	}
 	return &emptypb.Empty{}, nil
 }
 
@@ -169,6 +332,14 @@ func (s *Server) UpdateNvmeController(ctx context.Context, in *pb.UpdateNvmeCont
 	if err := s.validateUpdateNvmeControllerRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.NvmeController.Name); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.NvmeController.Name)
+	meta, err := requestMetadata(ctx, in.NvmeController.Name)
+	if err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	controller := new(pb.NvmeController)
 	found, err := s.store.Get(in.NvmeController.Name, controller)
@@ -177,7 +348,7 @@ func (s *Server) UpdateNvmeController(ctx context.Context, in *pb.UpdateNvmeCont
 	}
 	if !found {
 		if in.AllowMissing {
-			log.Printf("TODO: in case of AllowMissing, create a new resource, don;t return error")
+			logger.DebugContext(ctx, "TODO: in case of AllowMissing, create a new resource, don;t return error")
 		}
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.NvmeController.Name)
 		return nil, err
@@ -187,7 +358,7 @@ func (s *Server) UpdateNvmeController(ctx context.Context, in *pb.UpdateNvmeCont
 	if err := fieldmask.Validate(in.UpdateMask, in.NvmeController); err != nil {
 		return nil, err
 	}
-	log.Printf("TODO: use resourceID=%v", resourceID)
+	logger.DebugContext(ctx, "TODO: use resourceID", "resource_id", resourceID)
 	subsysName := utils.ResourceIDToSubsystemName(
 		utils.GetSubsystemIDFromNvmeName(in.NvmeController.Name),
 	)
@@ -201,16 +372,38 @@ func (s *Server) UpdateNvmeController(ctx context.Context, in *pb.UpdateNvmeCont
 		err := status.Errorf(codes.NotFound, "unable to find key %s", subsysName)
 		return nil, err
 	}
-	ctrlrID := autoCtrlrIDAllocation
-	if in.NvmeController.Spec.NvmeControllerId != nil {
-		ctrlrID = int(*in.NvmeController.Spec.NvmeControllerId)
+	// an omitted endpoint keeps the PCIe function of the controller
+	endpoint := in.GetNvmeController().GetSpec().GetPcieId()
+	if endpoint == nil {
+		endpoint = controller.GetSpec().GetPcieId()
+	}
+	releaseQueues, err := s.checkQueueBudget(in.NvmeController.Name, endpoint, in.NvmeController.Spec)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseQueues()
+	// an omitted controller ID keeps the one of the controller
+	requestedID := in.NvmeController.Spec.NvmeControllerId
+	if requestedID == nil {
+		requestedID = controller.Spec.NvmeControllerId
+	}
+	ctrlrID, err := s.allocateCtrlrID(subsysName, in.NvmeController.Name, requestedID)
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err = s.reservePcieFunction(in.NvmeController.Name, endpoint)
+	if err != nil {
+		if ctrlrID != int(controller.Spec.GetNvmeControllerId()) {
+			s.releaseCtrlrIDOrLog(ctx, subsysName, in.NvmeController.Name, ctrlrID)
+		}
+		return nil, err
 	}
 	// construct command with parameters
 	params := models.MrvlNvmSubsysCreateCtrlrParams{
 		Subnqn:       subsys.Spec.Nqn,
-		PcieDomainID: int(in.GetNvmeController().GetSpec().GetPcieId().GetPortId().GetValue()),
-		PfID:         int(in.GetNvmeController().GetSpec().GetPcieId().GetPhysicalFunction().GetValue()),
-		VfID:         int(in.GetNvmeController().GetSpec().GetPcieId().GetVirtualFunction().GetValue()),
+		PcieDomainID: int(endpoint.GetPortId().GetValue()),
+		PfID:         int(endpoint.GetPhysicalFunction().GetValue()),
+		VfID:         int(endpoint.GetVirtualFunction().GetValue()),
 		CtrlrID:      ctrlrID,
 		MaxNsq:       int(in.GetNvmeController().GetSpec().GetMaxNsq()),
 		MaxNcq:       int(in.GetNvmeController().GetSpec().GetMaxNcq()),
@@ -218,21 +411,48 @@ func (s *Server) UpdateNvmeController(ctx context.Context, in *pb.UpdateNvmeCont
 	}
 	var result models.MrvlNvmSubsysCreateCtrlrResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_subsys_update_ctrlr", &params, &result)
-	if err != nil {
-		return nil, err
-	}
-	log.Printf("Received from SPDK: %v", result)
-	if result.Status != 0 {
+	if err == nil && result.Status != 0 {
 		msg := fmt.Sprintf("Could not update CTRL: %s", in.NvmeController.Name)
-		return nil, status.Errorf(codes.InvalidArgument, msg)
+		err = status.Errorf(codes.InvalidArgument, msg)
 	}
 	response := utils.ProtoClone(in.NvmeController)
-	response.Spec.NvmeControllerId = proto.Int32(int32(result.CtrlrID))
-	response.Status = &pb.NvmeControllerStatus{Active: true}
+	response.Spec.Endpoint = &pb.NvmeControllerSpec_PcieId{PcieId: endpoint}
+	response.Spec.NvmeControllerId = proto.Int32(int32(ctrlrID))
+	if err == nil && queuesLeftToSdk(in.NvmeController.Spec) {
+		// the budget is checked again with the queues granted by the SDK
+		s.discoverQueues(ctx, subsys.Spec.Nqn, response.Spec)
+		if _, err = s.checkQueueBudget(in.NvmeController.Name, endpoint, response.Spec); err != nil {
+			s.rollbackNvmeControllerUpdate(ctx, subsys, controller)
+		}
+	}
+	if previous := controller.GetSpec().GetPcieId(); pcieEndpointOf(endpoint) != pcieEndpointOf(previous) {
+		// release the PCIe function the controller does not use anymore
+		released := previous
+		if err != nil {
+			released = endpoint
+		}
+		s.releasePcieFunctionOrLog(ctx, in.NvmeController.Name, released)
+	}
+	if previous := int(controller.Spec.GetNvmeControllerId()); ctrlrID != previous {
+		// release the controller ID the controller does not use anymore
+		released := previous
+		if err != nil {
+			released = ctrlrID
+		}
+		s.releaseCtrlrIDOrLog(ctx, subsysName, in.NvmeController.Name, released)
+	}
+	if err != nil {
+		return nil, err
+	}
+	response.Status = &pb.NvmeControllerStatus{Active: controllerActive(controller)}
 	err = s.store.Set(in.NvmeController.Name, response)
 	if err != nil {
 		return nil, err
 	}
+	err = s.replaceResourceMetadata(meta)
+	if err != nil {
+		return nil, err
+	}
 	return response, nil
 }
 
@@ -243,10 +463,17 @@ func (s *Server) ListNvmeControllers(ctx context.Context, in *pb.ListNvmeControl
 		return nil, err
 	}
 	// fetch object from the database
-	size, offset, perr := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	size, offset, perr := s.extractPagination(in.PageSize, in.PageToken)
 	if perr != nil {
 		return nil, perr
 	}
+	selector, err := labelSelectorOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Parent); err != nil {
+		return nil, err
+	}
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.Parent, subsys)
 	if err != nil {
@@ -264,22 +491,46 @@ func (s *Server) ListNvmeControllers(ctx context.Context, in *pb.ListNvmeControl
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not list CTRLs: %v", in.Parent)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
+	if selector != nil {
+		selected, err := s.selectedControllerIDs(selector, in.Parent)
+		if err != nil {
+			return nil, err
+		}
+		ctrlrIDList := result.CtrlrIDList[:0]
+		for _, r := range result.CtrlrIDList {
+			if selected[r.CtrlrID] {
+				ctrlrIDList = append(ctrlrIDList, r)
+			}
+		}
+		result.CtrlrIDList = ctrlrIDList
+	}
 	token, hasMoreElements := "", false
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(result.CtrlrIDList), offset, size)
+	logger.DebugContext(ctx, "Limiting result", "len", len(result.CtrlrIDList), "offset", offset, "size", size)
 	result.CtrlrIDList, hasMoreElements = utils.LimitPagination(result.CtrlrIDList, offset, size)
 	if hasMoreElements {
 		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+		s.setPageToken(token, offset+size)
+	}
+	// the SDK does not report the state of the controllers, the store does
+	controllers, err := s.subsystemControllers(subsys)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[int]*pb.NvmeController, len(controllers))
+	for _, c := range controllers {
+		stored[int(c.Spec.GetNvmeControllerId())] = c
 	}
 	Blobarray := make([]*pb.NvmeController, len(result.CtrlrIDList))
 	for i := range result.CtrlrIDList {
 		r := &result.CtrlrIDList[i]
 		Blobarray[i] = &pb.NvmeController{Spec: &pb.NvmeControllerSpec{NvmeControllerId: proto.Int32(int32(r.CtrlrID))}}
+		if c, ok := stored[r.CtrlrID]; ok {
+			Blobarray[i].Status = &pb.NvmeControllerStatus{Active: controllerActive(c)}
+		}
 	}
 	sortNvmeControllers(Blobarray)
 	return &pb.ListNvmeControllersResponse{NvmeControllers: Blobarray}, nil
@@ -291,6 +542,9 @@ func (s *Server) GetNvmeController(ctx context.Context, in *pb.GetNvmeController
 	if err := s.validateGetNvmeControllerRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	controller := new(pb.NvmeController)
 	found, err := s.store.Get(in.Name, controller)
@@ -323,13 +577,17 @@ func (s *Server) GetNvmeController(ctx context.Context, in *pb.GetNvmeController
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not get CTRL: %s", in.Name)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
 
-	return &pb.NvmeController{Name: in.Name, Spec: &pb.NvmeControllerSpec{NvmeControllerId: controller.Spec.NvmeControllerId}, Status: &pb.NvmeControllerStatus{Active: true}}, nil
+	meta, err := s.resourceMetadata(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	sendMetadataHeader(ctx, meta)
+	return &pb.NvmeController{Name: in.Name, Spec: &pb.NvmeControllerSpec{NvmeControllerId: controller.Spec.NvmeControllerId}, Status: &pb.NvmeControllerStatus{Active: controllerActive(controller)}}, nil
 }
 
 // StatsNvmeController gets an Nvme controller stats
@@ -338,6 +596,9 @@ func (s *Server) StatsNvmeController(ctx context.Context, in *pb.StatsNvmeContro
 	if err := s.validateStatsNvmeControllerRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	controller := new(pb.NvmeController)
 	found, err := s.store.Get(in.Name, controller)
@@ -370,7 +631,6 @@ func (s *Server) StatsNvmeController(ctx context.Context, in *pb.StatsNvmeContro
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not stats CTRL: %s", in.Name)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
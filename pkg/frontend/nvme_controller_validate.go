@@ -31,7 +31,8 @@ func (s *Server) validateCreateNvmeControllerRequest(in *pb.CreateNvmeController
 		return fmt.Errorf("not supported transport type: %v", in.NvmeController.Spec.Trtype)
 	}
 
-	if in.NvmeController.Spec.GetPcieId() == nil {
+	// an omitted endpoint is assigned from the PCIe functions inventory
+	if in.NvmeController.Spec.GetPcieId() == nil && !s.pcieEndpointAssignable(in.NvmeController.Spec) {
 		return errors.New("invalid endpoint type passed for transport")
 	}
 
@@ -58,7 +59,8 @@ func (s *Server) validateUpdateNvmeControllerRequest(in *pb.UpdateNvmeController
 		return fmt.Errorf("not supported transport type: %v", in.NvmeController.Spec.Trtype)
 	}
 
-	if in.NvmeController.Spec.GetPcieId() == nil {
+	// an omitted endpoint is assigned from the PCIe functions inventory
+	if in.NvmeController.Spec.GetPcieId() == nil && !s.pcieEndpointAssignable(in.NvmeController.Spec) {
 		return errors.New("invalid endpoint type passed for transport")
 	}
 
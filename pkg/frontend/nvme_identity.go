@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+const (
+	// maxNqnLength is the maximum length in bytes of an NQN
+	maxNqnLength = 223
+	// maxModelNumberLength is the length in bytes of the MN field of the
+	// Identify Controller data structure
+	maxModelNumberLength = 40
+	// maxSerialNumberLength is the length in bytes of the SN field of the
+	// Identify Controller data structure
+	maxSerialNumberLength = 20
+	// maxNvmeNamespaces is the highest number of namespaces of a subsystem,
+	// NSID 0xffffffff is the broadcast value
+	maxNvmeNamespaces = 0xfffffffe
+)
+
+var (
+	// nqnRegexp matches the NVMe qualified names of the nqn.yyyy-mm.<reverse
+	// domain>:<string> form
+	nqnRegexp = regexp.MustCompile(`^nqn\.[0-9]{4}-(0[1-9]|1[0-2])(\.[a-zA-Z0-9]+)+(:[a-zA-Z0-9-.]+)+$`)
+	// uuidRegexp matches the RFC 9562 UUIDs of all versions, 1 to 8
+	uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[1-8][0-9a-fA-F]{3}-[89abAB][0-9a-fA-F]{3}-[0-9a-fA-F]{12}$`)
+)
+
+// invalidFieldError returns an InvalidArgument status with msg, detailing
+// the violation of field in a BadRequest
+func invalidFieldError(field string, msg string) error {
+	st := status.New(codes.InvalidArgument, msg)
+	detailed, err := st.WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: msg}},
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// validateNqn checks that the NQN of field fits in 223 bytes and matches
+// the NQN format
+func validateNqn(field string, name string, nqn string) error {
+	if len(nqn) > maxNqnLength {
+		msg := fmt.Sprintf("%s value (%s) is too long, have to be between 1 and %d", name, nqn, maxNqnLength)
+		return invalidFieldError(field, msg)
+	}
+	if !nqnRegexp.MatchString(nqn) {
+		msg := fmt.Sprintf("NQN value (%s) does not match pattern", nqn)
+		return invalidFieldError(field, msg)
+	}
+	return nil
+}
+
+// validateASCIIString checks that the value of field fits in length bytes
+// of printable ASCII characters
+func validateASCIIString(field string, name string, value string, length int) error {
+	if len(value) > length {
+		msg := fmt.Sprintf("%s value (%s) is too long, have to be between 1 and %d", name, value, length)
+		return invalidFieldError(field, msg)
+	}
+	for _, c := range value {
+		if c < 0x20 || c > 0x7e {
+			msg := fmt.Sprintf("%s value (%s) has to contain only printable ASCII characters", name, value)
+			return invalidFieldError(field, msg)
+		}
+	}
+	return nil
+}
+
+// validateNguid checks that the NGUID of field is 16 bytes in hex, with
+// optional 0x prefix and dashes
+func validateNguid(field string, nguid string) error {
+	if b, err := hex.DecodeString(normalizeNguid(nguid)); err != nil || len(b) != 16 {
+		msg := fmt.Sprintf("Nguid value (%s) is not 16 bytes in hex", nguid)
+		return invalidFieldError(field, msg)
+	}
+	return nil
+}
+
+// validateUUID checks that the UUID of field is an RFC 9562 UUID
+func validateUUID(field string, uuid string) error {
+	if !uuidRegexp.MatchString(uuid) {
+		msg := fmt.Sprintf("Uuid value (%s) is not an RFC 9562 UUID", uuid)
+		return invalidFieldError(field, msg)
+	}
+	return nil
+}
+
+// normalizeNguid returns the hex digits of an NGUID, without 0x prefix
+// and dashes
+func normalizeNguid(nguid string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(strings.ToLower(nguid), "0x"), "-", "")
+}
+
+// formatEui64 encodes an EUI-64 the way the SDK expects it, in 0x prefixed
+// hex
+func formatEui64(eui64 int64) string {
+	return fmt.Sprintf("0x%016x", uint64(eui64))
+}
+
+// namespaceIdentifier is a namespace identifier indexed in the store, so
+// that it is unique across the DPU
+type namespaceIdentifier struct {
+	name  string
+	value string
+	key   string
+}
+
+// namespaceIdentifiers returns the identifiers set in the spec of a
+// namespace
+func namespaceIdentifiers(spec *pb.NvmeNamespaceSpec) []namespaceIdentifier {
+	var ids []namespaceIdentifier
+	if spec.Nguid != "" {
+		ids = append(ids, namespaceIdentifier{"Nguid", spec.Nguid, "nguids/" + normalizeNguid(spec.Nguid)})
+	}
+	if spec.Uuid != "" {
+		ids = append(ids, namespaceIdentifier{"Uuid", spec.Uuid, "uuids/" + strings.ToLower(spec.Uuid)})
+	}
+	if spec.Eui64 != 0 {
+		ids = append(ids, namespaceIdentifier{"Eui64", formatEui64(spec.Eui64), fmt.Sprintf("eui64s/%016x", uint64(spec.Eui64))})
+	}
+	return ids
+}
+
+// namespaceIdentifierUser returns the namespace using the identifier of
+// the store key, empty if it is free
+func (s *Server) namespaceIdentifierUser(key string) (string, error) {
+	user := new(wrapperspb.StringValue)
+	if _, err := s.store.Get(key, user); err != nil {
+		return "", err
+	}
+	return user.Value, nil
+}
+
+// generateEui64 returns an EUI-64 of the OUI of the options with a random
+// 40 bits extension identifier
+func (s *Server) generateEui64() (int64, error) {
+	var b [8]byte
+	if _, err := rand.Read(b[3:]); err != nil {
+		return 0, err
+	}
+	copy(b[:3], s.opts.OUI[:])
+	return int64(binary.BigEndian.Uint64(b[:])), nil
+}
+
+// generateNguid returns an NGUID of a random vendor specific extension
+// identifier followed by a generated EUI-64
+func (s *Server) generateNguid() (string, error) {
+	var vendor [8]byte
+	if _, err := rand.Read(vendor[:]); err != nil {
+		return "", err
+	}
+	eui64, err := s.generateEui64()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("0x%x%016x", vendor, uint64(eui64)), nil
+}
+
+// generateNamespaceIdentifiers fills the identifiers omitted in the spec
+// of a namespace with generated ones unused by the other namespaces
+func (s *Server) generateNamespaceIdentifiers(spec *pb.NvmeNamespaceSpec) error {
+	for spec.Nguid == "" {
+		nguid, err := s.generateNguid()
+		if err != nil {
+			return err
+		}
+		user, err := s.namespaceIdentifierUser("nguids/" + normalizeNguid(nguid))
+		if err != nil {
+			return err
+		}
+		if user == "" {
+			spec.Nguid = nguid
+		}
+	}
+	for spec.Uuid == "" {
+		id := uuid.New().String()
+		user, err := s.namespaceIdentifierUser("uuids/" + id)
+		if err != nil {
+			return err
+		}
+		if user == "" {
+			spec.Uuid = id
+		}
+	}
+	for spec.Eui64 == 0 {
+		eui64, err := s.generateEui64()
+		if err != nil {
+			return err
+		}
+		user, err := s.namespaceIdentifierUser(fmt.Sprintf("eui64s/%016x", uint64(eui64)))
+		if err != nil {
+			return err
+		}
+		if user == "" {
+			spec.Eui64 = eui64
+		}
+	}
+	return nil
+}
+
+// reserveNamespaceIdentifiers generates the identifiers omitted in the
+// spec of the namespace name and indexes them all, or returns
+// AlreadyExists if one is used by another namespace
+func (s *Server) reserveNamespaceIdentifiers(name string, spec *pb.NvmeNamespaceSpec) error {
+	s.identifierMutex.Lock()
+	defer s.identifierMutex.Unlock()
+	for _, id := range namespaceIdentifiers(spec) {
+		user, err := s.namespaceIdentifierUser(id.key)
+		if err != nil {
+			return err
+		}
+		if user != "" && user != name {
+			return status.Errorf(codes.AlreadyExists, "%s value (%s) is already used by %s", id.name, id.value, user)
+		}
+	}
+	if err := s.generateNamespaceIdentifiers(spec); err != nil {
+		return err
+	}
+	for _, id := range namespaceIdentifiers(spec) {
+		if err := s.store.Set(id.key, wrapperspb.String(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseNamespaceIdentifiers frees the identifiers of the spec indexed
+// for the namespace name
+func (s *Server) releaseNamespaceIdentifiers(name string, spec *pb.NvmeNamespaceSpec) error {
+	s.identifierMutex.Lock()
+	defer s.identifierMutex.Unlock()
+	for _, id := range namespaceIdentifiers(spec) {
+		user, err := s.namespaceIdentifierUser(id.key)
+		if err != nil {
+			return err
+		}
+		if user != name {
+			continue
+		}
+		if err := s.store.Delete(id.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// releaseNamespaceIdentifiersOrLog frees the identifiers of the spec
+// indexed for the namespace name, only logging the failures
+func (s *Server) releaseNamespaceIdentifiersOrLog(ctx context.Context, name string, spec *pb.NvmeNamespaceSpec) {
+	if err := s.releaseNamespaceIdentifiers(name, spec); err != nil {
+		logger.ErrorContext(ctx, "Could not release the namespace identifiers", "name", name, "error", err)
+	}
+}
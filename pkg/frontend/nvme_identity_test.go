@@ -0,0 +1,307 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_NvmeIdentityValidation(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	newNamespaceName := utils.ResourceIDToNamespaceName(testSubsystemID, "namespace-new")
+	createSubsystem := func(spec *pb.NvmeSubsystemSpec) func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+		return func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+			return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{NvmeSubsystem: &pb.NvmeSubsystem{Spec: spec}, NvmeSubsystemId: "subsystem-new"})
+		}
+	}
+	createNamespace := func(spec *pb.NvmeNamespaceSpec) func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+		return func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+			return c.CreateNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: &pb.NvmeNamespace{Spec: spec}, NvmeNamespaceId: "namespace-new"})
+		}
+	}
+
+	tests := map[string]struct {
+		call    func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out     proto.Message
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+		field   string
+	}{
+		"non ASCII serial number": {
+			call:    createSubsystem(&pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5", SerialNumber: "séria"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "SerialNumber value (séria) has to contain only printable ASCII characters",
+			field:   "nvme_subsystem.spec.serial_number",
+		},
+		"invalid NQN month": {
+			call:    createSubsystem(&pb.NvmeSubsystemSpec{Nqn: "nqn.2022-13.io.spdk:opi5"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "NQN value (nqn.2022-13.io.spdk:opi5) does not match pattern",
+			field:   "nvme_subsystem.spec.nqn",
+		},
+		"invalid host NQN": {
+			call:    createSubsystem(&pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5", Hostnqn: "host"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "NQN value (host) does not match pattern",
+			field:   "nvme_subsystem.spec.hostnqn",
+		},
+		"negative max namespaces": {
+			call:    createSubsystem(&pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5", MaxNamespaces: -1}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "MaxNamespaces value (-1) is out of range, have to be between 0 and 4294967294",
+			field:   "nvme_subsystem.spec.max_namespaces",
+		},
+		"NGUID not 16 bytes": {
+			call:    createNamespace(&pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Nguid: "0x25f9cbc45d0f976f"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "Nguid value (0x25f9cbc45d0f976f) is not 16 bytes in hex",
+			field:   "nvme_namespace.spec.nguid",
+		},
+		"UUID not RFC 9562": {
+			call:    createNamespace(&pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Uuid: "1b4e28ba-2fa1-01d2-883f-b9a761bde3fb"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "Uuid value (1b4e28ba-2fa1-01d2-883f-b9a761bde3fb) is not an RFC 9562 UUID",
+			field:   "nvme_namespace.spec.uuid",
+		},
+		"negative NSID": {
+			call:    createNamespace(&pb.NvmeNamespaceSpec{HostNsid: -1, VolumeNameRef: "Malloc1"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "HostNsid value (-1) must not be negative",
+			field:   "nvme_namespace.spec.host_nsid",
+		},
+		"NSID over max namespaces": {
+			call:    createNamespace(&pb.NvmeNamespaceSpec{HostNsid: 17, VolumeNameRef: "Malloc1"}),
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "HostNsid value (17) is out of range, have to be between 1 and 16",
+			field:   "nvme_namespace.spec.host_nsid",
+		},
+		"valid identifiers": {
+			call: createNamespace(&pb.NvmeNamespaceSpec{
+				HostNsid:      16,
+				VolumeNameRef: "Malloc1",
+				Nguid:         "0x25f9cbc45d0f976fb9c1a14ff5aed4b0",
+				Uuid:          "1b4e28ba-2fa1-11d2-883f-b9a761bde3fb",
+				Eui64:         1967554867335598546,
+			}),
+			out: &pb.NvmeNamespace{
+				Name: newNamespaceName,
+				Spec: &pb.NvmeNamespaceSpec{
+					HostNsid:      16,
+					VolumeNameRef: "Malloc1",
+					Nguid:         "0x25f9cbc45d0f976fb9c1a14ff5aed4b0",
+					Uuid:          "1b4e28ba-2fa1-11d2-883f-b9a761bde3fb",
+					Eui64:         1967554867335598546,
+				},
+				Status: &pb.NvmeNamespaceStatus{
+					State:     pb.NvmeNamespaceStatus_STATE_ENABLED,
+					OperState: pb.NvmeNamespaceStatus_OPER_STATE_ONLINE,
+				},
+			},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 16}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+			field:   "",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			subsys := utils.ProtoClone(&testSubsystemWithStatus)
+			subsys.Spec.MaxNamespaces = 16
+			if err := s.store.Set(testSubsystemName, subsys); err != nil {
+				t.Fatal(err)
+			}
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Spec.HostNsid = 1
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testNamespaceName] = false
+
+			response, err := tt.call(testEnv.ctx, testEnv.client)
+
+			if tt.out != nil && !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+				return
+			}
+			checkTenantError(t, err, tt.errCode, tt.errMsg)
+			var fields []string
+			for _, detail := range status.Convert(err).Details() {
+				if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+					for _, v := range badRequest.FieldViolations {
+						fields = append(fields, v.Field)
+					}
+				}
+			}
+			if len(fields) != 1 || fields[0] != tt.field {
+				t.Error("field violations: expected", tt.field, "received", fields)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_NamespaceIdentifiers(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	newNamespaceName := utils.ResourceIDToNamespaceName(testSubsystemID, "namespace-new")
+	// the existing namespace uses these identifiers
+	existing := &pb.NvmeNamespaceSpec{
+		HostNsid:      1,
+		VolumeNameRef: "Malloc0",
+		Nguid:         "0x25f9cbc45d0f976fb9c1a14ff5aed4b0",
+		Uuid:          "1b4e28ba-2fa1-11d2-883f-b9a761bde3fb",
+		Eui64:         0x0050430000000001,
+	}
+
+	tests := map[string]struct {
+		in      *pb.NvmeNamespaceSpec
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+		indexed int
+	}{
+		"generated identifiers": {
+			in:      &pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1"},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 2}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+			indexed: 3,
+		},
+		"version 7 UUID": {
+			in:      &pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Uuid: "01890a5d-ac96-774b-bcce-b302099a8057"},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 2}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+			indexed: 3,
+		},
+		"NGUID already used": {
+			in:      &pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Nguid: "25F9CBC4-5D0F-976F-B9C1-A14FF5AED4B0"},
+			spdk:    []string{},
+			errCode: codes.AlreadyExists,
+			errMsg:  "Nguid value (25F9CBC4-5D0F-976F-B9C1-A14FF5AED4B0) is already used by " + testNamespaceName,
+			indexed: 0,
+		},
+		"UUID already used": {
+			in:      &pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Uuid: "1B4E28BA-2FA1-11D2-883F-B9A761BDE3FB"},
+			spdk:    []string{},
+			errCode: codes.AlreadyExists,
+			errMsg:  "Uuid value (1B4E28BA-2FA1-11D2-883F-B9A761BDE3FB) is already used by " + testNamespaceName,
+			indexed: 0,
+		},
+		"EUI-64 already used": {
+			in:      &pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Eui64: 0x0050430000000001},
+			spdk:    []string{},
+			errCode: codes.AlreadyExists,
+			errMsg:  "Eui64 value (0x0050430000000001) is already used by " + testNamespaceName,
+			indexed: 0,
+		},
+		"released on SDK failure": {
+			in:      &pb.NvmeNamespaceSpec{HostNsid: 2, VolumeNameRef: "Malloc1", Uuid: "b3563324-0b77-473b-8b4e-bda571120dfb", Eui64: 2},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`},
+			errCode: codes.InvalidArgument,
+			errMsg:  "Could not create NS: " + newNamespaceName,
+			indexed: 0,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			namespace := &pb.NvmeNamespace{Name: testNamespaceName, Spec: existing}
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testNamespaceName] = false
+			if err := s.reserveNamespaceIdentifiers(testNamespaceName, utils.ProtoClone(existing)); err != nil {
+				t.Fatal(err)
+			}
+
+			response, err := testEnv.client.CreateNvmeNamespace(testEnv.ctx, &pb.CreateNvmeNamespaceRequest{
+				Parent: testSubsystemName, NvmeNamespace: &pb.NvmeNamespace{Spec: tt.in}, NvmeNamespaceId: "namespace-new"})
+
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+				spec := response.GetSpec()
+				if err := validateNguid("nguid", spec.Nguid); err != nil {
+					t.Error("nguid: expected 16 bytes, received", spec.Nguid)
+				}
+				if err := validateUUID("uuid", spec.Uuid); err != nil {
+					t.Error("uuid: expected RFC 9562 UUID, received", spec.Uuid)
+				}
+				if oui := uint64(spec.Eui64) >> 40; oui != 0x005043 {
+					t.Errorf("eui64: expected OUI 005043, received %06x", oui)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+
+			indexed := 0
+			spec := tt.in
+			if response != nil {
+				spec = response.Spec
+			}
+			for _, id := range namespaceIdentifiers(spec) {
+				user, err := s.namespaceIdentifierUser(id.key)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if user == newNamespaceName {
+					indexed++
+				}
+			}
+			if indexed != tt.indexed {
+				t.Error("indexed identifiers: expected", tt.indexed, "received", indexed)
+			}
+		})
+	}
+}
@@ -0,0 +1,424 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// request and response metadata keys of the labels and annotations, one
+// key=value pair per value
+const (
+	labelKey         = "x-opi-label"
+	annotationKey    = "x-opi-annotation"
+	labelSelectorKey = "x-opi-label-selector"
+)
+
+// metadataKey is the store key of the labels and annotations of an Nvme
+// resource
+func metadataKey(name string) string {
+	return "metadata/" + name
+}
+
+// keyValues parses the key=value pairs of the request metadata key
+func keyValues(md metadata.MD, key string) (map[string]string, error) {
+	values := md.Get(key)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	pairs := make(map[string]string, len(values))
+	for _, v := range values {
+		k, value, ok := strings.Cut(v, "=")
+		if !ok {
+			msg := fmt.Sprintf("%s value (%s) is not a key=value pair", key, v)
+			return nil, status.Errorf(codes.InvalidArgument, msg)
+		}
+		pairs[k] = value
+	}
+	return pairs, nil
+}
+
+// requestMetadata returns the labels and annotations of name set in the
+// request metadata, nil if none
+func requestMetadata(ctx context.Context, name string) (*mb.NvmeResourceMetadata, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	labels, err := keyValues(md, labelKey)
+	if err != nil {
+		return nil, err
+	}
+	annotations, err := keyValues(md, annotationKey)
+	if err != nil {
+		return nil, err
+	}
+	if labels == nil && annotations == nil {
+		return nil, nil
+	}
+	meta := &mb.NvmeResourceMetadata{Name: name, Labels: labels, Annotations: annotations}
+	if err := validateNvmeResourceMetadata(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// sendMetadataHeader returns the labels and annotations of a resource in
+// the response header
+func sendMetadataHeader(ctx context.Context, meta *mb.NvmeResourceMetadata) {
+	md := metadata.MD{}
+	for _, k := range sortedKeys(meta.Labels) {
+		md.Append(labelKey, k+"="+meta.Labels[k])
+	}
+	for _, k := range sortedKeys(meta.Annotations) {
+		md.Append(annotationKey, k+"="+meta.Annotations[k])
+	}
+	if md.Len() == 0 {
+		return
+	}
+	if err := grpc.SetHeader(ctx, md); err != nil {
+		logger.DebugContext(ctx, "cannot send the resource metadata", "name", meta.Name, "error", err)
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resourceMetadata returns the labels and annotations of an Nvme resource
+func (s *Server) resourceMetadata(name string) (*mb.NvmeResourceMetadata, error) {
+	meta := new(mb.NvmeResourceMetadata)
+	found, err := s.store.Get(metadataKey(name), meta)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return &mb.NvmeResourceMetadata{Name: name}, nil
+	}
+	return meta, nil
+}
+
+// setResourceMetadata saves the labels and annotations of an Nvme
+// resource, nothing is saved for nil
+func (s *Server) setResourceMetadata(meta *mb.NvmeResourceMetadata) error {
+	if meta == nil {
+		return nil
+	}
+	if len(meta.Labels) == 0 && len(meta.Annotations) == 0 {
+		return s.store.Delete(metadataKey(meta.Name))
+	}
+	return s.store.Set(metadataKey(meta.Name), meta)
+}
+
+// replaceResourceMetadata replaces the labels and/or the annotations given
+// in meta, nothing is replaced for nil
+func (s *Server) replaceResourceMetadata(meta *mb.NvmeResourceMetadata) error {
+	if meta == nil {
+		return nil
+	}
+	current, err := s.resourceMetadata(meta.Name)
+	if err != nil {
+		return err
+	}
+	if meta.Labels != nil {
+		current.Labels = meta.Labels
+	}
+	if meta.Annotations != nil {
+		current.Annotations = meta.Annotations
+	}
+	return s.setResourceMetadata(current)
+}
+
+// labelSelectorOf returns the label selector of the request metadata, nil
+// if none
+func labelSelectorOf(ctx context.Context) (labelSelector, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(labelSelectorKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+	return parseLabelSelector(strings.Join(values, ","))
+}
+
+// matchesLabels checks if the labels of the Nvme resource name match the
+// selector
+func (s *Server) matchesLabels(selector labelSelector, name string) (bool, error) {
+	meta, err := s.resourceMetadata(name)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(meta.Labels), nil
+}
+
+// selectedSubsystemNqns returns the NQNs of the subsystems whose labels
+// match the selector
+func (s *Server) selectedSubsystemNqns(selector labelSelector) (map[string]bool, error) {
+	return s.subsystemNqns(func(name string) (bool, error) {
+		return s.matchesLabels(selector, name)
+	})
+}
+
+// selectedControllerIDs returns the controller IDs of the controllers of
+// the subsystem parent whose labels match the selector
+func (s *Server) selectedControllerIDs(selector labelSelector, parent string) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, parent+"/nvmeControllers/") {
+			continue
+		}
+		ok, err := s.matchesLabels(selector, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		controller := new(pb.NvmeController)
+		found, err := s.store.Get(key, controller)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ids[int(controller.GetSpec().GetNvmeControllerId())] = true
+		}
+	}
+	return ids, nil
+}
+
+// selectedNamespaceIDs returns the host NSIDs of the namespaces of the
+// subsystem parent whose labels match the selector
+func (s *Server) selectedNamespaceIDs(selector labelSelector, parent string) (map[int]bool, error) {
+	ids := make(map[int]bool)
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, parent+"/nvmeNamespaces/") {
+			continue
+		}
+		ok, err := s.matchesLabels(selector, key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		namespace := new(pb.NvmeNamespace)
+		found, err := s.store.Get(key, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			ids[int(namespace.GetSpec().GetHostNsid())] = true
+		}
+	}
+	return ids, nil
+}
+
+// GetNvmeResourceMetadata gets the labels and annotations of an Nvme resource
+func (s *Server) GetNvmeResourceMetadata(ctx context.Context, in *mb.GetNvmeResourceMetadataRequest) (*mb.NvmeResourceMetadata, error) {
+	// check input correctness
+	if err := s.validateGetNvmeResourceMetadataRequest(in); err != nil {
+		return nil, err
+	}
+	if err := s.checkNvmeResourceExists(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	return s.resourceMetadata(in.Name)
+}
+
+// UpdateNvmeResourceMetadata replaces the labels and/or annotations of an
+// Nvme resource
+func (s *Server) UpdateNvmeResourceMetadata(ctx context.Context, in *mb.UpdateNvmeResourceMetadataRequest) (*mb.NvmeResourceMetadata, error) {
+	// check input correctness
+	if err := s.validateUpdateNvmeResourceMetadataRequest(in); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.NvmeResourceMetadata.Name)
+	if err := s.checkNvmeResourceExists(ctx, in.NvmeResourceMetadata.Name); err != nil {
+		return nil, err
+	}
+	meta, err := s.resourceMetadata(in.NvmeResourceMetadata.Name)
+	if err != nil {
+		return nil, err
+	}
+	paths := in.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{"labels", "annotations"}
+	}
+	for _, p := range paths {
+		switch p {
+		case "labels":
+			meta.Labels = in.NvmeResourceMetadata.Labels
+		case "annotations":
+			meta.Annotations = in.NvmeResourceMetadata.Annotations
+		}
+	}
+	if err := s.setResourceMetadata(meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// ListNvmeResourceMetadata lists the labels and annotations of Nvme resources
+func (s *Server) ListNvmeResourceMetadata(ctx context.Context, in *mb.ListNvmeResourceMetadataRequest) (*mb.ListNvmeResourceMetadataResponse, error) {
+	// check input correctness
+	if err := s.validateListNvmeResourceMetadataRequest(in); err != nil {
+		return nil, err
+	}
+	selector, err := parseLabelSelector(in.LabelSelector)
+	if err != nil {
+		return nil, err
+	}
+	size, offset, perr := s.extractPagination(in.PageSize, in.PageToken)
+	if perr != nil {
+		return nil, perr
+	}
+	var names []string
+	for _, key := range s.listedKeys() {
+		// the operations are listed as well, but carry no metadata
+		if !strings.HasPrefix(key, "nvmeSubsystems/") {
+			continue
+		}
+		if in.Parent != "" && key != in.Parent && !strings.HasPrefix(key, in.Parent+"/") {
+			continue
+		}
+		owned, err := s.ownedByCaller(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if owned {
+			names = append(names, key)
+		}
+	}
+	sort.Strings(names)
+	var list []*mb.NvmeResourceMetadata
+	for _, name := range names {
+		meta, err := s.resourceMetadata(name)
+		if err != nil {
+			return nil, err
+		}
+		if selector.Matches(meta.Labels) {
+			list = append(list, meta)
+		}
+	}
+	token, hasMoreElements := "", false
+	logger.DebugContext(ctx, "Limiting result", "len", len(list), "offset", offset, "size", size)
+	list, hasMoreElements = utils.LimitPagination(list, offset, size)
+	if hasMoreElements {
+		token = uuid.New().String()
+		s.setPageToken(token, offset+size)
+	}
+	return &mb.ListNvmeResourceMetadataResponse{NvmeResourceMetadata: list, NextPageToken: token}, nil
+}
+
+// checkNvmeResourceExists returns NotFound if the Nvme resource name does
+// not exist or is not accessible to the caller
+func (s *Server) checkNvmeResourceExists(ctx context.Context, name string) error {
+	if err := s.checkOwnedByCaller(ctx, name); err != nil {
+		return err
+	}
+	resource := newWatchedResource(name)
+	if resource == nil {
+		return status.Errorf(codes.NotFound, "unable to find key %s", name)
+	}
+	found, err := s.store.Get(name, resource)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return status.Errorf(codes.NotFound, "unable to find key %s", name)
+	}
+	return nil
+}
+
+// labelOperator is the operator of a label requirement
+type labelOperator int
+
+const (
+	labelEquals labelOperator = iota
+	labelNotEquals
+	labelExists
+	labelDoesNotExist
+)
+
+// labelRequirement is a requirement of a label selector: key=value,
+// key!=value, key or !key
+type labelRequirement struct {
+	key      string
+	operator labelOperator
+	value    string
+}
+
+// labelSelector selects the resources with labels matching all its
+// requirements
+type labelSelector []labelRequirement
+
+// parseLabelSelector parses the comma separated requirements of selector
+func parseLabelSelector(selector string) (labelSelector, error) {
+	var requirements labelSelector
+	for _, r := range strings.Split(selector, ",") {
+		r = strings.TrimSpace(r)
+		if r == "" {
+			continue
+		}
+		requirement := labelRequirement{key: r, operator: labelExists}
+		if key, value, ok := strings.Cut(r, "!="); ok {
+			requirement = labelRequirement{key: key, operator: labelNotEquals, value: value}
+		} else if key, value, ok := strings.Cut(r, "="); ok {
+			requirement = labelRequirement{key: key, operator: labelEquals, value: strings.TrimPrefix(value, "=")}
+		} else if strings.HasPrefix(r, "!") {
+			requirement = labelRequirement{key: strings.TrimPrefix(r, "!"), operator: labelDoesNotExist}
+		}
+		requirement.key = strings.TrimSpace(requirement.key)
+		requirement.value = strings.TrimSpace(requirement.value)
+		if err := validateLabel(requirement.key, requirement.value); err != nil {
+			msg := fmt.Sprintf("LabelSelector requirement (%s) is invalid: %s", r, status.Convert(err).Message())
+			return nil, status.Errorf(codes.InvalidArgument, msg)
+		}
+		requirements = append(requirements, requirement)
+	}
+	return requirements, nil
+}
+
+// Matches checks if labels satisfy all the requirements, an empty selector
+// matches everything
+func (sel labelSelector) Matches(labels map[string]string) bool {
+	for _, r := range sel {
+		value, ok := labels[r.key]
+		switch r.operator {
+		case labelEquals:
+			if !ok || value != r.value {
+				return false
+			}
+		case labelNotEquals:
+			if ok && value == r.value {
+				return false
+			}
+		case labelExists:
+			if !ok {
+				return false
+			}
+		case labelDoesNotExist:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,391 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_NvmeResourceMetadata(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	otherControllerName := utils.ResourceIDToControllerName(testSubsystemID, "controller-other")
+	otherController := &pb.NvmeController{
+		Name: otherControllerName,
+		Spec: &pb.NvmeControllerSpec{NvmeControllerId: proto.Int32(3)},
+	}
+	controllerMetadata := &mb.NvmeResourceMetadata{
+		Name:        testControllerName,
+		Labels:      map[string]string{"vm": "vm-1", "example.com/class": "gold"},
+		Annotations: map[string]string{"note": "first, second"},
+	}
+	otherControllerMetadata := &mb.NvmeResourceMetadata{
+		Name:   otherControllerName,
+		Labels: map[string]string{"vm": "vm-2"},
+	}
+	newController := &pb.NvmeController{
+		Spec: &pb.NvmeControllerSpec{
+			Endpoint: &pb.NvmeControllerSpec_PcieId{
+				PcieId: &pb.PciEndpoint{
+					PhysicalFunction: wrapperspb.Int32(0),
+					VirtualFunction:  wrapperspb.Int32(0),
+					PortId:           wrapperspb.Int32(0)},
+			},
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: proto.Int32(18),
+		},
+	}
+	namespaceMetadata := &mb.NvmeResourceMetadata{
+		Name:   testNamespaceName,
+		Labels: map[string]string{"vm": "vm-1"},
+	}
+
+	tests := map[string]struct {
+		md      metadata.MD
+		call    func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out     proto.Message
+		header  metadata.MD
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+	}{
+		"get metadata": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetNvmeResourceMetadata(ctx, &mb.GetNvmeResourceMetadataRequest{Name: testControllerName})
+			},
+			out:     controllerMetadata,
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"get empty metadata": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetNvmeResourceMetadata(ctx, &mb.GetNvmeResourceMetadataRequest{Name: testSubsystemName})
+			},
+			out:     &mb.NvmeResourceMetadata{Name: testSubsystemName},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"get metadata of unknown resource": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetNvmeResourceMetadata(ctx, &mb.GetNvmeResourceMetadataRequest{Name: "unknown-id"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", "unknown-id"),
+		},
+		"update labels only": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.UpdateNvmeResourceMetadata(ctx, &mb.UpdateNvmeResourceMetadataRequest{
+					NvmeResourceMetadata: &mb.NvmeResourceMetadata{Name: testControllerName, Labels: map[string]string{"vm": "vm-3"}},
+					UpdateMask:           &fieldmaskpb.FieldMask{Paths: []string{"labels"}},
+				})
+			},
+			out: &mb.NvmeResourceMetadata{
+				Name:        testControllerName,
+				Labels:      map[string]string{"vm": "vm-3"},
+				Annotations: controllerMetadata.Annotations,
+			},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"update all": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.UpdateNvmeResourceMetadata(ctx, &mb.UpdateNvmeResourceMetadataRequest{
+					NvmeResourceMetadata: &mb.NvmeResourceMetadata{Name: testSubsystemName, Annotations: map[string]string{"owner": "team-a"}},
+				})
+			},
+			out:     &mb.NvmeResourceMetadata{Name: testSubsystemName, Annotations: map[string]string{"owner": "team-a"}},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"update invalid label": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.UpdateNvmeResourceMetadata(ctx, &mb.UpdateNvmeResourceMetadataRequest{
+					NvmeResourceMetadata: &mb.NvmeResourceMetadata{Name: testControllerName, Labels: map[string]string{"vm": "vm 1"}},
+				})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Label value (%s) of %s does not match pattern", "vm 1", "vm"),
+		},
+		"update invalid mask": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.UpdateNvmeResourceMetadata(ctx, &mb.UpdateNvmeResourceMetadataRequest{
+					NvmeResourceMetadata: &mb.NvmeResourceMetadata{Name: testControllerName},
+					UpdateMask:           &fieldmaskpb.FieldMask{Paths: []string{"name"}},
+				})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("UpdateMask path (%s) is not labels or annotations", "name"),
+		},
+		"list by label": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeResourceMetadata(ctx, &mb.ListNvmeResourceMetadataRequest{LabelSelector: "vm=vm-1"})
+			},
+			out: &mb.ListNvmeResourceMetadataResponse{
+				NvmeResourceMetadata: []*mb.NvmeResourceMetadata{controllerMetadata, namespaceMetadata},
+			},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list by parent and missing label": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeResourceMetadata(ctx, &mb.ListNvmeResourceMetadataRequest{Parent: testSubsystemName, LabelSelector: "!example.com/class, vm!=vm-1"})
+			},
+			out: &mb.ListNvmeResourceMetadataResponse{
+				NvmeResourceMetadata: []*mb.NvmeResourceMetadata{{Name: testSubsystemName}, otherControllerMetadata},
+			},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list without parent and missing label": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeResourceMetadata(ctx, &mb.ListNvmeResourceMetadataRequest{LabelSelector: "!example.com/class, vm!=vm-1"})
+			},
+			out: &mb.ListNvmeResourceMetadataResponse{
+				NvmeResourceMetadata: []*mb.NvmeResourceMetadata{{Name: testSubsystemName}, otherControllerMetadata},
+			},
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list with invalid selector": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeResourceMetadata(ctx, &mb.ListNvmeResourceMetadataRequest{LabelSelector: "vm=vm 1"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("LabelSelector requirement (%s) is invalid: Label value (%s) of %s does not match pattern", "vm=vm 1", "vm 1", "vm"),
+		},
+		"list controllers by label": {
+			md: metadata.Pairs(labelSelectorKey, "vm"),
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeControllers(ctx, &pb.ListNvmeControllersRequest{Parent: testSubsystemName})
+			},
+			out: &pb.ListNvmeControllersResponse{
+				NvmeControllers: []*pb.NvmeController{
+					{Spec: &pb.NvmeControllerSpec{NvmeControllerId: proto.Int32(3)}, Status: &pb.NvmeControllerStatus{Active: true}},
+					{Spec: &pb.NvmeControllerSpec{NvmeControllerId: proto.Int32(17)}, Status: &pb.NvmeControllerStatus{Active: true}},
+				},
+			},
+			spdk:    []string{`{"jsonrpc":"2.0","id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id_list":[{"ctrlr_id":1},{"ctrlr_id":3},{"ctrlr_id":17}]}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list namespaces by label": {
+			md: metadata.Pairs(labelSelectorKey, "vm=vm-1"),
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeNamespaces(ctx, &pb.ListNvmeNamespacesRequest{Parent: testSubsystemName})
+			},
+			out: &pb.ListNvmeNamespacesResponse{
+				NvmeNamespaces: []*pb.NvmeNamespace{{Spec: &pb.NvmeNamespaceSpec{HostNsid: 22}, Status: testNamespaceWithStatus.Status}},
+			},
+			spdk:    []string{`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"ns_list":[{"ns_instance_id":11,"bdev":"bdev01","ctrlr_id_list":[]},{"ns_instance_id":22,"bdev":"bdev02","ctrlr_id_list":[]}]}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list subsystems by label": {
+			md: metadata.Pairs(labelSelectorKey, "vm=vm-1"),
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeSubsystems(ctx, &pb.ListNvmeSubsystemsRequest{})
+			},
+			out:     &pb.ListNvmeSubsystemsResponse{NvmeSubsystems: []*pb.NvmeSubsystem{}},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[{"subnqn":"nqn.2022-09.io.spdk:opi3"}]}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"get namespace with metadata": {
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetNvmeNamespace(ctx, &pb.GetNvmeNamespaceRequest{Name: testNamespaceName})
+			},
+			out: &pb.NvmeNamespace{
+				Name: testNamespaceName,
+				Spec: &pb.NvmeNamespaceSpec{Nguid: "0x25f9cbc45d0f976fb9c1a14ff5aed4b0"},
+				Status: &pb.NvmeNamespaceStatus{
+					State:     pb.NvmeNamespaceStatus_STATE_ENABLED,
+					OperState: pb.NvmeNamespaceStatus_OPER_STATE_ONLINE,
+				},
+			},
+			header:  metadata.Pairs(labelKey, "vm=vm-1"),
+			spdk:    []string{`{"jsonrpc":"2.0","id":%d,"result":{"status":0,"nguid":"0x25f9cbc45d0f976fb9c1a14ff5aed4b0","eui64":"0xa7632f80702e4242","uuid":"0xb35633240b77073b8b4ebda571120dfb","nmic":1,"bdev":"bdev01","num_ctrlrs":1,"ctrlr_id_list":[{"ctrlr_id":1}]}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"create controller with metadata": {
+			md: metadata.Pairs(labelKey, "vm=vm-4", annotationKey, "note=a=b"),
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				_, err := c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{
+					Parent:           testSubsystemName,
+					NvmeController:   newController,
+					NvmeControllerId: "controller-new",
+				})
+				if err != nil {
+					return nil, err
+				}
+				return c.GetNvmeResourceMetadata(ctx, &mb.GetNvmeResourceMetadataRequest{Name: utils.ResourceIDToControllerName(testSubsystemID, "controller-new")})
+			},
+			out: &mb.NvmeResourceMetadata{
+				Name:        utils.ResourceIDToControllerName(testSubsystemID, "controller-new"),
+				Labels:      map[string]string{"vm": "vm-4"},
+				Annotations: map[string]string{"note": "a=b"},
+			},
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0}}`,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"create controller with invalid metadata": {
+			md: metadata.Pairs(labelKey, "vm"),
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{
+					Parent:           testSubsystemName,
+					NvmeController:   newController,
+					NvmeControllerId: "controller-new",
+				})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("%s value (%s) is not a key=value pair", labelKey, "vm"),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			for _, r := range []struct {
+				name   string
+				object proto.Message
+			}{
+				{testSubsystemName, &testSubsystemWithStatus},
+				{testControllerName, &testControllerWithStatus},
+				{otherControllerName, otherController},
+				{testNamespaceName, &testNamespaceWithStatus},
+				{testOperationName, &testOperation},
+			} {
+				if err := s.store.Set(r.name, r.object); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[r.name] = false
+			}
+			for _, meta := range []*mb.NvmeResourceMetadata{controllerMetadata, otherControllerMetadata, namespaceMetadata} {
+				if err := s.setResourceMetadata(meta); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			ctx := metadata.NewOutgoingContext(testEnv.ctx, tt.md)
+			var header metadata.MD
+			response, err := tt.call(ctx, &frontendClient{
+				FrontendNvmeServiceClient: &headerClient{testEnv.client.FrontendNvmeServiceClient, &header},
+				NvmeMetadataServiceClient: testEnv.client.NvmeMetadataServiceClient,
+			})
+
+			if tt.out == nil {
+				if err == nil {
+					t.Error("response: expected error, received", response)
+				}
+			} else if !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			for k, v := range tt.header {
+				if fmt.Sprint(header.Get(k)) != fmt.Sprint(v) {
+					t.Error("header", k, "expected", v, "received", header.Get(k))
+				}
+			}
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+		})
+	}
+}
+
+// headerClient records the response header of GetNvmeNamespace
+type headerClient struct {
+	pb.FrontendNvmeServiceClient
+	header *metadata.MD
+}
+
+func (c *headerClient) GetNvmeNamespace(ctx context.Context, in *pb.GetNvmeNamespaceRequest, opts ...grpc.CallOption) (*pb.NvmeNamespace, error) {
+	return c.FrontendNvmeServiceClient.GetNvmeNamespace(ctx, in, append(opts, grpc.Header(c.header))...)
+}
+
+func TestFrontEnd_LabelSelector(t *testing.T) {
+	labels := map[string]string{"vm": "vm-1", "example.com/class": "gold"}
+	tests := map[string]struct {
+		selector string
+		match    bool
+		errMsg   string
+	}{
+		"empty":             {selector: "", match: true},
+		"equals":            {selector: "vm=vm-1", match: true},
+		"double equals":     {selector: "vm==vm-1", match: true},
+		"equals other":      {selector: "vm=vm-2", match: false},
+		"not equals":        {selector: "vm!=vm-2", match: true},
+		"not equals absent": {selector: "zone!=a", match: true},
+		"exists":            {selector: "example.com/class", match: true},
+		"does not exist":    {selector: "!zone", match: true},
+		"all requirements":  {selector: "vm=vm-1, !example.com/class", match: false},
+		"invalid key": {
+			selector: "-vm=vm-1",
+			errMsg:   "LabelSelector requirement (-vm=vm-1) is invalid: Label key (-vm) does not match pattern",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			selector, err := parseLabelSelector(tt.selector)
+			if err != nil {
+				if status.Convert(err).Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", err)
+				}
+				return
+			}
+			if tt.errMsg != "" {
+				t.Error("expected error", tt.errMsg)
+			}
+			if selector.Matches(labels) != tt.match {
+				t.Error("match: expected", tt.match, "for", tt.selector)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"fmt"
+	"regexp"
+
+	"go.einride.tech/aip/fieldbehavior"
+	"go.einride.tech/aip/resourcename"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+)
+
+var (
+	// metadataKeyRegex matches the label and annotation keys: an optional
+	// DNS subdomain prefix and a name of at most 63 characters
+	metadataKeyRegex = regexp.MustCompile(`^([a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*/)?[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	// labelValueRegex matches the label values, at most 63 characters
+	labelValueRegex = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+)
+
+// maxAnnotationsSize bounds the total size of the annotations of a resource
+const maxAnnotationsSize = 256 * 1024
+
+func validateMetadataKey(kind string, key string) error {
+	if len(key) > 253 || !metadataKeyRegex.MatchString(key) {
+		msg := fmt.Sprintf("%s key (%s) does not match pattern", kind, key)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	return nil
+}
+
+func validateLabel(key string, value string) error {
+	if err := validateMetadataKey("Label", key); err != nil {
+		return err
+	}
+	if !labelValueRegex.MatchString(value) {
+		msg := fmt.Sprintf("Label value (%s) of %s does not match pattern", value, key)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	return nil
+}
+
+func validateNvmeResourceMetadata(meta *mb.NvmeResourceMetadata) error {
+	for key, value := range meta.Labels {
+		if err := validateLabel(key, value); err != nil {
+			return err
+		}
+	}
+	size := 0
+	for key, value := range meta.Annotations {
+		if err := validateMetadataKey("Annotation", key); err != nil {
+			return err
+		}
+		size += len(key) + len(value)
+	}
+	if size > maxAnnotationsSize {
+		msg := fmt.Sprintf("Annotations size (%d) is too big, have to be at most %d", size, maxAnnotationsSize)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	return nil
+}
+
+func (s *Server) validateGetNvmeResourceMetadataRequest(in *mb.GetNvmeResourceMetadataRequest) error {
+	// check required fields
+	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+		return err
+	}
+	// Validate that a resource name conforms to the restrictions outlined in AIP-122.
+	return resourcename.Validate(in.Name)
+}
+
+func (s *Server) validateUpdateNvmeResourceMetadataRequest(in *mb.UpdateNvmeResourceMetadataRequest) error {
+	// check required fields
+	if err := fieldbehavior.ValidateRequiredFields(in); err != nil {
+		return err
+	}
+	// Validate that a resource name conforms to the restrictions outlined in AIP-122.
+	if err := resourcename.Validate(in.NvmeResourceMetadata.Name); err != nil {
+		return err
+	}
+	for _, p := range in.GetUpdateMask().GetPaths() {
+		if p != "labels" && p != "annotations" {
+			msg := fmt.Sprintf("UpdateMask path (%s) is not labels or annotations", p)
+			return status.Errorf(codes.InvalidArgument, msg)
+		}
+	}
+	return validateNvmeResourceMetadata(in.NvmeResourceMetadata)
+}
+
+func (s *Server) validateListNvmeResourceMetadataRequest(in *mb.ListNvmeResourceMetadataRequest) error {
+	if in.Parent == "" {
+		return nil
+	}
+	// Validate that a resource name conforms to the restrictions outlined in AIP-122.
+	return resourcename.Validate(in.Parent)
+}
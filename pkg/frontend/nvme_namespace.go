@@ -9,13 +9,12 @@ package frontend
 import (
 	"context"
 	"fmt"
-	"log"
 	"path"
 	"sort"
-	"strconv"
-	"strings"
 
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
 	"github.com/opiproject/opi-marvell-bridge/pkg/models"
 	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
 
@@ -28,6 +27,14 @@ import (
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// shareEnable converts the ShareNamespaces option to the SDK share_enable
+func shareEnable(share bool) int {
+	if share {
+		return 1
+	}
+	return 0
+}
+
 func sortNvmeNamespaces(namespaces []*pb.NvmeNamespace) {
 	sort.Slice(namespaces, func(i int, j int) bool {
 		return namespaces[i].Spec.HostNsid < namespaces[j].Spec.HostNsid
@@ -43,12 +50,44 @@ func (s *Server) CreateNvmeNamespace(ctx context.Context, in *pb.CreateNvmeNames
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.NvmeNamespaceId != "" {
-		log.Printf("client provided the ID of a resource %v, ignoring the name field %v", in.NvmeNamespaceId, in.NvmeNamespace.Name)
+		logger.InfoContext(ctx, "client provided the ID of a resource, ignoring the name field", "id", in.NvmeNamespaceId, "name", in.NvmeNamespace.Name)
 		resourceID = in.NvmeNamespaceId
 	}
 	in.NvmeNamespace.Name = utils.ResourceIDToNamespaceName(
 		utils.GetSubsystemIDFromNvmeName(in.Parent), resourceID,
 	)
+	audit.SetResource(ctx, in.NvmeNamespace.Name)
+	if err := s.checkOwnedByCaller(ctx, in.Parent); err != nil {
+		return nil, err
+	}
+	owner, err := s.subsystemOwner(in.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateNvmeNamespaceNsid(in.Parent, in.NvmeNamespace); err != nil {
+		return nil, err
+	}
+	bdev, err := s.namespaceBdev(ctx, in.NvmeNamespace.Spec)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := requestMetadata(ctx, in.NvmeNamespace.Name)
+	if err != nil {
+		return nil, err
+	}
+	return runOperation(ctx, s, "CreateNvmeNamespace", in.NvmeNamespace.Name, utils.ProtoClone(in.NvmeNamespace),
+		func(ctx context.Context) (*pb.NvmeNamespace, error) {
+			release, err := s.checkQuota(in.NvmeNamespace.Name, owner, tenantUsage{namespaces: 1})
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return s.createNvmeNamespace(ctx, in, meta, bdev)
+		},
+	)
+}
+
+func (s *Server) createNvmeNamespace(ctx context.Context, in *pb.CreateNvmeNamespaceRequest, meta *mb.NvmeResourceMetadata, bdev string) (*pb.NvmeNamespace, error) {
 	// idempotent API when called with same key, should return same object
 	namespace := new(pb.NvmeNamespace)
 	found, err := s.store.Get(in.NvmeNamespace.Name, namespace)
@@ -56,7 +95,7 @@ func (s *Server) CreateNvmeNamespace(ctx context.Context, in *pb.CreateNvmeNames
 		return nil, err
 	}
 	if found {
-		log.Printf("Already existing NvmeNamespace with id %v", in.NvmeNamespace.Name)
+		logger.InfoContext(ctx, "Already existing NvmeNamespace", "name", in.NvmeNamespace.Name)
 		return namespace, nil
 	}
 	// not found, so create a new one
@@ -69,77 +108,171 @@ func (s *Server) CreateNvmeNamespace(ctx context.Context, in *pb.CreateNvmeNames
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Parent)
 		return nil, err
 	}
-	// TODO: do lookup through VolumeId key instead of using it's value
+	// allocate the host NSID, the lowest free one if omitted
+	nsid, err := s.allocateNsid(in.Parent, in.NvmeNamespace.Name, in.NvmeNamespace.Spec.HostNsid)
+	if err != nil {
+		return nil, err
+	}
+	in.NvmeNamespace.Spec.HostNsid = nsid
+	// generate the omitted identifiers, all have to be unique on the DPU
+	if err := s.reserveNamespaceIdentifiers(in.NvmeNamespace.Name, in.NvmeNamespace.Spec); err != nil {
+		s.releaseNsidOrLog(ctx, in.Parent, in.NvmeNamespace.Name, nsid)
+		return nil, err
+	}
+	release := func() {
+		s.releaseNamespaceIdentifiersOrLog(ctx, in.NvmeNamespace.Name, in.NvmeNamespace.Spec)
+		s.releaseNsidOrLog(ctx, in.Parent, in.NvmeNamespace.Name, nsid)
+	}
 	params := models.MrvlNvmSubsysAllocNsParams{
 		Subnqn:      subsys.Spec.Nqn,
 		Nguid:       in.NvmeNamespace.Spec.Nguid,
-		Eui64:       strconv.FormatInt(in.NvmeNamespace.Spec.Eui64, 10),
+		Eui64:       formatEui64(in.NvmeNamespace.Spec.Eui64),
 		UUID:        in.NvmeNamespace.Spec.Uuid,
-		ShareEnable: 1,
-		Bdev:        in.NvmeNamespace.Spec.VolumeNameRef,
+		ShareEnable: shareEnable(s.opts.ShareNamespaces),
+		Bdev:        bdev,
 	}
 	var result models.MrvlNvmSubsysAllocNsResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_subsys_alloc_ns", &params, &result)
 	if err != nil {
+		release()
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not create NS: %s", in.NvmeNamespace.Name)
+		release()
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
-	// Now, attach this new NS to ALL controllers
-	for key := range s.ListHelper {
-		if !strings.HasPrefix(key, subsys.Name+"/nvmeControllers") {
-			continue
-		}
-		c := new(pb.NvmeController)
-		ok, err := s.store.Get(key, c)
-		if err != nil {
-			return nil, err
-		}
-		if !ok {
-			err := status.Errorf(codes.NotFound, "unable to find key %s", key)
+	// the SDK picks the ns_instance_id, which may differ from the host NSID
+	nsInstanceID := result.NsInstanceID
+	if nsInstanceID == 0 {
+		nsInstanceID = int(nsid)
+	}
+	// the SDK namespace is allocated, free it as well on failure
+	rollback := func(attached []*pb.NvmeController) {
+		s.rollbackNvmeNamespaceCreate(ctx, subsys, in.NvmeNamespace, nsInstanceID, attached)
+		release()
+	}
+	if err := s.setNsInstanceID(in.NvmeNamespace, nsInstanceID); err != nil {
+		rollback(nil)
+		return nil, err
+	}
+	// Now, attach this new NS to ALL active controllers
+	controllers, err := s.subsystemControllers(subsys)
+	if err != nil {
+		rollback(nil)
+		return nil, err
+	}
+	controllers = activeControllers(controllers)
+	for i, c := range controllers {
+		if err := checkOperationCanceled(ctx); err != nil {
+			rollback(controllers[:i])
 			return nil, err
 		}
-		if utils.GetSubsystemIDFromNvmeName(c.Name) != utils.GetSubsystemIDFromNvmeName(in.Parent) {
-			continue
-		}
+		s.setOperationProgress(ctx, i, len(controllers))
 		params := models.MrvlNvmCtrlrAttachNsParams{
 			Subnqn:       subsys.Spec.Nqn,
 			CtrlrID:      int(*c.Spec.NvmeControllerId),
-			NsInstanceID: int(in.NvmeNamespace.Spec.HostNsid),
+			NsInstanceID: nsInstanceID,
 		}
 		var result models.MrvlNvmCtrlrAttachNsResult
 		err = s.rpc.Call(ctx, "mrvl_nvm_ctrlr_attach_ns", &params, &result)
 		if err != nil {
+			rollback(controllers[:i])
 			return nil, err
 		}
 		if result.Status != 0 {
 			msg := fmt.Sprintf("Could not attach NS: %s", in.NvmeNamespace.Name)
+			rollback(controllers[:i])
 			return nil, status.Errorf(codes.InvalidArgument, msg)
 		}
 	}
 	response := utils.ProtoClone(in.NvmeNamespace)
-	response.Status = &pb.NvmeNamespaceStatus{
-		State:     pb.NvmeNamespaceStatus_STATE_ENABLED,
-		OperState: pb.NvmeNamespaceStatus_OPER_STATE_ONLINE,
-	}
+	response.Status = namespaceStatus(true)
 	// save object to the database
-	s.ListHelper[in.NvmeNamespace.Name] = false
+	err = s.setResourceMetadata(meta)
+	if err != nil {
+		rollback(controllers)
+		return nil, err
+	}
+	s.addListed(in.NvmeNamespace.Name)
 	err = s.store.Set(in.NvmeNamespace.Name, response)
 	if err != nil {
+		s.deleteListed(in.NvmeNamespace.Name)
+		if err := s.store.Delete(metadataKey(in.NvmeNamespace.Name)); err != nil {
+			logger.ErrorContext(ctx, "Could not delete NS metadata on rollback", "name", in.NvmeNamespace.Name, "error", err)
+		}
+		rollback(controllers)
 		return nil, err
 	}
 	return response, nil
 }
 
+// rollbackNvmeNamespaceCreate undoes a failed or cancelled namespace
+// creation, so that no namespace unknown to the store is left in the SDK
+func (s *Server) rollbackNvmeNamespaceCreate(ctx context.Context, subsys *pb.NvmeSubsystem, namespace *pb.NvmeNamespace, nsInstanceID int, attached []*pb.NvmeController) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	logger.WarnContext(ctx, "Rolling back failed NvmeNamespace creation", "name", namespace.Name)
+	for _, c := range attached {
+		params := models.MrvlNvmCtrlrDetachNsParams{
+			Subnqn:       subsys.Spec.Nqn,
+			CtrlrID:      int(*c.Spec.NvmeControllerId),
+			NsInstanceID: nsInstanceID,
+		}
+		var result models.MrvlNvmCtrlrDetachNsResult
+		err := s.rpc.Call(ctx, "mrvl_nvm_ctrlr_detach_ns", &params, &result)
+		if err != nil || result.Status != 0 {
+			logger.ErrorContext(ctx, "Could not detach NS on rollback", "name", namespace.Name, "controller", c.Name, "error", err, "status", result.Status)
+		}
+	}
+	params := models.MrvlNvmSubsysUnallocNsParams{
+		Subnqn:       subsys.Spec.Nqn,
+		NsInstanceID: nsInstanceID,
+	}
+	var result models.MrvlNvmSubsysUnallocNsResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_subsys_unalloc_ns", &params, &result)
+	if err != nil || result.Status != 0 {
+		logger.ErrorContext(ctx, "Could not delete NS on rollback", "name", namespace.Name, "error", err, "status", result.Status)
+	}
+}
+
+// rollbackNvmeNamespaceDelete re-attaches a namespace to the controllers it was
+// detached from by a cancelled namespace deletion
+func (s *Server) rollbackNvmeNamespaceDelete(ctx context.Context, subsys *pb.NvmeSubsystem, namespace *pb.NvmeNamespace, nsInstanceID int, detached []*pb.NvmeController) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	logger.WarnContext(ctx, "Rolling back failed NvmeNamespace deletion", "name", namespace.Name)
+	for _, c := range detached {
+		params := models.MrvlNvmCtrlrAttachNsParams{
+			Subnqn:       subsys.Spec.Nqn,
+			CtrlrID:      int(*c.Spec.NvmeControllerId),
+			NsInstanceID: nsInstanceID,
+		}
+		var result models.MrvlNvmCtrlrAttachNsResult
+		err := s.rpc.Call(ctx, "mrvl_nvm_ctrlr_attach_ns", &params, &result)
+		if err != nil || result.Status != 0 {
+			logger.ErrorContext(ctx, "Could not attach NS on rollback", "name", namespace.Name, "controller", c.Name, "error", err, "status", result.Status)
+		}
+	}
+}
+
 // DeleteNvmeNamespace deletes an Nvme namespace
 func (s *Server) DeleteNvmeNamespace(ctx context.Context, in *pb.DeleteNvmeNamespaceRequest) (*emptypb.Empty, error) {
 	// check input correctness
 	if err := s.validateDeleteNvmeNamespaceRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	return runOperation(ctx, s, "DeleteNvmeNamespace", in.Name, &emptypb.Empty{},
+		func(ctx context.Context) (*emptypb.Empty, error) {
+			return s.deleteNvmeNamespace(ctx, in)
+		},
+	)
+}
+
+func (s *Server) deleteNvmeNamespace(ctx context.Context, in *pb.DeleteNvmeNamespaceRequest) (*emptypb.Empty, error) {
 	// fetch object from the database
 	namespace := new(pb.NvmeNamespace)
 	found, err := s.store.Get(in.Name, namespace)
@@ -166,68 +299,96 @@ func (s *Server) DeleteNvmeNamespace(ctx context.Context, in *pb.DeleteNvmeNames
 		err := status.Errorf(codes.NotFound, "unable to find subsystem %s", subsysName)
 		return nil, err
 	}
-	// First, detach this NS from ALL controllers
-	for key := range s.ListHelper {
-		if !strings.HasPrefix(key, subsys.Name+"/nvmeControllers") {
-			continue
-		}
-		c := new(pb.NvmeController)
-		ok, err := s.store.Get(key, c)
-		if err != nil {
-			return nil, err
-		}
-		if !ok {
-			err := status.Errorf(codes.NotFound, "unable to find key %s", key)
+	nsInstanceID, err := s.nsInstanceID(namespace)
+	if err != nil {
+		return nil, err
+	}
+	// First, detach this NS from ALL controllers it is attached to
+	controllers, err := s.subsystemControllers(subsys)
+	if err != nil {
+		return nil, err
+	}
+	controllers = activeControllers(controllers)
+	if !namespaceEnabled(namespace) {
+		controllers = nil
+	}
+	for i, c := range controllers {
+		if err := checkOperationCanceled(ctx); err != nil {
+			s.rollbackNvmeNamespaceDelete(ctx, subsys, namespace, nsInstanceID, controllers[:i])
 			return nil, err
 		}
-		if utils.GetSubsystemIDFromNvmeName(c.Name) != utils.GetSubsystemIDFromNvmeName(in.Name) {
-			continue
-		}
+		s.setOperationProgress(ctx, i, len(controllers))
 		params := models.MrvlNvmCtrlrDetachNsParams{
 			Subnqn:       subsys.Spec.Nqn,
 			CtrlrID:      int(*c.Spec.NvmeControllerId),
-			NsInstanceID: int(namespace.Spec.HostNsid),
+			NsInstanceID: nsInstanceID,
 		}
 		var result models.MrvlNvmCtrlrDetachNsResult
 		err = s.rpc.Call(ctx, "mrvl_nvm_ctrlr_detach_ns", &params, &result)
 		if err != nil {
+			s.rollbackNvmeNamespaceDelete(ctx, subsys, namespace, nsInstanceID, controllers[:i])
 			return nil, err
 		}
-		log.Printf("Received from SPDK: %v", result)
 		if result.Status != 0 {
 			msg := fmt.Sprintf("Could not detach NS: %s", in.Name)
+			s.rollbackNvmeNamespaceDelete(ctx, subsys, namespace, nsInstanceID, controllers[:i])
 			return nil, status.Errorf(codes.InvalidArgument, msg)
 		}
 	}
 	params := models.MrvlNvmSubsysUnallocNsParams{
 		Subnqn:       subsys.Spec.Nqn,
-		NsInstanceID: int(namespace.Spec.HostNsid),
+		NsInstanceID: nsInstanceID,
 	}
 	var result models.MrvlNvmSubsysUnallocNsResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_subsys_unalloc_ns", &params, &result)
 	if err != nil {
+		s.rollbackNvmeNamespaceDelete(ctx, subsys, namespace, nsInstanceID, controllers)
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not delete NS: %s", in.Name)
+		s.rollbackNvmeNamespaceDelete(ctx, subsys, namespace, nsInstanceID, controllers)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
 	// remove from the Database
-	delete(s.ListHelper, namespace.Name)
+	s.deleteListed(namespace.Name)
 	err = s.store.Delete(namespace.Name)
 	if err != nil {
 		return nil, err
 	}
+	err = s.store.Delete(metadataKey(namespace.Name))
+	if err != nil {
+		return nil, err
+	}
+	err = s.releaseNamespaceIdentifiers(namespace.Name, namespace.Spec)
+	if err != nil {
+		return nil, err
+	}
+	err = s.releaseNsid(subsysName, namespace.Name, namespace.Spec.HostNsid)
+	if err != nil {
+		return nil, err
+	}
 	return &emptypb.Empty{}, nil
 }
 
 // UpdateNvmeNamespace updates an Nvme namespace
-func (s *Server) UpdateNvmeNamespace(_ context.Context, in *pb.UpdateNvmeNamespaceRequest) (*pb.NvmeNamespace, error) {
+func (s *Server) UpdateNvmeNamespace(ctx context.Context, in *pb.UpdateNvmeNamespaceRequest) (*pb.NvmeNamespace, error) {
 	// check input correctness
 	if err := s.validateUpdateNvmeNamespaceRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.NvmeNamespace.Name); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.NvmeNamespace.Name)
+	return runOperation(ctx, s, "UpdateNvmeNamespace", in.NvmeNamespace.Name, utils.ProtoClone(in.NvmeNamespace),
+		func(ctx context.Context) (*pb.NvmeNamespace, error) {
+			return s.updateNvmeNamespace(ctx, in)
+		},
+	)
+}
+
+func (s *Server) updateNvmeNamespace(ctx context.Context, in *pb.UpdateNvmeNamespaceRequest) (*pb.NvmeNamespace, error) {
 	// fetch object from the database
 	namespace := new(pb.NvmeNamespace)
 	found, err := s.store.Get(in.NvmeNamespace.Name, namespace)
@@ -236,7 +397,7 @@ func (s *Server) UpdateNvmeNamespace(_ context.Context, in *pb.UpdateNvmeNamespa
 	}
 	if !found {
 		if in.AllowMissing {
-			log.Printf("TODO: in case of AllowMissing, create a new resource, don;t return error")
+			logger.DebugContext(ctx, "TODO: in case of AllowMissing, create a new resource, don;t return error")
 		}
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.NvmeNamespace.Name)
 		return nil, err
@@ -246,7 +407,7 @@ func (s *Server) UpdateNvmeNamespace(_ context.Context, in *pb.UpdateNvmeNamespa
 	if err := fieldmask.Validate(in.UpdateMask, in.NvmeNamespace); err != nil {
 		return nil, err
 	}
-	log.Printf("TODO: use resourceID=%v", resourceID)
+	logger.DebugContext(ctx, "TODO: use resourceID", "resource_id", resourceID)
 	return nil, status.Errorf(codes.Unimplemented, "UpdateNvmeNamespace method is not implemented")
 }
 
@@ -257,10 +418,17 @@ func (s *Server) ListNvmeNamespaces(ctx context.Context, in *pb.ListNvmeNamespac
 		return nil, err
 	}
 	// fetch object from the database
-	size, offset, perr := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	size, offset, perr := s.extractPagination(in.PageSize, in.PageToken)
 	if perr != nil {
 		return nil, perr
 	}
+	selector, err := labelSelectorOf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Parent); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.Parent, subsys)
@@ -279,22 +447,50 @@ func (s *Server) ListNvmeNamespaces(ctx context.Context, in *pb.ListNvmeNamespac
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not list NS: %s", in.Parent)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
+	nsids, err := s.subsystemHostNsids(in.Parent)
+	if err != nil {
+		return nil, err
+	}
+	if selector != nil {
+		selected, err := s.selectedNamespaceIDs(selector, in.Parent)
+		if err != nil {
+			return nil, err
+		}
+		nsList := result.NsList[:0]
+		for _, r := range result.NsList {
+			if selected[int(hostNsid(nsids, r.NsInstanceID))] {
+				nsList = append(nsList, r)
+			}
+		}
+		result.NsList = nsList
+	}
 	token, hasMoreElements := "", false
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(result.NsList), offset, size)
+	logger.DebugContext(ctx, "Limiting result", "len", len(result.NsList), "offset", offset, "size", size)
 	result.NsList, hasMoreElements = utils.LimitPagination(result.NsList, offset, size)
 	if hasMoreElements {
 		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+		s.setPageToken(token, offset+size)
+	}
+	// the SDK does not report the state of the namespaces, the store does
+	namespaces, err := s.subsystemNamespaces(subsys)
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[int32]*pb.NvmeNamespace, len(namespaces))
+	for _, namespace := range namespaces {
+		stored[namespace.Spec.GetHostNsid()] = namespace
 	}
 	Blobarray := make([]*pb.NvmeNamespace, len(result.NsList))
 	for i := range result.NsList {
 		r := &result.NsList[i]
-		Blobarray[i] = &pb.NvmeNamespace{Spec: &pb.NvmeNamespaceSpec{HostNsid: int32(r.NsInstanceID)}}
+		Blobarray[i] = &pb.NvmeNamespace{Spec: &pb.NvmeNamespaceSpec{HostNsid: hostNsid(nsids, r.NsInstanceID)}}
+		if namespace, ok := stored[Blobarray[i].Spec.HostNsid]; ok {
+			Blobarray[i].Status = namespaceStatus(namespaceEnabled(namespace))
+		}
 	}
 	sortNvmeNamespaces(Blobarray)
 	return &pb.ListNvmeNamespacesResponse{NvmeNamespaces: Blobarray}, nil
@@ -306,6 +502,9 @@ func (s *Server) GetNvmeNamespace(ctx context.Context, in *pb.GetNvmeNamespaceRe
 	if err := s.validateGetNvmeNamespaceRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	namespace := new(pb.NvmeNamespace)
 	found, err := s.store.Get(in.Name, namespace)
@@ -316,7 +515,7 @@ func (s *Server) GetNvmeNamespace(ctx context.Context, in *pb.GetNvmeNamespaceRe
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
 		return nil, err
 	}
-	log.Printf("namespace: %v", namespace)
+	logger.DebugContext(ctx, "Found namespace", "namespace", namespace)
 	subsysName := utils.ResourceIDToSubsystemName(
 		utils.GetSubsystemIDFromNvmeName(in.Name),
 	)
@@ -331,28 +530,33 @@ func (s *Server) GetNvmeNamespace(ctx context.Context, in *pb.GetNvmeNamespaceRe
 		return nil, err
 	}
 
+	nsInstanceID, err := s.nsInstanceID(namespace)
+	if err != nil {
+		return nil, err
+	}
 	params := models.MrvlNvmGetNsInfoParams{
 		SubNqn:       subsys.Spec.Nqn,
-		NsInstanceID: int(namespace.Spec.HostNsid),
+		NsInstanceID: nsInstanceID,
 	}
 	var result models.MrvlNvmGetNsInfoResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_ns_get_info", &params, &result)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not get NS: %s", in.Name)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
+	meta, err := s.resourceMetadata(in.Name)
+	if err != nil {
+		return nil, err
+	}
+	sendMetadataHeader(ctx, meta)
 	return &pb.NvmeNamespace{Name: in.Name,
 		Spec: &pb.NvmeNamespaceSpec{
 			Nguid: result.Nguid,
 		},
-		Status: &pb.NvmeNamespaceStatus{
-			State:     pb.NvmeNamespaceStatus_STATE_ENABLED,
-			OperState: pb.NvmeNamespaceStatus_OPER_STATE_ONLINE,
-		},
+		Status: namespaceStatus(namespaceEnabled(namespace)),
 	}, nil
 }
 
@@ -362,6 +566,9 @@ func (s *Server) StatsNvmeNamespace(ctx context.Context, in *pb.StatsNvmeNamespa
 	if err := s.validateStatsNvmeNamespaceRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	namespace := new(pb.NvmeNamespace)
 	found, err := s.store.Get(in.Name, namespace)
@@ -386,16 +593,19 @@ func (s *Server) StatsNvmeNamespace(ctx context.Context, in *pb.StatsNvmeNamespa
 		return nil, err
 	}
 
+	nsInstanceID, err := s.nsInstanceID(namespace)
+	if err != nil {
+		return nil, err
+	}
 	params := models.MrvlNvmGetNsStatsParams{
 		SubNqn:       subsys.Spec.Nqn,
-		NsInstanceID: int(namespace.Spec.HostNsid),
+		NsInstanceID: nsInstanceID,
 	}
 	var result models.MrvlNvmGetNsStatsResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_get_ns_stats", &params, &result)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not stats NS: %s", in.Name)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
@@ -7,10 +7,15 @@
 package frontend
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"testing"
 
+	"go.opentelemetry.io/otel/metric/noop"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
@@ -144,8 +149,12 @@ func TestFrontEnd_CreateNvmeNamespace(t *testing.T) {
 				Name: testNamespaceName,
 				Spec: spec,
 			},
-			out:     nil,
-			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 17}}`, `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`},
+			out: nil,
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0, "ns_instance_id": 17}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+			},
 			errCode: codes.InvalidArgument,
 			errMsg:  fmt.Sprintf("Could not attach NS: %v", testNamespaceName),
 			exist:   false,
@@ -276,7 +285,7 @@ func TestFrontEnd_DeleteNvmeNamespace(t *testing.T) {
 		"valid request with invalid SPDK second response": {
 			in:      testNamespaceName,
 			out:     nil,
-			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`, `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`, `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`, `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`},
 			errCode: codes.InvalidArgument,
 			errMsg:  fmt.Sprintf("Could not delete NS: %v", testNamespaceName),
 			missing: false,
@@ -378,6 +387,85 @@ func TestFrontEnd_DeleteNvmeNamespace(t *testing.T) {
 	}
 }
 
+func TestFrontEnd_DeleteNvmeNamespaceRollback(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	done := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	failed := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`
+	broken := `{"id":%d,"error":{"code":1,"message":"myopierr"},"result":{"status": 1}}`
+	tests := map[string]struct {
+		spdk    []string
+		errCode codes.Code
+		errMsg  string
+		methods []string
+	}{
+		"second detach failed": {
+			spdk:    []string{done, failed, done},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not detach NS: %v", testNamespaceName),
+			methods: []string{"mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_attach_ns"},
+		},
+		"second detach error": {
+			spdk:    []string{done, broken, done},
+			errCode: codes.Unknown,
+			errMsg:  fmt.Sprintf("mrvl_nvm_ctrlr_detach_ns: %v", "json response error: myopierr"),
+			methods: []string{"mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_attach_ns"},
+		},
+		"unalloc failed": {
+			spdk:    []string{done, done, failed, done, done},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not delete NS: %v", testNamespaceName),
+			methods: []string{"mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_subsys_unalloc_ns", "mrvl_nvm_ctrlr_attach_ns", "mrvl_nvm_ctrlr_attach_ns"},
+		},
+		"unalloc error": {
+			spdk:    []string{done, done, broken, done, done},
+			errCode: codes.Unknown,
+			errMsg:  fmt.Sprintf("mrvl_nvm_subsys_unalloc_ns: %v", "json response error: myopierr"),
+			methods: []string{"mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_subsys_unalloc_ns", "mrvl_nvm_ctrlr_attach_ns", "mrvl_nvm_ctrlr_attach_ns"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			other := utils.ProtoClone(&testControllerWithStatus)
+			other.Name = utils.ResourceIDToControllerName(testSubsystemID, "controller-other")
+			other.Spec.NvmeControllerId = proto.Int32(18)
+			for _, c := range []*pb.NvmeController{&testControllerWithStatus, other} {
+				if err := s.store.Set(c.Name, c); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[c.Name] = false
+			}
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testNamespaceName, &testNamespaceWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			rpc := &recordingJSONRPC{JSONRPC: s.rpc}
+			s.rpc = rpc
+
+			_, err := testEnv.client.DeleteNvmeNamespace(testEnv.ctx, &pb.DeleteNvmeNamespaceRequest{Name: testNamespaceName})
+
+			checkTenantError(t, err, tt.errCode, tt.errMsg)
+			if !reflect.DeepEqual(rpc.methods, tt.methods) {
+				t.Error("methods: expected", tt.methods, "received", rpc.methods)
+			}
+			found, err := s.store.Get(testNamespaceName, new(pb.NvmeNamespace))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("expected the namespace to be kept")
+			}
+		})
+	}
+}
+
 func TestFrontEnd_UpdateNvmeNamespace(t *testing.T) {
 	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
 	tests := map[string]struct {
@@ -893,3 +981,71 @@ func TestFrontEnd_StatsNvmeNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestFrontEnd_NvmeNamespaceRollback(t *testing.T) {
+	tests := map[string]struct {
+		spdk  []string
+		call  func(ctx context.Context, s *Server) error
+		calls []string
+		exist bool
+	}{
+		"cancelled create": {
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+			},
+			call: func(ctx context.Context, s *Server) error {
+				namespace := utils.ProtoClone(&testNamespace)
+				namespace.Name = testNamespaceName
+				_, err := s.createNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: namespace}, nil, namespace.Spec.VolumeNameRef)
+				return err
+			},
+			calls: []string{"mrvl_nvm_subsys_alloc_ns", "mrvl_nvm_subsys_unalloc_ns"},
+			exist: false,
+		},
+		"cancelled delete": {
+			spdk: []string{},
+			call: func(ctx context.Context, s *Server) error {
+				_ = s.store.Set(testNamespaceName, &testNamespaceWithStatus)
+				_, err := s.deleteNvmeNamespace(ctx, &pb.DeleteNvmeNamespaceRequest{Name: testNamespaceName})
+				return err
+			},
+			calls: []string{},
+			exist: true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+
+			recorder := tracetest.NewSpanRecorder()
+			testEnv.opiSpdkServer.rpc = newTracedJSONRPC(testEnv.jsonRPC,
+				sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)), noop.NewMeterProvider())
+			testEnv.opiSpdkServer.ListHelper[testControllerName] = false
+			_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystemWithStatus)
+			_ = testEnv.opiSpdkServer.store.Set(testControllerName, &testControllerWithStatus)
+
+			ctx, cancel := context.WithCancel(testEnv.ctx)
+			cancel()
+			err := tt.call(ctx, testEnv.opiSpdkServer)
+			if status.Code(err) != codes.Canceled {
+				t.Error("error code: expected", codes.Canceled, "received", err)
+			}
+
+			calls := []string{}
+			for _, span := range recorder.Ended() {
+				calls = append(calls, span.Name())
+			}
+			if !reflect.DeepEqual(calls, tt.calls) {
+				t.Error("SDK calls: expected", tt.calls, "received", calls)
+			}
+			exist, _ := testEnv.opiSpdkServer.store.Get(testNamespaceName, new(pb.NvmeNamespace))
+			if exist != tt.exist {
+				t.Error("namespace exists: expected", tt.exist, "received", exist)
+			}
+		})
+	}
+}
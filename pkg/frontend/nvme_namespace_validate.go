@@ -5,6 +5,8 @@
 package frontend
 
 import (
+	"fmt"
+
 	"go.einride.tech/aip/fieldbehavior"
 	"go.einride.tech/aip/resourceid"
 	"go.einride.tech/aip/resourcename"
@@ -24,7 +26,49 @@ func (s *Server) validateCreateNvmeNamespaceRequest(in *pb.CreateNvmeNamespaceRe
 		}
 	}
 	// Validate that a resource name conforms to the restrictions outlined in AIP-122.
-	return resourcename.Validate(in.Parent)
+	if err := resourcename.Validate(in.Parent); err != nil {
+		return err
+	}
+	return validateNvmeNamespaceSpec(in.NvmeNamespace.Spec)
+}
+
+// validateNvmeNamespaceSpec checks the identifiers of a namespace against
+// the NVMe spec, the Eui64 being 8 bytes by its type
+func validateNvmeNamespaceSpec(spec *pb.NvmeNamespaceSpec) error {
+	if spec.HostNsid < 0 {
+		msg := fmt.Sprintf("HostNsid value (%d) must not be negative", spec.HostNsid)
+		return invalidFieldError("nvme_namespace.spec.host_nsid", msg)
+	}
+	if spec.Nguid != "" {
+		if err := validateNguid("nvme_namespace.spec.nguid", spec.Nguid); err != nil {
+			return err
+		}
+	}
+	if spec.Uuid != "" {
+		if err := validateUUID("nvme_namespace.spec.uuid", spec.Uuid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNvmeNamespaceNsid checks that the HostNsid of the namespace is
+// within the MaxNamespaces of its subsystem
+func (s *Server) validateNvmeNamespaceNsid(subsysName string, namespace *pb.NvmeNamespace) error {
+	nsid := namespace.Spec.HostNsid
+	if nsid == 0 {
+		return nil
+	}
+	subsys := new(pb.NvmeSubsystem)
+	found, err := s.store.Get(subsysName, subsys)
+	if err != nil || !found {
+		return err
+	}
+	if limit := subsys.Spec.MaxNamespaces; limit > 0 && int64(nsid) > limit {
+		msg := fmt.Sprintf("HostNsid value (%d) is out of range, have to be between 1 and %d", nsid, limit)
+		return invalidFieldError("nvme_namespace.spec.host_nsid", msg)
+	}
+	return nil
 }
 
 func (s *Server) validateDeleteNvmeNamespaceRequest(in *pb.DeleteNvmeNamespaceRequest) error {
@@ -0,0 +1,323 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// controllerActive reports whether the enabled namespaces are attached to
+// a controller, as for the controllers stored without status
+func controllerActive(controller *pb.NvmeController) bool {
+	return controller.GetStatus() == nil || controller.GetStatus().GetActive()
+}
+
+// namespaceEnabled reports whether a namespace is attached to the active
+// controllers
+func namespaceEnabled(namespace *pb.NvmeNamespace) bool {
+	return namespace.GetStatus().GetState() != pb.NvmeNamespaceStatus_STATE_DISABLED
+}
+
+// namespaceStatus returns the status of an enabled or disabled namespace
+func namespaceStatus(enabled bool) *pb.NvmeNamespaceStatus {
+	if !enabled {
+		return &pb.NvmeNamespaceStatus{
+			State:     pb.NvmeNamespaceStatus_STATE_DISABLED,
+			OperState: pb.NvmeNamespaceStatus_OPER_STATE_OFFLINE,
+		}
+	}
+	return &pb.NvmeNamespaceStatus{
+		State:     pb.NvmeNamespaceStatus_STATE_ENABLED,
+		OperState: pb.NvmeNamespaceStatus_OPER_STATE_ONLINE,
+	}
+}
+
+// activeControllers returns the active controllers among controllers
+func activeControllers(controllers []*pb.NvmeController) []*pb.NvmeController {
+	active := []*pb.NvmeController{}
+	for _, c := range controllers {
+		if controllerActive(c) {
+			active = append(active, c)
+		}
+	}
+	return active
+}
+
+// attachment is a namespace attached to a controller in the SDK
+type attachment struct {
+	controller   *pb.NvmeController
+	namespace    *pb.NvmeNamespace
+	nsInstanceID int
+}
+
+// setAttached attaches or detaches the namespaces to or from the
+// controllers, and undoes the done ones if any fails
+func (s *Server) setAttached(ctx context.Context, subsys *pb.NvmeSubsystem, attachments []attachment, attached bool) error {
+	for i, a := range attachments {
+		if err := s.setNamespaceAttached(ctx, subsys, a, attached); err != nil {
+			// the rollback must not be cancelled as well
+			ctx := context.WithoutCancel(ctx)
+			for _, done := range attachments[:i] {
+				if err := s.setNamespaceAttached(ctx, subsys, done, !attached); err != nil {
+					logger.ErrorContext(ctx, "Could not restore NS attachment on rollback", "name", done.namespace.Name, "controller", done.controller.Name, "error", err)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// enabledNamespaceAttachments returns the attachments of the enabled
+// namespaces of the subsystem to an active controller
+func (s *Server) enabledNamespaceAttachments(subsys *pb.NvmeSubsystem, controller *pb.NvmeController) ([]attachment, error) {
+	namespaces, err := s.subsystemNamespaces(subsys)
+	if err != nil {
+		return nil, err
+	}
+	attachments := []attachment{}
+	for _, namespace := range namespaces {
+		if !namespaceEnabled(namespace) {
+			continue
+		}
+		nsInstanceID, err := s.nsInstanceID(namespace)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, attachment{controller: controller, namespace: namespace, nsInstanceID: nsInstanceID})
+	}
+	return attachments, nil
+}
+
+// setNamespaceAttached attaches or detaches a namespace to or from a
+// controller
+func (s *Server) setNamespaceAttached(ctx context.Context, subsys *pb.NvmeSubsystem, a attachment, attached bool) error {
+	if attached {
+		params := models.MrvlNvmCtrlrAttachNsParams{
+			Subnqn:       subsys.Spec.Nqn,
+			CtrlrID:      int(a.controller.Spec.GetNvmeControllerId()),
+			NsInstanceID: a.nsInstanceID,
+		}
+		var result models.MrvlNvmCtrlrAttachNsResult
+		err := s.rpc.Call(ctx, "mrvl_nvm_ctrlr_attach_ns", &params, &result)
+		if err != nil {
+			return err
+		}
+		if result.Status != 0 {
+			msg := fmt.Sprintf("Could not attach NS: %s", a.namespace.Name)
+			return status.Errorf(codes.InvalidArgument, msg)
+		}
+		return nil
+	}
+	params := models.MrvlNvmCtrlrDetachNsParams{
+		Subnqn:       subsys.Spec.Nqn,
+		CtrlrID:      int(a.controller.Spec.GetNvmeControllerId()),
+		NsInstanceID: a.nsInstanceID,
+	}
+	var result models.MrvlNvmCtrlrDetachNsResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_ctrlr_detach_ns", &params, &result)
+	if err != nil {
+		return err
+	}
+	if result.Status != 0 {
+		msg := fmt.Sprintf("Could not detach NS: %s", a.namespace.Name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	return nil
+}
+
+// EnableNvmeController attaches the enabled namespaces of the subsystem to
+// an Nvme controller
+func (s *Server) EnableNvmeController(ctx context.Context, in *mb.EnableNvmeControllerRequest) (*pb.NvmeController, error) {
+	// check input correctness
+	if err := s.validateEnableNvmeControllerRequest(in); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.Name)
+	return s.setNvmeControllerActive(ctx, in.Name, true, removalForce)
+}
+
+// DisableNvmeController detaches all namespaces from an Nvme controller,
+// which keeps its ID and PCIe function, and quiesces it following the
+// removal policy
+func (s *Server) DisableNvmeController(ctx context.Context, in *mb.DisableNvmeControllerRequest) (*pb.NvmeController, error) {
+	// check input correctness
+	if err := s.validateDisableNvmeControllerRequest(in); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	policy, err := s.requestRemovalPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.Name)
+	return s.setNvmeControllerActive(ctx, in.Name, false, policy)
+}
+
+// EnableNvmeNamespace attaches an Nvme namespace to the active controllers
+// of the subsystem
+func (s *Server) EnableNvmeNamespace(ctx context.Context, in *mb.EnableNvmeNamespaceRequest) (*pb.NvmeNamespace, error) {
+	// check input correctness
+	if err := s.validateEnableNvmeNamespaceRequest(in); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.Name)
+	return s.setNvmeNamespaceEnabled(ctx, in.Name, true)
+}
+
+// DisableNvmeNamespace detaches an Nvme namespace from all controllers,
+// which keeps its host NSID and identifiers
+func (s *Server) DisableNvmeNamespace(ctx context.Context, in *mb.DisableNvmeNamespaceRequest) (*pb.NvmeNamespace, error) {
+	// check input correctness
+	if err := s.validateDisableNvmeNamespaceRequest(in); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.Name)
+	return s.setNvmeNamespaceEnabled(ctx, in.Name, false)
+}
+
+// quiesceNvmeController waits for the host to release the queues of a
+// controller whose namespaces are detached, following the removal policy
+func (s *Server) quiesceNvmeController(ctx context.Context, subsys *pb.NvmeSubsystem, controller *pb.NvmeController, policy removalPolicy) error {
+	if policy == removalForce {
+		return nil
+	}
+	released, nsq, ncq, err := s.waitQueuesReleased(ctx, subsys, controller, func() (bool, error) {
+		return true, nil
+	})
+	if err != nil || released {
+		return err
+	}
+	if policy == removalGracefulThenForce {
+		logger.WarnContext(ctx, "Host did not release the controller in time, disabling it anyway",
+			"name", controller.Name, "active_nsq", nsq, "active_ncq", ncq)
+		return nil
+	}
+	return s.queuesKeptError(controller, nsq, ncq)
+}
+
+// undoAttached undoes setAttached once the new state cannot be saved, only
+// logging the failures
+func (s *Server) undoAttached(ctx context.Context, subsys *pb.NvmeSubsystem, attachments []attachment, attached bool) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	if err := s.setAttached(ctx, subsys, attachments, !attached); err != nil {
+		logger.ErrorContext(ctx, "Could not restore NS attachments on rollback", "subsystem", subsys.Name, "error", err)
+	}
+}
+
+func (s *Server) setNvmeControllerActive(ctx context.Context, name string, active bool, policy removalPolicy) (*pb.NvmeController, error) {
+	// fetch object from the database
+	controller := new(pb.NvmeController)
+	found, err := s.store.Get(name, controller)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", name)
+		return nil, err
+	}
+	if controllerActive(controller) == active {
+		return controller, nil
+	}
+	subsys := new(pb.NvmeSubsystem)
+	found, err = s.store.Get(subsystemNameOf(name), subsys)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", subsystemNameOf(name))
+		return nil, err
+	}
+	attachments, err := s.enabledNamespaceAttachments(subsys, controller)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setAttached(ctx, subsys, attachments, active); err != nil {
+		return nil, err
+	}
+	if !active {
+		if err := s.quiesceNvmeController(ctx, subsys, controller, policy); err != nil {
+			s.undoAttached(ctx, subsys, attachments, active)
+			return nil, err
+		}
+	}
+	controller.Status = &pb.NvmeControllerStatus{Active: active}
+	// save object to the database
+	err = s.store.Set(name, controller)
+	if err != nil {
+		s.undoAttached(ctx, subsys, attachments, active)
+		return nil, err
+	}
+	return controller, nil
+}
+
+func (s *Server) setNvmeNamespaceEnabled(ctx context.Context, name string, enabled bool) (*pb.NvmeNamespace, error) {
+	// fetch object from the database
+	namespace := new(pb.NvmeNamespace)
+	found, err := s.store.Get(name, namespace)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", name)
+		return nil, err
+	}
+	if namespaceEnabled(namespace) == enabled {
+		return namespace, nil
+	}
+	subsys := new(pb.NvmeSubsystem)
+	found, err = s.store.Get(subsystemNameOf(name), subsys)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", subsystemNameOf(name))
+		return nil, err
+	}
+	nsInstanceID, err := s.nsInstanceID(namespace)
+	if err != nil {
+		return nil, err
+	}
+	controllers, err := s.subsystemControllers(subsys)
+	if err != nil {
+		return nil, err
+	}
+	attachments := []attachment{}
+	for _, c := range activeControllers(controllers) {
+		attachments = append(attachments, attachment{controller: c, namespace: namespace, nsInstanceID: nsInstanceID})
+	}
+	if err := s.setAttached(ctx, subsys, attachments, enabled); err != nil {
+		return nil, err
+	}
+	namespace.Status = namespaceStatus(enabled)
+	// save object to the database
+	err = s.store.Set(name, namespace)
+	if err != nil {
+		s.undoAttached(ctx, subsys, attachments, enabled)
+		return nil, err
+	}
+	return namespace, nil
+}
@@ -0,0 +1,587 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/philippgille/gokv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/gospdk/spdk"
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_NvmeState(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	done := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	refused := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`
+	otherControllerName := utils.ResourceIDToControllerName(testSubsystemID, "controller-other")
+	tests := map[string]struct {
+		name            string
+		enable          bool
+		active          bool
+		enabled         bool
+		otherController bool
+		spdk            []string
+		errCode         codes.Code
+		errMsg          string
+		wantActive      bool
+		wantEnabled     bool
+	}{
+		"disable namespace": {
+			name:        testNamespaceName,
+			enable:      false,
+			active:      true,
+			enabled:     true,
+			spdk:        []string{done},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  true,
+			wantEnabled: false,
+		},
+		"enable namespace": {
+			name:        testNamespaceName,
+			enable:      true,
+			active:      true,
+			enabled:     false,
+			spdk:        []string{done},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  true,
+			wantEnabled: true,
+		},
+		"disable disabled namespace": {
+			name:        testNamespaceName,
+			enable:      false,
+			active:      true,
+			enabled:     false,
+			spdk:        []string{},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  true,
+			wantEnabled: false,
+		},
+		"enable namespace on inactive controller": {
+			name:        testNamespaceName,
+			enable:      true,
+			active:      false,
+			enabled:     false,
+			spdk:        []string{},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  false,
+			wantEnabled: true,
+		},
+		"namespace detach refused": {
+			name:        testNamespaceName,
+			enable:      false,
+			active:      true,
+			enabled:     true,
+			spdk:        []string{refused},
+			errCode:     codes.InvalidArgument,
+			errMsg:      fmt.Sprintf("Could not detach NS: %v", testNamespaceName),
+			wantActive:  true,
+			wantEnabled: true,
+		},
+		"namespace attach rolled back": {
+			name:            testNamespaceName,
+			enable:          true,
+			active:          true,
+			enabled:         false,
+			otherController: true,
+			spdk:            []string{done, refused, done},
+			errCode:         codes.InvalidArgument,
+			errMsg:          fmt.Sprintf("Could not attach NS: %v", testNamespaceName),
+			wantActive:      true,
+			wantEnabled:     false,
+		},
+		"disable controller": {
+			name:        testControllerName,
+			enable:      false,
+			active:      true,
+			enabled:     true,
+			spdk:        []string{done},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  false,
+			wantEnabled: true,
+		},
+		"enable controller": {
+			name:        testControllerName,
+			enable:      true,
+			active:      false,
+			enabled:     true,
+			spdk:        []string{done},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  true,
+			wantEnabled: true,
+		},
+		"enable active controller": {
+			name:        testControllerName,
+			enable:      true,
+			active:      true,
+			enabled:     true,
+			spdk:        []string{},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  true,
+			wantEnabled: true,
+		},
+		"disable controller without enabled namespaces": {
+			name:        testControllerName,
+			enable:      false,
+			active:      true,
+			enabled:     false,
+			spdk:        []string{},
+			errCode:     codes.OK,
+			errMsg:      "",
+			wantActive:  false,
+			wantEnabled: false,
+		},
+		"controller attach refused": {
+			name:        testControllerName,
+			enable:      true,
+			active:      false,
+			enabled:     true,
+			spdk:        []string{refused},
+			errCode:     codes.InvalidArgument,
+			errMsg:      fmt.Sprintf("Could not attach NS: %v", testNamespaceName),
+			wantActive:  false,
+			wantEnabled: true,
+		},
+		"unknown controller": {
+			name:        testControllerName + "-unknown",
+			enable:      false,
+			active:      true,
+			enabled:     true,
+			spdk:        []string{},
+			errCode:     codes.NotFound,
+			errMsg:      fmt.Sprintf("unable to find key %v", testControllerName+"-unknown"),
+			wantActive:  true,
+			wantEnabled: true,
+		},
+		"malformed name": {
+			name:        "-ABC-DEF",
+			enable:      true,
+			active:      true,
+			enabled:     true,
+			spdk:        []string{},
+			errCode:     codes.Unknown,
+			errMsg:      fmt.Sprintf("segment '%s': not a valid DNS name", "-ABC-DEF"),
+			wantActive:  true,
+			wantEnabled: true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			controller := utils.ProtoClone(&testControllerWithStatus)
+			controller.Status.Active = tt.active
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Status = namespaceStatus(tt.enabled)
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, controller); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+			s.ListHelper[testNamespaceName] = false
+			if tt.otherController {
+				other := utils.ProtoClone(&testControllerWithStatus)
+				other.Name = otherControllerName
+				other.Spec.NvmeControllerId = proto.Int32(18)
+				if err := s.store.Set(otherControllerName, other); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[otherControllerName] = false
+			}
+
+			var err error
+			switch {
+			case tt.name == testNamespaceName && tt.enable:
+				_, err = testEnv.client.EnableNvmeNamespace(testEnv.ctx, &mb.EnableNvmeNamespaceRequest{Name: tt.name})
+			case tt.name == testNamespaceName:
+				_, err = testEnv.client.DisableNvmeNamespace(testEnv.ctx, &mb.DisableNvmeNamespaceRequest{Name: tt.name})
+			case tt.enable:
+				_, err = testEnv.client.EnableNvmeController(testEnv.ctx, &mb.EnableNvmeControllerRequest{Name: tt.name})
+			default:
+				_, err = testEnv.client.DisableNvmeController(testEnv.ctx, &mb.DisableNvmeControllerRequest{Name: tt.name})
+			}
+
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+			stored := new(pb.NvmeController)
+			if _, err := s.store.Get(testControllerName, stored); err != nil {
+				t.Fatal(err)
+			}
+			if controllerActive(stored) != tt.wantActive {
+				t.Error("controller active: expected", tt.wantActive, "received", controllerActive(stored))
+			}
+			storedNamespace := new(pb.NvmeNamespace)
+			if _, err := s.store.Get(testNamespaceName, storedNamespace); err != nil {
+				t.Fatal(err)
+			}
+			if namespaceEnabled(storedNamespace) != tt.wantEnabled {
+				t.Error("namespace enabled: expected", tt.wantEnabled, "received", namespaceEnabled(storedNamespace))
+			}
+		})
+	}
+}
+
+func TestFrontEnd_NvmeControllerQuiesce(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	done := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	queues := func(nsq, ncq int) string {
+		return fmt.Sprintf(`{"id":%%d,"error":{"code":0,"message":""},"result":{"status": 0, "active_nsq": %d, "active_ncq": %d}}`, nsq, ncq)
+	}
+	tests := map[string]struct {
+		timeout    time.Duration
+		policy     string
+		spdk       []string
+		errCode    codes.Code
+		errMsg     string
+		wantActive bool
+	}{
+		"force without removal timeout": {
+			timeout:    0,
+			policy:     "",
+			spdk:       []string{done},
+			errCode:    codes.OK,
+			errMsg:     "",
+			wantActive: false,
+		},
+		"queues released by the host": {
+			timeout:    time.Second,
+			policy:     "",
+			spdk:       []string{done, queues(2, 2), queues(0, 0)},
+			errCode:    codes.OK,
+			errMsg:     "",
+			wantActive: false,
+		},
+		"queues kept by the host": {
+			timeout:    0,
+			policy:     "graceful",
+			spdk:       []string{done, queues(2, 1), done},
+			errCode:    codes.FailedPrecondition,
+			errMsg:     fmt.Sprintf("%v still has 2 active submission and 1 completion queues after 0s", testControllerName),
+			wantActive: true,
+		},
+		"disabled anyway": {
+			timeout:    0,
+			policy:     "graceful-then-force",
+			spdk:       []string{done, queues(2, 1)},
+			errCode:    codes.OK,
+			errMsg:     "",
+			wantActive: false,
+		},
+		"invalid policy": {
+			timeout:    time.Second,
+			policy:     "later",
+			spdk:       []string{},
+			errCode:    codes.InvalidArgument,
+			errMsg:     "x-opi-removal-policy value (later) is not one of force, graceful or graceful-then-force",
+			wantActive: true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+			s.opts.RemovalTimeout = tt.timeout
+			s.opts.RemovalPollInterval = time.Millisecond
+
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, &testControllerWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testNamespaceName, &testNamespaceWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+			s.ListHelper[testNamespaceName] = false
+
+			ctx := testEnv.ctx
+			if tt.policy != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, removalPolicyKey, tt.policy)
+			}
+			_, err := testEnv.client.DisableNvmeController(ctx, &mb.DisableNvmeControllerRequest{Name: testControllerName})
+
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+			stored := new(pb.NvmeController)
+			if _, err := s.store.Get(testControllerName, stored); err != nil {
+				t.Fatal(err)
+			}
+			if controllerActive(stored) != tt.wantActive {
+				t.Error("controller active: expected", tt.wantActive, "received", controllerActive(stored))
+			}
+		})
+	}
+}
+
+// failingSetStore fails to save the key
+type failingSetStore struct {
+	gokv.Store
+	key string
+}
+
+func (s failingSetStore) Set(k string, v interface{}) error {
+	if k == s.key {
+		return errors.New("store unavailable")
+	}
+	return s.Store.Set(k, v)
+}
+
+// recordingJSONRPC records the methods called and their parameters
+type recordingJSONRPC struct {
+	spdk.JSONRPC
+	mutex   sync.Mutex
+	methods []string
+	params  []interface{}
+}
+
+func (r *recordingJSONRPC) Call(ctx context.Context, method string, args, result interface{}) error {
+	r.mutex.Lock()
+	r.methods = append(r.methods, method)
+	r.params = append(r.params, args)
+	r.mutex.Unlock()
+	return r.JSONRPC.Call(ctx, method, args, result)
+}
+
+func TestFrontEnd_NvmeStateNotSaved(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	done := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	tests := map[string]struct {
+		name    string
+		enable  bool
+		active  bool
+		enabled bool
+		methods []string
+	}{
+		"disabled namespace attached back": {
+			name:    testNamespaceName,
+			enable:  false,
+			active:  true,
+			enabled: true,
+			methods: []string{"mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_attach_ns"},
+		},
+		"enabled namespace detached back": {
+			name:    testNamespaceName,
+			enable:  true,
+			active:  true,
+			enabled: false,
+			methods: []string{"mrvl_nvm_ctrlr_attach_ns", "mrvl_nvm_ctrlr_detach_ns"},
+		},
+		"disabled controller attached back": {
+			name:    testControllerName,
+			enable:  false,
+			active:  true,
+			enabled: true,
+			methods: []string{"mrvl_nvm_ctrlr_detach_ns", "mrvl_nvm_ctrlr_attach_ns"},
+		},
+		"enabled controller detached back": {
+			name:    testControllerName,
+			enable:  true,
+			active:  false,
+			enabled: true,
+			methods: []string{"mrvl_nvm_ctrlr_attach_ns", "mrvl_nvm_ctrlr_detach_ns"},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{done, done})
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			controller := utils.ProtoClone(&testControllerWithStatus)
+			controller.Status.Active = tt.active
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Status = namespaceStatus(tt.enabled)
+			if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testControllerName, controller); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.store.Set(testNamespaceName, namespace); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testSubsystemName] = false
+			s.ListHelper[testControllerName] = false
+			s.ListHelper[testNamespaceName] = false
+			watched := s.store.(*watchedStore)
+			watched.Store = failingSetStore{Store: watched.Store, key: tt.name}
+			rpc := &recordingJSONRPC{JSONRPC: s.rpc}
+			s.rpc = rpc
+
+			var err error
+			switch {
+			case tt.name == testNamespaceName && tt.enable:
+				_, err = testEnv.client.EnableNvmeNamespace(testEnv.ctx, &mb.EnableNvmeNamespaceRequest{Name: tt.name})
+			case tt.name == testNamespaceName:
+				_, err = testEnv.client.DisableNvmeNamespace(testEnv.ctx, &mb.DisableNvmeNamespaceRequest{Name: tt.name})
+			case tt.enable:
+				_, err = testEnv.client.EnableNvmeController(testEnv.ctx, &mb.EnableNvmeControllerRequest{Name: tt.name})
+			default:
+				_, err = testEnv.client.DisableNvmeController(testEnv.ctx, &mb.DisableNvmeControllerRequest{Name: tt.name})
+			}
+
+			checkTenantError(t, err, codes.Unknown, "store unavailable")
+			if !reflect.DeepEqual(rpc.methods, tt.methods) {
+				t.Error("SDK calls: expected", tt.methods, "received", rpc.methods)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_EnableNvmeNamespaceAfterRestart(t *testing.T) {
+	testEnv := createTestEnvironment([]string{})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+
+	namespace := utils.ProtoClone(&testNamespaceWithStatus)
+	namespace.Status = namespaceStatus(false)
+	for key, value := range map[string]proto.Message{
+		testSubsystemName:  &testSubsystemWithStatus,
+		testControllerName: &testControllerWithStatus,
+		testNamespaceName:  namespace,
+	} {
+		if err := s.store.Set(key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the next instance of the server shares the store
+	store := keyListingStore{
+		Store: s.store.(*watchedStore).Store,
+		keys:  []string{testSubsystemName, testControllerName, testNamespaceName},
+	}
+	rpc := &recordingJSONRPC{JSONRPC: unreachableJSONRPC{testEnv.jsonRPC}}
+	restarted := NewServer(rpc, store)
+
+	if _, err := restarted.EnableNvmeNamespace(testEnv.ctx, &mb.EnableNvmeNamespaceRequest{Name: testNamespaceName}); err == nil {
+		t.Error("expected the attachment to the controller to fail")
+	}
+	if want := []string{"mrvl_nvm_ctrlr_attach_ns"}; !reflect.DeepEqual(rpc.methods, want) {
+		t.Error("methods: expected", want, "received", rpc.methods)
+	}
+}
+
+func TestFrontEnd_CreateNvmeControllerAttachesNamespaces(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	done := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	failed := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`
+	tests := map[string]struct {
+		enabled bool
+		spdk    []string
+		methods []string
+		errCode codes.Code
+		errMsg  string
+	}{
+		"enabled namespace attached": {
+			enabled: true,
+			spdk:    []string{done, done},
+			methods: []string{"mrvl_nvm_subsys_create_ctrlr", "mrvl_nvm_ctrlr_attach_ns"},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"disabled namespace not attached": {
+			enabled: false,
+			spdk:    []string{done},
+			methods: []string{"mrvl_nvm_subsys_create_ctrlr"},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"failed attachment removes the controller": {
+			enabled: true,
+			spdk:    []string{done, failed, done},
+			methods: []string{"mrvl_nvm_subsys_create_ctrlr", "mrvl_nvm_ctrlr_attach_ns", "mrvl_nvm_subsys_remove_ctrlr"},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not attach NS: %v", testNamespaceName),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Status = namespaceStatus(tt.enabled)
+			for key, value := range map[string]proto.Message{
+				testSubsystemName: &testSubsystemWithStatus,
+				testNamespaceName: namespace,
+			} {
+				if err := s.store.Set(key, value); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[key] = false
+			}
+			rpc := &recordingJSONRPC{JSONRPC: s.rpc}
+			s.rpc = rpc
+
+			_, err := testEnv.client.CreateNvmeController(testEnv.ctx, &pb.CreateNvmeControllerRequest{
+				Parent:           testSubsystemName,
+				NvmeController:   &pb.NvmeController{Spec: testController.Spec},
+				NvmeControllerId: "controller-new",
+			})
+
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Error("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+			if !reflect.DeepEqual(rpc.methods, tt.methods) {
+				t.Error("SDK calls: expected", tt.methods, "received", rpc.methods)
+			}
+		})
+	}
+}
@@ -9,13 +9,14 @@ package frontend
 import (
 	"context"
 	"fmt"
-	"log"
 	"path"
 	"sort"
 	"strings"
 
 	"github.com/opiproject/gospdk/spdk"
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
 	"github.com/opiproject/opi-marvell-bridge/pkg/models"
 	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
 
@@ -43,10 +44,41 @@ func (s *Server) CreateNvmeSubsystem(ctx context.Context, in *pb.CreateNvmeSubsy
 	// see https://google.aip.dev/133#user-specified-ids
 	resourceID := resourceid.NewSystemGenerated()
 	if in.NvmeSubsystemId != "" {
-		log.Printf("client provided the ID of a resource %v, ignoring the name field %v", in.NvmeSubsystemId, in.NvmeSubsystem.Name)
+		logger.InfoContext(ctx, "client provided the ID of a resource, ignoring the name field", "id", in.NvmeSubsystemId, "name", in.NvmeSubsystem.Name)
 		resourceID = in.NvmeSubsystemId
 	}
 	in.NvmeSubsystem.Name = utils.ResourceIDToSubsystemName(resourceID)
+	audit.SetResource(ctx, in.NvmeSubsystem.Name)
+	// the caller context is not available to long-running operations
+	tenant := tenantOf(ctx)
+	owned, err := s.ownedByCaller(ctx, in.NvmeSubsystem.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !owned {
+		return nil, status.Errorf(codes.AlreadyExists, "%s already exists", in.NvmeSubsystem.Name)
+	}
+	meta, err := requestMetadata(ctx, in.NvmeSubsystem.Name)
+	if err != nil {
+		return nil, err
+	}
+	ctrlrIDs, err := s.requestCtrlrIDRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runOperation(ctx, s, "CreateNvmeSubsystem", in.NvmeSubsystem.Name, utils.ProtoClone(in.NvmeSubsystem),
+		func(ctx context.Context) (*pb.NvmeSubsystem, error) {
+			release, err := s.checkQuota(in.NvmeSubsystem.Name, tenant, tenantUsage{subsystems: 1})
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			return s.createNvmeSubsystem(ctx, in, tenant, meta, ctrlrIDs)
+		},
+	)
+}
+
+func (s *Server) createNvmeSubsystem(ctx context.Context, in *pb.CreateNvmeSubsystemRequest, tenant string, meta *mb.NvmeResourceMetadata, ctrlrIDs *mb.NvmeControllerIdRange) (*pb.NvmeSubsystem, error) {
 	// idempotent API when called with same key, should return same object
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.NvmeSubsystem.Name, subsys)
@@ -54,11 +86,11 @@ func (s *Server) CreateNvmeSubsystem(ctx context.Context, in *pb.CreateNvmeSubsy
 		return nil, err
 	}
 	if found {
-		log.Printf("Already existing NvmeSubsystem with id %v", in.NvmeSubsystem.Name)
+		logger.InfoContext(ctx, "Already existing NvmeSubsystem", "name", in.NvmeSubsystem.Name)
 		return subsys, nil
 	}
 	// check if another object exists with same NQN, it is not allowed
-	for key := range s.ListHelper {
+	for _, key := range s.listedKeys() {
 		if !strings.HasPrefix(key, "//storage.opiproject.org/subsystems") {
 			continue
 		}
@@ -78,21 +110,19 @@ func (s *Server) CreateNvmeSubsystem(ctx context.Context, in *pb.CreateNvmeSubsy
 	}
 	// not found, so create a new one
 
-	// TODO: fix const values below
 	params := models.MrvlNvmCreateSubsystemParams{
 		Subnqn:        in.NvmeSubsystem.Spec.Nqn,
 		Mn:            in.NvmeSubsystem.Spec.ModelNumber,
 		Sn:            in.NvmeSubsystem.Spec.SerialNumber,
 		MaxNamespaces: int(in.NvmeSubsystem.Spec.MaxNamespaces),
-		MinCtrlrID:    0, // bug in v21.01, should be 0 for now
-		MaxCtrlrID:    256,
+		MinCtrlrID:    int(ctrlrIDs.MinCtrlrId),
+		MaxCtrlrID:    int(ctrlrIDs.MaxCtrlrId),
 	}
 	var result models.MrvlNvmCreateSubsystemResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_create_subsystem", &params, &result)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not create NQN: %s", in.NvmeSubsystem.Spec.Nqn)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
@@ -102,11 +132,22 @@ func (s *Server) CreateNvmeSubsystem(ctx context.Context, in *pb.CreateNvmeSubsy
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", ver)
 	response := utils.ProtoClone(in.NvmeSubsystem)
 	response.Status = &pb.NvmeSubsystemStatus{FirmwareRevision: ver.Version}
 	// save object to the database
-	s.ListHelper[in.NvmeSubsystem.Name] = false
+	err = s.setSubsystemOwner(in.NvmeSubsystem.Name, tenant)
+	if err != nil {
+		return nil, err
+	}
+	err = s.setResourceMetadata(meta)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Set(ctrlrIDRangeStoreKey(in.NvmeSubsystem.Name), ctrlrIDs)
+	if err != nil {
+		return nil, err
+	}
+	s.addListed(in.NvmeSubsystem.Name)
 	err = s.store.Set(in.NvmeSubsystem.Name, response)
 	if err != nil {
 		return nil, err
@@ -120,6 +161,22 @@ func (s *Server) DeleteNvmeSubsystem(ctx context.Context, in *pb.DeleteNvmeSubsy
 	if err := s.validateDeleteNvmeSubsystemRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	force := forceRequested(ctx)
+	policy, err := s.requestRemovalPolicy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return runOperation(ctx, s, "DeleteNvmeSubsystem", in.Name, &emptypb.Empty{},
+		func(ctx context.Context) (*emptypb.Empty, error) {
+			return s.deleteNvmeSubsystem(ctx, in, force, policy)
+		},
+	)
+}
+
+func (s *Server) deleteNvmeSubsystem(ctx context.Context, in *pb.DeleteNvmeSubsystemRequest, force bool, policy removalPolicy) (*emptypb.Empty, error) {
 	// fetch object from the database
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.Name, subsys)
@@ -133,34 +190,73 @@ func (s *Server) DeleteNvmeSubsystem(ctx context.Context, in *pb.DeleteNvmeSubsy
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
 		return nil, err
 	}
+	// the controllers and namespaces go first, and only by force
+	children, err := s.checkSubsystemEmpty(subsys, force)
+	if err != nil {
+		return nil, err
+	}
+	restore := func() {}
+	if children != nil {
+		if err := s.deleteSubsystemChildren(ctx, subsys, children, policy); err != nil {
+			return nil, err
+		}
+		restore = func() {
+			s.restoreSubsystemChildren(ctx, subsys, children)
+		}
+	}
 	params := models.MrvlNvmDeleteSubsystemParams{
 		Subnqn: subsys.Spec.Nqn,
 	}
 	var result models.MrvlNvmDeleteSubsystemResult
 	err = s.rpc.Call(ctx, "mrvl_nvm_delete_subsystem", &params, &result)
 	if err != nil {
+		restore()
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not delete NQN: %s", subsys.Spec.Nqn)
+		restore()
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
 	// remove from the Database
-	delete(s.ListHelper, subsys.Name)
+	s.deleteListed(subsys.Name)
 	err = s.store.Delete(subsys.Name)
 	if err != nil {
 		return nil, err
 	}
+	err = s.store.Delete(ownerKey(subsys.Name))
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Delete(metadataKey(subsys.Name))
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Delete(ctrlrIDRangeStoreKey(subsys.Name))
+	if err != nil {
+		return nil, err
+	}
 	return &emptypb.Empty{}, nil
 }
 
 // UpdateNvmeSubsystem updates an Nvme Subsystem
-func (s *Server) UpdateNvmeSubsystem(_ context.Context, in *pb.UpdateNvmeSubsystemRequest) (*pb.NvmeSubsystem, error) {
+func (s *Server) UpdateNvmeSubsystem(ctx context.Context, in *pb.UpdateNvmeSubsystemRequest) (*pb.NvmeSubsystem, error) {
 	// check input correctness
 	if err := s.validateUpdateNvmeSubsystemRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.NvmeSubsystem.Name); err != nil {
+		return nil, err
+	}
+	audit.SetResource(ctx, in.NvmeSubsystem.Name)
+	return runOperation(ctx, s, "UpdateNvmeSubsystem", in.NvmeSubsystem.Name, utils.ProtoClone(in.NvmeSubsystem),
+		func(ctx context.Context) (*pb.NvmeSubsystem, error) {
+			return s.updateNvmeSubsystem(ctx, in)
+		},
+	)
+}
+
+func (s *Server) updateNvmeSubsystem(ctx context.Context, in *pb.UpdateNvmeSubsystemRequest) (*pb.NvmeSubsystem, error) {
 	// fetch object from the database
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.NvmeSubsystem.Name, subsys)
@@ -169,7 +265,7 @@ func (s *Server) UpdateNvmeSubsystem(_ context.Context, in *pb.UpdateNvmeSubsyst
 	}
 	if !found {
 		if in.AllowMissing {
-			log.Printf("TODO: in case of AllowMissing, create a new resource, don;t return error")
+			logger.DebugContext(ctx, "TODO: in case of AllowMissing, create a new resource, don;t return error")
 		}
 		err := status.Errorf(codes.NotFound, "unable to find key %s", in.NvmeSubsystem.Name)
 		return nil, err
@@ -179,7 +275,7 @@ func (s *Server) UpdateNvmeSubsystem(_ context.Context, in *pb.UpdateNvmeSubsyst
 	if err := fieldmask.Validate(in.UpdateMask, in.NvmeSubsystem); err != nil {
 		return nil, err
 	}
-	log.Printf("TODO: use resourceID=%v", resourceID)
+	logger.DebugContext(ctx, "TODO: use resourceID", "resource_id", resourceID)
 	return nil, status.Errorf(codes.Unimplemented, "UpdateNvmeSubsystem method is not implemented")
 }
 
@@ -190,26 +286,55 @@ func (s *Server) ListNvmeSubsystems(ctx context.Context, in *pb.ListNvmeSubsyste
 		return nil, err
 	}
 	// fetch object from the database
-	size, offset, perr := utils.ExtractPagination(in.PageSize, in.PageToken, s.Pagination)
+	size, offset, perr := s.extractPagination(in.PageSize, in.PageToken)
 	if perr != nil {
 		return nil, perr
 	}
+	selector, err := labelSelectorOf(ctx)
+	if err != nil {
+		return nil, err
+	}
 	var result models.MrvlNvmGetSubsysListResult
-	err := s.rpc.Call(ctx, "mrvl_nvm_get_subsys_list", nil, &result)
+	err = s.rpc.Call(ctx, "mrvl_nvm_get_subsys_list", nil, &result)
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := "Could not list subsystems"
 		return nil, status.Errorf(codes.InvalidArgument, msg)
 	}
+	if tenant := tenantOf(ctx); tenant != "" {
+		owned, err := s.ownedSubsystemNqns(tenant)
+		if err != nil {
+			return nil, err
+		}
+		subsysList := result.SubsysList[:0]
+		for _, r := range result.SubsysList {
+			if owned[r.Subnqn] {
+				subsysList = append(subsysList, r)
+			}
+		}
+		result.SubsysList = subsysList
+	}
+	if selector != nil {
+		selected, err := s.selectedSubsystemNqns(selector)
+		if err != nil {
+			return nil, err
+		}
+		subsysList := result.SubsysList[:0]
+		for _, r := range result.SubsysList {
+			if selected[r.Subnqn] {
+				subsysList = append(subsysList, r)
+			}
+		}
+		result.SubsysList = subsysList
+	}
 	token, hasMoreElements := "", false
-	log.Printf("Limiting result len(%d) to [%d:%d]", len(result.SubsysList), offset, size)
+	logger.DebugContext(ctx, "Limiting result", "len", len(result.SubsysList), "offset", offset, "size", size)
 	result.SubsysList, hasMoreElements = utils.LimitPagination(result.SubsysList, offset, size)
 	if hasMoreElements {
 		token = uuid.New().String()
-		s.Pagination[token] = offset + size
+		s.setPageToken(token, offset+size)
 	}
 	Blobarray := make([]*pb.NvmeSubsystem, len(result.SubsysList))
 	for i := range result.SubsysList {
@@ -226,6 +351,9 @@ func (s *Server) GetNvmeSubsystem(ctx context.Context, in *pb.GetNvmeSubsystemRe
 	if err := s.validateGetNvmeSubsystemRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.Name, subsys)
@@ -242,7 +370,6 @@ func (s *Server) GetNvmeSubsystem(ctx context.Context, in *pb.GetNvmeSubsystemRe
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not list NQN: %s", subsys.Spec.Nqn)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
@@ -250,6 +377,16 @@ func (s *Server) GetNvmeSubsystem(ctx context.Context, in *pb.GetNvmeSubsystemRe
 	for i := range result.SubsysList {
 		r := &result.SubsysList[i]
 		if r.Subnqn == subsys.Spec.Nqn {
+			meta, err := s.resourceMetadata(in.Name)
+			if err != nil {
+				return nil, err
+			}
+			sendMetadataHeader(ctx, meta)
+			ctrlrIDs, err := s.subsystemCtrlrIDRange(in.Name)
+			if err != nil {
+				return nil, err
+			}
+			sendCtrlrIDRangeHeader(ctx, ctrlrIDs)
 			return &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: r.Subnqn}, Status: &pb.NvmeSubsystemStatus{FirmwareRevision: "TBD"}}, nil
 		}
 	}
@@ -263,6 +400,9 @@ func (s *Server) StatsNvmeSubsystem(ctx context.Context, in *pb.StatsNvmeSubsyst
 	if err := s.validateStatsNvmeSubsystemRequest(in); err != nil {
 		return nil, err
 	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
 	// fetch object from the database
 	subsys := new(pb.NvmeSubsystem)
 	found, err := s.store.Get(in.Name, subsys)
@@ -281,7 +421,6 @@ func (s *Server) StatsNvmeSubsystem(ctx context.Context, in *pb.StatsNvmeSubsyst
 	if err != nil {
 		return nil, err
 	}
-	log.Printf("Received from SPDK: %v", result)
 	if result.Status != 0 {
 		msg := fmt.Sprintf("Could not stats NQN: %s", subsys.Spec.Nqn)
 		return nil, status.Errorf(codes.InvalidArgument, msg)
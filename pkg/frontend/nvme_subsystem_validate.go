@@ -6,15 +6,11 @@ package frontend
 
 import (
 	"fmt"
-	"regexp"
 
 	"go.einride.tech/aip/fieldbehavior"
 	"go.einride.tech/aip/resourceid"
 	"go.einride.tech/aip/resourcename"
 
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
-
 	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
 )
 
@@ -29,26 +25,27 @@ func (s *Server) validateCreateNvmeSubsystemRequest(in *pb.CreateNvmeSubsystemRe
 			return err
 		}
 	}
-	// check Nqn length
-	if len(in.NvmeSubsystem.Spec.Nqn) > 223 {
-		msg := fmt.Sprintf("Nqn value (%s) is too long, have to be between 1 and 223", in.NvmeSubsystem.Spec.Nqn)
-		return status.Errorf(codes.InvalidArgument, msg)
+	spec := in.NvmeSubsystem.Spec
+	// check SerialNumber and ModelNumber are ASCII of the Identify Controller length
+	if err := validateASCIIString("nvme_subsystem.spec.serial_number", "SerialNumber", spec.SerialNumber, maxSerialNumberLength); err != nil {
+		return err
 	}
-	// check SerialNumber length
-	if len(in.NvmeSubsystem.Spec.SerialNumber) > 20 {
-		msg := fmt.Sprintf("SerialNumber value (%s) is too long, have to be between 1 and 20", in.NvmeSubsystem.Spec.SerialNumber)
-		return status.Errorf(codes.InvalidArgument, msg)
+	if err := validateASCIIString("nvme_subsystem.spec.model_number", "ModelNumber", spec.ModelNumber, maxModelNumberLength); err != nil {
+		return err
 	}
-	// check ModelNumber length
-	if len(in.NvmeSubsystem.Spec.ModelNumber) > 40 {
-		msg := fmt.Sprintf("ModelNumber value (%s) is too long, have to be between 1 and 40", in.NvmeSubsystem.Spec.ModelNumber)
-		return status.Errorf(codes.InvalidArgument, msg)
+	// check Nqn length and pattern
+	if err := validateNqn("nvme_subsystem.spec.nqn", "Nqn", spec.Nqn); err != nil {
+		return err
+	}
+	if spec.Hostnqn != "" {
+		if err := validateNqn("nvme_subsystem.spec.hostnqn", "Hostnqn", spec.Hostnqn); err != nil {
+			return err
+		}
 	}
-	// check if the NQN matches the pattern
-	regex := regexp.MustCompile(`^nqn\.[0-9]{4}-[0-9]{2}(\.[a-zA-Z0-9]+)+(:[a-zA-Z0-9-.]+)+$`)
-	if !regex.MatchString(in.NvmeSubsystem.Spec.Nqn) {
-		msg := fmt.Sprintf("NQN value (%s) does not match pattern", in.NvmeSubsystem.Spec.Nqn)
-		return status.Errorf(codes.InvalidArgument, msg)
+	// check MaxNamespaces range
+	if spec.MaxNamespaces < 0 || spec.MaxNamespaces > maxNvmeNamespaces {
+		msg := fmt.Sprintf("MaxNamespaces value (%d) is out of range, have to be between 0 and %d", spec.MaxNamespaces, maxNvmeNamespaces)
+		return invalidFieldError("nvme_subsystem.spec.max_namespaces", msg)
 	}
 	return nil
 }
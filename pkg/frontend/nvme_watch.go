@@ -0,0 +1,352 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/philippgille/gokv"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var watchLogger = logging.Logger("watch")
+
+const (
+	// watchHistorySize is the number of recent events kept to resume watches
+	watchHistorySize = 1024
+	// watchBufferSize is the number of events a slow watcher can lag behind
+	// before its stream is aborted
+	watchBufferSize = 128
+	// resourceVersionKey is the store key of the last published resource
+	// version, so that the versions keep increasing across restarts
+	resourceVersionKey = "watchResourceVersion"
+)
+
+// watchEvent is a published event with the tenant owning its resource
+// when it was published, the resource may be gone when the event is sent
+type watchEvent struct {
+	*mb.NvmeResourceEvent
+	owner string
+}
+
+// watcher fans out Nvme resource changes to the open watch streams and
+// keeps a window of recent events, so that a watch can be resumed
+type watcher struct {
+	mutex       sync.Mutex
+	version     int64
+	events      []*watchEvent
+	subscribers map[chan *watchEvent]struct{}
+	listeners   []func(*mb.NvmeResourceEvent)
+	closed      chan struct{}
+	// store keeps the last published resource version, if set
+	store gokv.Store
+}
+
+func newWatcher() *watcher {
+	return &watcher{
+		subscribers: make(map[chan *watchEvent]struct{}),
+		closed:      make(chan struct{}),
+	}
+}
+
+// close ends all watch streams and rejects new ones, e.g. on shutdown
+func (w *watcher) close() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	select {
+	case <-w.closed:
+	default:
+		close(w.closed)
+	}
+}
+
+// persist continues the resource versions published before a restart and
+// saves every new one to store
+func (w *watcher) persist(store gokv.Store) error {
+	version := new(wrapperspb.Int64Value)
+	if _, err := store.Get(resourceVersionKey, version); err != nil {
+		return err
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.version = version.Value
+	w.store = store
+	return nil
+}
+
+// newNvmeResourceEvent returns an event of the Nvme resource name, nil if
+// resource is not a watched one
+func newNvmeResourceEvent(eventType mb.NvmeResourceEvent_EventType, name string, resource proto.Message) *mb.NvmeResourceEvent {
+	event := &mb.NvmeResourceEvent{Type: eventType, Name: name, EventTime: timestamppb.Now()}
+	switch r := resource.(type) {
+	case *pb.NvmeSubsystem:
+		event.Resource = &mb.NvmeResourceEvent_NvmeSubsystem{NvmeSubsystem: utils.ProtoClone(r)}
+	case *pb.NvmeController:
+		event.Resource = &mb.NvmeResourceEvent_NvmeController{NvmeController: utils.ProtoClone(r)}
+	case *pb.NvmeNamespace:
+		event.Resource = &mb.NvmeResourceEvent_NvmeNamespace{NvmeNamespace: utils.ProtoClone(r)}
+	default:
+		return nil
+	}
+	return event
+}
+
+func (w *watcher) publish(eventType mb.NvmeResourceEvent_EventType, name string, owner string, resource proto.Message) {
+	event := newNvmeResourceEvent(eventType, name, resource)
+	if event == nil {
+		return
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.version++
+	event.ResourceVersion = w.version
+	if w.store != nil {
+		// saved in order, a lost version only makes the events before it unavailable
+		if err := w.store.Set(resourceVersionKey, wrapperspb.Int64(w.version)); err != nil {
+			watchLogger.Error("Could not save the resource version", "resource_version", w.version, "error", err)
+		}
+	}
+	w.events = append(w.events, &watchEvent{NvmeResourceEvent: event, owner: owner})
+	if len(w.events) > watchHistorySize {
+		w.events = w.events[len(w.events)-watchHistorySize:]
+	}
+	for _, listener := range w.listeners {
+		listener(event)
+	}
+	for events := range w.subscribers {
+		select {
+		case events <- w.events[len(w.events)-1]:
+		default:
+			watchLogger.Warn("Dropping watcher lagging behind", "resource_version", event.ResourceVersion)
+			delete(w.subscribers, events)
+			close(events)
+		}
+	}
+}
+
+// listen registers a function called synchronously for every event,
+// it must not call back into the watcher
+func (w *watcher) listen(listener func(*mb.NvmeResourceEvent)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.listeners = append(w.listeners, listener)
+}
+
+// listenStored registers listener like listen, and first calls it with a
+// created event for every listed resource, e.g. the ones stored before a
+// restart. The events published meanwhile wait, so that none is missed.
+func (s *Server) listenStored(listener func(*mb.NvmeResourceEvent)) {
+	s.watcher.mutex.Lock()
+	defer s.watcher.mutex.Unlock()
+	for _, key := range s.listedKeys() {
+		resource := newWatchedResource(key)
+		if resource == nil {
+			continue
+		}
+		found, err := s.store.Get(key, resource)
+		if err != nil {
+			watchLogger.Error("Could not fetch a stored resource", "name", key, "error", err)
+			continue
+		}
+		if found {
+			listener(newNvmeResourceEvent(mb.NvmeResourceEvent_EVENT_TYPE_CREATED, key, resource))
+		}
+	}
+	s.watcher.listeners = append(s.watcher.listeners, listener)
+}
+
+// subscribe registers a new watch stream and returns the events it missed since version
+func (w *watcher) subscribe(version int64) (chan *watchEvent, []*watchEvent, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	select {
+	case <-w.closed:
+		return nil, nil, status.Error(codes.Unavailable, "server is shutting down")
+	default:
+	}
+	if version > w.version {
+		msg := "resource version %d is newer than the current resource version %d"
+		return nil, nil, status.Errorf(codes.OutOfRange, msg, version, w.version)
+	}
+	backlog := []*watchEvent{}
+	if version > 0 && version < w.version {
+		// the events before a restart of the server are not kept
+		if oldest := w.version - int64(len(w.events)); oldest > version {
+			msg := "resource version %d is too old, the oldest available resource version is %d"
+			return nil, nil, status.Errorf(codes.OutOfRange, msg, version, oldest)
+		}
+		for _, event := range w.events {
+			if event.ResourceVersion > version {
+				backlog = append(backlog, event)
+			}
+		}
+	}
+	events := make(chan *watchEvent, watchBufferSize)
+	w.subscribers[events] = struct{}{}
+	return events, backlog, nil
+}
+
+func (w *watcher) unsubscribe(events chan *watchEvent) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if _, ok := w.subscribers[events]; ok {
+		delete(w.subscribers, events)
+		close(events)
+	}
+}
+
+// watchedStore publishes every change of an Nvme resource written to the store
+type watchedStore struct {
+	gokv.Store
+	watcher *watcher
+	// mutex makes the reads of the previous states atomic with the writes,
+	// so that concurrent writes publish consistent events
+	mutex sync.Mutex
+}
+
+// newWatchedResource returns an empty resource of the kind stored under key,
+// or nil if the key does not hold a watched resource
+func newWatchedResource(key string) proto.Message {
+	switch {
+	case !strings.HasPrefix(key, "nvmeSubsystems/"):
+		return nil
+	case strings.Contains(key, "/nvmeControllers/"):
+		return new(pb.NvmeController)
+	case strings.Contains(key, "/nvmeNamespaces/"):
+		return new(pb.NvmeNamespace)
+	default:
+		return new(pb.NvmeSubsystem)
+	}
+}
+
+// owner returns the tenant owning the subsystem of the resource k, which
+// is set before the subsystem is stored and deleted after it
+func (w *watchedStore) owner(k string) (string, error) {
+	owner := new(wrapperspb.StringValue)
+	if _, err := w.Store.Get(ownerKey(subsystemNameOf(k)), owner); err != nil {
+		return "", err
+	}
+	return owner.Value, nil
+}
+
+// Set stores the value and publishes a created or updated event
+func (w *watchedStore) Set(k string, v interface{}) error {
+	previous := newWatchedResource(k)
+	if previous == nil {
+		return w.Store.Set(k, v)
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	found, err := w.Store.Get(k, previous)
+	if err != nil {
+		return err
+	}
+	owner, err := w.owner(k)
+	if err != nil {
+		return err
+	}
+	err = w.Store.Set(k, v)
+	if err != nil {
+		return err
+	}
+	eventType := mb.NvmeResourceEvent_EVENT_TYPE_CREATED
+	if found {
+		eventType = mb.NvmeResourceEvent_EVENT_TYPE_UPDATED
+	}
+	if resource, ok := v.(proto.Message); ok {
+		w.watcher.publish(eventType, k, owner, resource)
+	}
+	return nil
+}
+
+// Delete deletes the value and publishes a deleted event with its last known state
+func (w *watchedStore) Delete(k string) error {
+	previous := newWatchedResource(k)
+	if previous == nil {
+		return w.Store.Delete(k)
+	}
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	found, err := w.Store.Get(k, previous)
+	if err != nil {
+		return err
+	}
+	owner, err := w.owner(k)
+	if err != nil {
+		return err
+	}
+	err = w.Store.Delete(k)
+	if err != nil {
+		return err
+	}
+	if found {
+		w.watcher.publish(mb.NvmeResourceEvent_EVENT_TYPE_DELETED, k, owner, previous)
+	}
+	return nil
+}
+
+// WatchNvmeResources streams changes of Nvme subsystems, controllers and
+// namespaces, only of their own subsystems for tenants
+func (s *Server) WatchNvmeResources(in *mb.WatchNvmeResourcesRequest, stream mb.NvmeWatchService_WatchNvmeResourcesServer) error {
+	// check input correctness
+	if err := s.validateWatchNvmeResourcesRequest(in); err != nil {
+		return err
+	}
+	if in.Parent != "" {
+		if err := s.checkOwnedByCaller(stream.Context(), in.Parent); err != nil {
+			return err
+		}
+	}
+	tenant := tenantOf(stream.Context())
+	events, backlog, err := s.watcher.subscribe(in.ResourceVersion)
+	if err != nil {
+		return err
+	}
+	defer s.watcher.unsubscribe(events)
+	for _, event := range backlog {
+		if err := sendNvmeResourceEvent(stream, in.Parent, tenant, event); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				msg := "watch fell behind, resume it from the last received resource version"
+				return status.Error(codes.Aborted, msg)
+			}
+			if err := sendNvmeResourceEvent(stream, in.Parent, tenant, event); err != nil {
+				return err
+			}
+		case <-s.watcher.closed:
+			msg := "server is shutting down, resume the watch from the last received resource version"
+			return status.Error(codes.Unavailable, msg)
+		case <-stream.Context().Done():
+			return nil
+		}
+	}
+}
+
+func sendNvmeResourceEvent(stream mb.NvmeWatchService_WatchNvmeResourcesServer, parent string, tenant string, event *watchEvent) error {
+	if parent != "" && event.Name != parent && !strings.HasPrefix(event.Name, parent+"/") {
+		return nil
+	}
+	// the resources of other tenants are not disclosed
+	if tenant != "" && event.owner != tenant {
+		return nil
+	}
+	return stream.Send(event.NvmeResourceEvent)
+}
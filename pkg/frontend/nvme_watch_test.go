@@ -0,0 +1,340 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/philippgille/gokv"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	bridgestore "github.com/opiproject/opi-marvell-bridge/pkg/store"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+type testNvmeResourceEvent struct {
+	eventType mb.NvmeResourceEvent_EventType
+	version   int64
+	name      string
+}
+
+func TestFrontEnd_WatchNvmeResources(t *testing.T) {
+	otherSubsystemName := utils.ResourceIDToSubsystemName("subsystem-other")
+	tests := map[string]struct {
+		in      *mb.WatchNvmeResourcesRequest
+		tenant  string
+		update  bool
+		out     []testNvmeResourceEvent
+		errCode codes.Code
+		errMsg  string
+	}{
+		"resume from resource version": {
+			in:     &mb.WatchNvmeResourcesRequest{ResourceVersion: 1},
+			update: false,
+			out: []testNvmeResourceEvent{
+				{mb.NvmeResourceEvent_EVENT_TYPE_CREATED, 2, testControllerName},
+				{mb.NvmeResourceEvent_EVENT_TYPE_CREATED, 3, testNamespaceName},
+				{mb.NvmeResourceEvent_EVENT_TYPE_DELETED, 4, testNamespaceName},
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"filter by parent": {
+			in:     &mb.WatchNvmeResourcesRequest{ResourceVersion: 1, Parent: testControllerName},
+			update: false,
+			out: []testNvmeResourceEvent{
+				{mb.NvmeResourceEvent_EVENT_TYPE_CREATED, 2, testControllerName},
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"events of the tenant": {
+			in:     &mb.WatchNvmeResourcesRequest{ResourceVersion: 1},
+			tenant: "tenant-b",
+			update: false,
+			out: []testNvmeResourceEvent{
+				{mb.NvmeResourceEvent_EVENT_TYPE_CREATED, 5, otherSubsystemName},
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"parent of another tenant": {
+			in:      &mb.WatchNvmeResourcesRequest{Parent: testSubsystemName},
+			tenant:  "tenant-b",
+			update:  false,
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testSubsystemName),
+		},
+		"live events": {
+			in:     &mb.WatchNvmeResourcesRequest{ResourceVersion: 4},
+			update: true,
+			out: []testNvmeResourceEvent{
+				{mb.NvmeResourceEvent_EVENT_TYPE_UPDATED, 5, testSubsystemName},
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"resource version newer than current": {
+			in:      &mb.WatchNvmeResourcesRequest{ResourceVersion: 10},
+			update:  false,
+			out:     nil,
+			errCode: codes.OutOfRange,
+			errMsg:  fmt.Sprintf("resource version %d is newer than the current resource version %d", 10, 4),
+		},
+		"negative resource version": {
+			in:      &mb.WatchNvmeResourcesRequest{ResourceVersion: -1},
+			update:  false,
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("ResourceVersion value (%d) is negative", -1),
+		},
+		"malformed parent": {
+			in:      &mb.WatchNvmeResourcesRequest{Parent: "-ABC-DEF"},
+			update:  false,
+			out:     nil,
+			errCode: codes.Unknown,
+			errMsg:  fmt.Sprintf("segment '%s': not a valid DNS name", "-ABC-DEF"),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			_ = testEnv.opiSpdkServer.setSubsystemOwner(testSubsystemName, "tenant-a")
+			_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystem)
+			_ = testEnv.opiSpdkServer.store.Set(testControllerName, &testController)
+			_ = testEnv.opiSpdkServer.store.Set(testNamespaceName, &testNamespace)
+			_ = testEnv.opiSpdkServer.store.Delete(testNamespaceName)
+
+			ctx, cancel := context.WithCancel(testEnv.ctx)
+			defer cancel()
+			if tt.tenant != "" {
+				_ = testEnv.opiSpdkServer.setSubsystemOwner(otherSubsystemName, tt.tenant)
+				_ = testEnv.opiSpdkServer.store.Set(otherSubsystemName, &pb.NvmeSubsystem{Name: otherSubsystemName})
+				ctx = metadata.AppendToOutgoingContext(ctx, tenantKey, tt.tenant)
+			}
+			stream, err := testEnv.client.WatchNvmeResources(ctx, tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.update {
+				_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystem)
+			}
+
+			for _, expected := range tt.out {
+				event, err := stream.Recv()
+				if err != nil {
+					t.Fatal("unexpected error", err)
+				}
+				if event.Type != expected.eventType || event.ResourceVersion != expected.version || event.Name != expected.name {
+					t.Error("event: expected", expected, "received", event)
+				}
+			}
+
+			if tt.errCode != codes.OK {
+				_, err = stream.Recv()
+				if er, ok := status.FromError(err); ok {
+					if er.Code() != tt.errCode {
+						t.Error("error code: expected", tt.errCode, "received", er.Code())
+					}
+					if er.Message() != tt.errMsg {
+						t.Error("error message: expected", tt.errMsg, "received", er.Message())
+					}
+				} else {
+					t.Error("expected grpc error status")
+				}
+			}
+		})
+	}
+}
+
+func TestFrontEnd_WatchNvmeResourcesEvents(t *testing.T) {
+	tests := map[string]struct {
+		set     bool
+		delete  bool
+		out     *mb.NvmeResourceEvent
+		errCode codes.Code
+		errMsg  string
+	}{
+		"deleted event carries last known state": {
+			set:    true,
+			delete: true,
+			out: &mb.NvmeResourceEvent{
+				Type:            mb.NvmeResourceEvent_EVENT_TYPE_DELETED,
+				ResourceVersion: 2,
+				Name:            testSubsystemName,
+				Resource:        &mb.NvmeResourceEvent_NvmeSubsystem{NvmeSubsystem: &testSubsystem},
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"delete of unknown resource is not published": {
+			set:     false,
+			delete:  true,
+			out:     nil,
+			errCode: codes.OutOfRange,
+			errMsg:  fmt.Sprintf("resource version %d is newer than the current resource version %d", 1, 0),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			if tt.set {
+				_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystem)
+			}
+			if tt.delete {
+				_ = testEnv.opiSpdkServer.store.Delete(testSubsystemName)
+			}
+
+			events, backlog, err := testEnv.opiSpdkServer.watcher.subscribe(1)
+			if err == nil {
+				defer testEnv.opiSpdkServer.watcher.unsubscribe(events)
+			}
+
+			if tt.out != nil {
+				if len(backlog) != 1 {
+					t.Fatal("backlog: expected 1 event, received", len(backlog))
+				}
+				backlog[0].EventTime = nil
+				if !proto.Equal(backlog[0].NvmeResourceEvent, tt.out) {
+					t.Error("event: expected", tt.out, "received", backlog[0].NvmeResourceEvent)
+				}
+			}
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+		})
+	}
+}
+
+func TestFrontEnd_WatchNvmeResourcesHistory(t *testing.T) {
+	w := newWatcher()
+	for i := 0; i < watchHistorySize+2; i++ {
+		w.publish(mb.NvmeResourceEvent_EVENT_TYPE_UPDATED, testSubsystemName, "", &pb.NvmeSubsystem{Name: testSubsystemName})
+	}
+
+	_, _, err := w.subscribe(1)
+	if er, ok := status.FromError(err); !ok || er.Code() != codes.OutOfRange {
+		t.Error("error: expected", codes.OutOfRange, "received", err)
+	}
+
+	events, backlog, err := w.subscribe(2)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if len(backlog) != watchHistorySize {
+		t.Error("backlog: expected", watchHistorySize, "received", len(backlog))
+	}
+
+	// a watcher that does not drain its events is dropped
+	for i := 0; i <= watchBufferSize; i++ {
+		w.publish(mb.NvmeResourceEvent_EVENT_TYPE_UPDATED, testSubsystemName, "", &pb.NvmeSubsystem{Name: testSubsystemName})
+	}
+	received := 0
+	for range events {
+		received++
+	}
+	if received != watchBufferSize {
+		t.Error("events: expected", watchBufferSize, "received", received)
+	}
+}
+
+func TestFrontEnd_WatchNvmeResourcesAfterRestart(t *testing.T) {
+	testEnv := createTestEnvironment([]string{})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	for i := 0; i < 3; i++ {
+		if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the next instance of the server shares the store, not the events
+	restarted := NewServer(testEnv.jsonRPC, s.store.(*watchedStore).Store)
+
+	_, _, err := restarted.watcher.subscribe(2)
+	checkTenantError(t, err, codes.OutOfRange, "resource version 2 is too old, the oldest available resource version is 3")
+	events, backlog, err := restarted.watcher.subscribe(3)
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	defer restarted.watcher.unsubscribe(events)
+	if len(backlog) != 0 {
+		t.Error("backlog: expected none, received", backlog)
+	}
+	if err := restarted.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+		t.Fatal(err)
+	}
+	if event := <-events; event.ResourceVersion != 4 {
+		t.Error("resource version: expected 4, received", event.ResourceVersion)
+	}
+}
+
+// slowGetStore widens the window between the reads and the writes
+type slowGetStore struct {
+	gokv.Store
+}
+
+func (s slowGetStore) Get(k string, v interface{}) (bool, error) {
+	time.Sleep(10 * time.Millisecond)
+	return s.Store.Get(k, v)
+}
+
+func TestFrontEnd_WatchNvmeResourcesConcurrentSets(t *testing.T) {
+	store := &watchedStore{Store: slowGetStore{bridgestore.NewGomapStore(utils.ProtoCodec{})}, watcher: newWatcher()}
+	events, _, err := store.watcher.subscribe(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.watcher.unsubscribe(events)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	created := 0
+	for i := 0; i < 8; i++ {
+		if event := <-events; event.Type == mb.NvmeResourceEvent_EVENT_TYPE_CREATED {
+			created++
+		}
+	}
+	if created != 1 {
+		t.Error("created events: expected 1, received", created)
+	}
+}
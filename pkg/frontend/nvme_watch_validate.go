@@ -0,0 +1,28 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"fmt"
+
+	"go.einride.tech/aip/resourcename"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+)
+
+func (s *Server) validateWatchNvmeResourcesRequest(in *mb.WatchNvmeResourcesRequest) error {
+	if in.ResourceVersion < 0 {
+		msg := fmt.Sprintf("ResourceVersion value (%d) is negative", in.ResourceVersion)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	if in.Parent == "" {
+		return nil
+	}
+	// Validate that a resource name conforms to the restrictions outlined in AIP-122.
+	return resourcename.Validate(in.Parent)
+}
@@ -0,0 +1,511 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"github.com/google/uuid"
+	"go.einride.tech/aip/resourcename"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+var operationsLogger = logging.Logger("operations")
+
+const (
+	// asyncOperationKey is the request metadata key a client sets to "true"
+	// to run a Create/Update/Delete call as a long-running operation
+	asyncOperationKey = "x-opi-async"
+	// operationHeaderKey is the response header key carrying the name of
+	// the started long-running operation
+	operationHeaderKey = "x-opi-operation"
+
+	defaultWaitOperationTimeout = 30 * time.Second
+	// operationRollbackTimeout bounds the wait for operations cancelled
+	// on shutdown to roll back
+	operationRollbackTimeout = 5 * time.Second
+	// runningOperationsKey is the store key of the names of the operations
+	// not done yet, which are failed on the next start if the server stops
+	// meanwhile
+	runningOperationsKey = "runningOperations"
+)
+
+// operation tracks a long-running operation executed by this server
+type operation struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type operationContextKey struct{}
+
+func sortOperations(operations []*longrunningpb.Operation) {
+	sort.Slice(operations, func(i int, j int) bool {
+		return operations[i].Name < operations[j].Name
+	})
+}
+
+// asyncRequested checks if the client opted in to a long-running operation
+func asyncRequested(ctx context.Context) bool {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false
+	}
+	for _, v := range md.Get(asyncOperationKey) {
+		if async, err := strconv.ParseBool(v); err == nil && async {
+			return true
+		}
+	}
+	return false
+}
+
+// runOperation executes fn in place, unless the client opted in to a
+// long-running operation. In that case fn is executed in the background,
+// pending is returned right away and the operation name is sent back in
+// the response header.
+func runOperation[T proto.Message](ctx context.Context, s *Server, method string, resource string, pending T, fn func(context.Context) (T, error)) (T, error) {
+	if !s.opts.AsyncOperations || !asyncRequested(ctx) {
+		return fn(ctx)
+	}
+	name, err := s.startOperation(ctx, method, resource, func(ctx context.Context) (proto.Message, error) {
+		return fn(ctx)
+	})
+	if err != nil {
+		var empty T
+		return empty, err
+	}
+	if err := grpc.SetHeader(ctx, metadata.Pairs(operationHeaderKey, name)); err != nil {
+		operationsLogger.WarnContext(ctx, "unable to send operation header", "operation", name, "error", err)
+	}
+	return pending, nil
+}
+
+func (s *Server) startOperation(ctx context.Context, method string, resource string, fn func(context.Context) (proto.Message, error)) (string, error) {
+	s.opMutex.Lock()
+	stopping := s.stopping
+	s.opMutex.Unlock()
+	if stopping {
+		return "", status.Error(codes.Unavailable, "server is shutting down")
+	}
+	name := resourcename.Join("operations", uuid.New().String())
+	meta, err := newOperationMetadata(method, resource)
+	if err != nil {
+		return "", err
+	}
+	op := &longrunningpb.Operation{Name: name, Metadata: meta}
+	if err := s.setOperationOwner(name, tenantOf(ctx)); err != nil {
+		return "", err
+	}
+	// save object to the database
+	s.addListed(name)
+	err = s.store.Set(name, op)
+	if err != nil {
+		return "", err
+	}
+	if err := s.setOperationRunning(name, true); err != nil {
+		return "", err
+	}
+	// operation outlives the call that started it, so it can't inherit its context
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, operationContextKey{}, name)
+	running := &operation{cancel: cancel, done: make(chan struct{})}
+	s.opMutex.Lock()
+	s.operations[name] = running
+	s.opMutex.Unlock()
+	operationsLogger.Info("Started operation", "operation", name, "method", method, "resource", resource)
+	go func() {
+		result, err := fn(ctx)
+		s.finishOperation(name, result, err)
+		cancel()
+		s.opMutex.Lock()
+		delete(s.operations, name)
+		s.opMutex.Unlock()
+		close(running.done)
+	}()
+	return name, nil
+}
+
+// setOperationOwner records the tenant which started an operation, the
+// operations started by the operators have no owner
+func (s *Server) setOperationOwner(name string, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	return s.store.Set(ownerKey(name), wrapperspb.String(tenant))
+}
+
+// operationOwner returns the tenant which started an operation, empty if none
+func (s *Server) operationOwner(name string) (string, error) {
+	owner := new(wrapperspb.StringValue)
+	if _, err := s.store.Get(ownerKey(name), owner); err != nil {
+		return "", err
+	}
+	return owner.Value, nil
+}
+
+func (s *Server) finishOperation(name string, result proto.Message, opErr error) {
+	op := new(longrunningpb.Operation)
+	found, err := s.store.Get(name, op)
+	if err != nil {
+		operationsLogger.Error("unable to fetch operation", "operation", name, "error", err)
+		return
+	}
+	if !found {
+		operationsLogger.Warn("operation was deleted before it finished", "operation", name)
+		s.setOperationRunningOrLog(name, false)
+		return
+	}
+	op.Done = true
+	if opErr == nil {
+		var response *anypb.Any
+		response, opErr = anypb.New(result)
+		if opErr == nil {
+			op.Result = &longrunningpb.Operation_Response{Response: response}
+			op.Metadata = withOperationProgress(op.Metadata, 100)
+		}
+	}
+	if opErr != nil {
+		op.Result = &longrunningpb.Operation_Error{Error: status.Convert(opErr).Proto()}
+	}
+	operationsLogger.Info("Finished operation", "operation", name, "error", opErr)
+	err = s.store.Set(name, op)
+	if err != nil {
+		operationsLogger.Error("unable to save operation", "operation", name, "error", err)
+		return
+	}
+	s.setOperationRunningOrLog(name, false)
+}
+
+// runningOperations returns the names of the operations not done yet
+func (s *Server) runningOperations() ([]string, error) {
+	list := new(structpb.ListValue)
+	if _, err := s.store.Get(runningOperationsKey, list); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Values))
+	for _, v := range list.Values {
+		names = append(names, v.GetStringValue())
+	}
+	return names, nil
+}
+
+// setOperationRunning adds or removes an operation to or from the running ones
+func (s *Server) setOperationRunning(name string, running bool) error {
+	s.opMutex.Lock()
+	defer s.opMutex.Unlock()
+	names, err := s.runningOperations()
+	if err != nil {
+		return err
+	}
+	list := &structpb.ListValue{}
+	for _, n := range names {
+		if n != name {
+			list.Values = append(list.Values, structpb.NewStringValue(n))
+		}
+	}
+	if running {
+		list.Values = append(list.Values, structpb.NewStringValue(name))
+	}
+	if len(list.Values) == 0 {
+		return s.store.Delete(runningOperationsKey)
+	}
+	return s.store.Set(runningOperationsKey, list)
+}
+
+func (s *Server) setOperationRunningOrLog(name string, running bool) {
+	if err := s.setOperationRunning(name, running); err != nil {
+		operationsLogger.Error("unable to save the running operations", "operation", name, "error", err)
+	}
+}
+
+// failInterruptedOperations fails the operations left running by a
+// previous instance of the server, which will never finish them
+func (s *Server) failInterruptedOperations() error {
+	names, err := s.runningOperations()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		op := new(longrunningpb.Operation)
+		found, err := s.store.Get(name, op)
+		if err != nil {
+			return err
+		}
+		if found {
+			s.addListed(name)
+		}
+		if found && !op.Done {
+			operationsLogger.Warn("Failing operation interrupted by a restart", "operation", name)
+			op.Done = true
+			op.Result = &longrunningpb.Operation_Error{
+				Error: status.New(codes.Aborted, "operation interrupted by a restart of the server").Proto(),
+			}
+			if err := s.store.Set(name, op); err != nil {
+				return err
+			}
+		}
+		if err := s.setOperationRunning(name, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setOperationProgress records the progress of the long-running operation
+// carried by ctx, if any
+func (s *Server) setOperationProgress(ctx context.Context, done int, total int) {
+	name, ok := ctx.Value(operationContextKey{}).(string)
+	if !ok || total == 0 {
+		return
+	}
+	op := new(longrunningpb.Operation)
+	found, err := s.store.Get(name, op)
+	if err != nil || !found {
+		operationsLogger.ErrorContext(ctx, "unable to fetch operation", "operation", name, "error", err)
+		return
+	}
+	op.Metadata = withOperationProgress(op.Metadata, 100*done/total)
+	err = s.store.Set(name, op)
+	if err != nil {
+		operationsLogger.ErrorContext(ctx, "unable to save operation", "operation", name, "error", err)
+	}
+}
+
+func newOperationMetadata(method string, resource string) (*anypb.Any, error) {
+	meta, err := structpb.NewStruct(map[string]interface{}{
+		"method":           method,
+		"resource":         resource,
+		"progress_percent": 0,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return anypb.New(meta)
+}
+
+func withOperationProgress(meta *anypb.Any, percent int) *anypb.Any {
+	fields := new(structpb.Struct)
+	if err := meta.UnmarshalTo(fields); err != nil {
+		operationsLogger.Error("unable to decode operation metadata", "error", err)
+		return meta
+	}
+	fields.Fields["progress_percent"] = structpb.NewNumberValue(float64(percent))
+	updated, err := anypb.New(fields)
+	if err != nil {
+		operationsLogger.Error("unable to encode operation metadata", "error", err)
+		return meta
+	}
+	return updated
+}
+
+// StopWatches ends the open watch streams and rejects new ones
+func (s *Server) StopWatches() {
+	s.watcher.close()
+}
+
+// Shutdown ends the watch streams, rejects new long-running operations and
+// waits for the running ones to finish. Operations still running when ctx
+// is done are cancelled, so that they roll back their SDK changes, and
+// waited for a little longer.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.StopWatches()
+	s.opMutex.Lock()
+	s.stopping = true
+	running := make([]*operation, 0, len(s.operations))
+	for _, op := range s.operations {
+		running = append(running, op)
+	}
+	s.opMutex.Unlock()
+	operationsLogger.InfoContext(ctx, "Waiting for running operations", "count", len(running))
+	pending := waitOperations(ctx.Done(), running)
+	if len(pending) == 0 {
+		return nil
+	}
+	operationsLogger.WarnContext(ctx, "Cancelling operations still running", "count", len(pending))
+	for _, op := range pending {
+		op.cancel()
+	}
+	timer := time.NewTimer(operationRollbackTimeout)
+	defer timer.Stop()
+	if pending = waitOperations(timer.C, pending); len(pending) != 0 {
+		operationsLogger.ErrorContext(ctx, "Operations did not roll back in time", "count", len(pending))
+	}
+	return ctx.Err()
+}
+
+// waitOperations waits for operations to finish until stop, and returns
+// the ones still running
+func waitOperations[T any](stop <-chan T, operations []*operation) []*operation {
+	for i, op := range operations {
+		select {
+		case <-op.done:
+		case <-stop:
+			return operations[i:]
+		}
+	}
+	return nil
+}
+
+// checkOperationCanceled is called between SDK calls of multi-step handlers,
+// so that a cancelled long-running operation stops as soon as possible
+func checkOperationCanceled(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return status.FromContextError(err).Err()
+	}
+	return nil
+}
+
+// ListOperations lists long-running operations
+func (s *Server) ListOperations(ctx context.Context, in *longrunningpb.ListOperationsRequest) (*longrunningpb.ListOperationsResponse, error) {
+	// check input correctness
+	done, err := s.validateListOperationsRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	// fetch object from the database
+	size, offset, perr := s.extractPagination(in.PageSize, in.PageToken)
+	if perr != nil {
+		return nil, perr
+	}
+	Blobarray := []*longrunningpb.Operation{}
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, "operations/") {
+			continue
+		}
+		owned, err := s.ownedByCaller(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		if !owned {
+			continue
+		}
+		op := new(longrunningpb.Operation)
+		ok, err := s.store.Get(key, op)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			err := status.Errorf(codes.NotFound, "unable to find key %s", key)
+			return nil, err
+		}
+		if done != nil && op.Done != *done {
+			continue
+		}
+		Blobarray = append(Blobarray, op)
+	}
+	sortOperations(Blobarray)
+	token, hasMoreElements := "", false
+	operationsLogger.DebugContext(ctx, "Limiting result", "len", len(Blobarray), "offset", offset, "size", size)
+	Blobarray, hasMoreElements = utils.LimitPagination(Blobarray, offset, size)
+	if hasMoreElements {
+		token = uuid.New().String()
+		s.setPageToken(token, offset+size)
+	}
+	return &longrunningpb.ListOperationsResponse{Operations: Blobarray, NextPageToken: token}, nil
+}
+
+// GetOperation gets a long-running operation
+func (s *Server) GetOperation(ctx context.Context, in *longrunningpb.GetOperationRequest) (*longrunningpb.Operation, error) {
+	// check input correctness
+	if err := s.validateOperationName(in.Name); err != nil {
+		return nil, err
+	}
+	if err := s.checkOwnedByCaller(ctx, in.Name); err != nil {
+		return nil, err
+	}
+	// fetch object from the database
+	op := new(longrunningpb.Operation)
+	found, err := s.store.Get(in.Name, op)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		err := status.Errorf(codes.NotFound, "unable to find key %s", in.Name)
+		return nil, err
+	}
+	return op, nil
+}
+
+// DeleteOperation deletes a long-running operation, cancelling it if it is still running
+func (s *Server) DeleteOperation(ctx context.Context, in *longrunningpb.DeleteOperationRequest) (*emptypb.Empty, error) {
+	op, err := s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: in.Name})
+	if err != nil {
+		return nil, err
+	}
+	s.opMutex.Lock()
+	if running, ok := s.operations[op.Name]; ok {
+		running.cancel()
+	}
+	s.opMutex.Unlock()
+	// remove from the Database
+	s.deleteListed(op.Name)
+	err = s.store.Delete(op.Name)
+	if err != nil {
+		return nil, err
+	}
+	err = s.store.Delete(ownerKey(op.Name))
+	if err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// CancelOperation starts asynchronous cancellation of a long-running operation
+func (s *Server) CancelOperation(ctx context.Context, in *longrunningpb.CancelOperationRequest) (*emptypb.Empty, error) {
+	op, err := s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: in.Name})
+	if err != nil {
+		return nil, err
+	}
+	s.opMutex.Lock()
+	if running, ok := s.operations[op.Name]; ok {
+		operationsLogger.InfoContext(ctx, "Cancelling operation", "operation", op.Name)
+		running.cancel()
+	}
+	s.opMutex.Unlock()
+	return &emptypb.Empty{}, nil
+}
+
+// WaitOperation waits until a long-running operation is done or the timeout expires
+func (s *Server) WaitOperation(ctx context.Context, in *longrunningpb.WaitOperationRequest) (*longrunningpb.Operation, error) {
+	timeout, err := s.validateWaitOperationRequest(in)
+	if err != nil {
+		return nil, err
+	}
+	op, err := s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: in.Name})
+	if err != nil || op.Done {
+		return op, err
+	}
+	s.opMutex.Lock()
+	running, ok := s.operations[op.Name]
+	s.opMutex.Unlock()
+	if !ok {
+		// not executed by this server instance, e.g. interrupted by a restart
+		return op, nil
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-running.done:
+	case <-timer.C:
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+	return s.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: in.Name})
+}
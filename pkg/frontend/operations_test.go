@@ -0,0 +1,769 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	mb "github.com/opiproject/opi-marvell-bridge/api/v1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+var (
+	testOperationName     = "operations/operation-test"
+	testDoneOperationName = "operations/operation-done-test"
+	testOperation         = longrunningpb.Operation{
+		Name: testOperationName,
+	}
+	testDoneOperation = longrunningpb.Operation{
+		Name: testDoneOperationName,
+		Done: true,
+		Result: &longrunningpb.Operation_Error{
+			Error: status.New(codes.Canceled, "context canceled").Proto(),
+		},
+	}
+)
+
+func TestFrontEnd_GetOperation(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		out     *longrunningpb.Operation
+		errCode codes.Code
+		errMsg  string
+	}{
+		"valid request": {
+			in:      testDoneOperationName,
+			out:     &testDoneOperation,
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"valid request with unknown key": {
+			in:      "operations/unknown-operation-id",
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", "operations/unknown-operation-id"),
+		},
+		"not an operation name": {
+			in:      testSubsystemName,
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Operation name (%s) must start with operations/", testSubsystemName),
+		},
+		"no required field": {
+			in:      "",
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  "missing required field: name",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			_ = testEnv.opiSpdkServer.store.Set(testDoneOperationName, &testDoneOperation)
+
+			request := &longrunningpb.GetOperationRequest{Name: tt.in}
+			response, err := testEnv.client.GetOperation(testEnv.ctx, request)
+
+			if !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+		})
+	}
+}
+
+func TestFrontEnd_ListOperations(t *testing.T) {
+	tests := map[string]struct {
+		filter  string
+		out     []*longrunningpb.Operation
+		errCode codes.Code
+		errMsg  string
+		size    int32
+		token   string
+	}{
+		"valid request": {
+			filter:  "",
+			out:     []*longrunningpb.Operation{&testDoneOperation, &testOperation},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    0,
+			token:   "",
+		},
+		"done filter": {
+			filter:  "done = true",
+			out:     []*longrunningpb.Operation{&testDoneOperation},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    0,
+			token:   "",
+		},
+		"not done filter": {
+			filter:  "done=false",
+			out:     []*longrunningpb.Operation{&testOperation},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    0,
+			token:   "",
+		},
+		"unsupported filter": {
+			filter:  "name=operations/a",
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Filter (%s) is not supported, use done=true or done=false", "name=operations/a"),
+			size:    0,
+			token:   "",
+		},
+		"pagination": {
+			filter:  "",
+			out:     []*longrunningpb.Operation{&testDoneOperation},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    1,
+			token:   "",
+		},
+		"pagination offset": {
+			filter:  "",
+			out:     []*longrunningpb.Operation{&testOperation},
+			errCode: codes.OK,
+			errMsg:  "",
+			size:    1,
+			token:   "existing-pagination-token",
+		},
+		"pagination negative": {
+			filter:  "",
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  "negative PageSize is not allowed",
+			size:    -10,
+			token:   "",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			testEnv.opiSpdkServer.Pagination["existing-pagination-token"] = 1
+			testEnv.opiSpdkServer.ListHelper[testOperationName] = false
+			testEnv.opiSpdkServer.ListHelper[testDoneOperationName] = false
+			testEnv.opiSpdkServer.ListHelper[testSubsystemName] = false
+			_ = testEnv.opiSpdkServer.store.Set(testOperationName, &testOperation)
+			_ = testEnv.opiSpdkServer.store.Set(testDoneOperationName, &testDoneOperation)
+			_ = testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystemWithStatus)
+
+			request := &longrunningpb.ListOperationsRequest{Name: "operations", Filter: tt.filter, PageSize: tt.size, PageToken: tt.token}
+			response, err := testEnv.client.ListOperations(testEnv.ctx, request)
+
+			if !utils.EqualProtoSlices(response.GetOperations(), tt.out) {
+				t.Error("response: expected", tt.out, "received", response.GetOperations())
+			}
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+		})
+	}
+}
+
+func TestFrontEnd_CancelOperation(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		out     *emptypb.Empty
+		errCode codes.Code
+		errMsg  string
+	}{
+		"running operation": {
+			in:      testOperationName,
+			out:     &emptypb.Empty{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"done operation": {
+			in:      testDoneOperationName,
+			out:     &emptypb.Empty{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"valid request with unknown key": {
+			in:      "operations/unknown-operation-id",
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", "operations/unknown-operation-id"),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			cancelled := false
+			testEnv.opiSpdkServer.operations[testOperationName] = &operation{
+				cancel: func() { cancelled = true },
+				done:   make(chan struct{}),
+			}
+			_ = testEnv.opiSpdkServer.store.Set(testOperationName, &testOperation)
+			_ = testEnv.opiSpdkServer.store.Set(testDoneOperationName, &testDoneOperation)
+
+			request := &longrunningpb.CancelOperationRequest{Name: tt.in}
+			response, err := testEnv.client.CancelOperation(testEnv.ctx, request)
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+
+			if reflect.TypeOf(response) != reflect.TypeOf(tt.out) {
+				t.Error("response: expected", reflect.TypeOf(tt.out), "received", reflect.TypeOf(response))
+			}
+
+			if cancelled != (tt.in == testOperationName) {
+				t.Error("cancelled: expected", tt.in == testOperationName, "received", cancelled)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_DeleteOperation(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		out     *emptypb.Empty
+		errCode codes.Code
+		errMsg  string
+	}{
+		"valid request": {
+			in:      testDoneOperationName,
+			out:     &emptypb.Empty{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"valid request with unknown key": {
+			in:      "operations/unknown-operation-id",
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", "operations/unknown-operation-id"),
+		},
+		"malformed name": {
+			in:      "operations/-ABC-DEF",
+			out:     nil,
+			errCode: codes.Unknown,
+			errMsg:  fmt.Sprintf("segment '%s': not a valid DNS name", "-ABC-DEF"),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			testEnv.opiSpdkServer.ListHelper[testDoneOperationName] = false
+			_ = testEnv.opiSpdkServer.store.Set(testDoneOperationName, &testDoneOperation)
+
+			request := &longrunningpb.DeleteOperationRequest{Name: tt.in}
+			response, err := testEnv.client.DeleteOperation(testEnv.ctx, request)
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+
+			if reflect.TypeOf(response) != reflect.TypeOf(tt.out) {
+				t.Error("response: expected", reflect.TypeOf(tt.out), "received", reflect.TypeOf(response))
+			}
+		})
+	}
+}
+
+func TestFrontEnd_WaitOperation(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		timeout *durationpb.Duration
+		out     *longrunningpb.Operation
+		errCode codes.Code
+		errMsg  string
+	}{
+		"done operation": {
+			in:      testDoneOperationName,
+			timeout: nil,
+			out:     &testDoneOperation,
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"timeout expired": {
+			in:      testOperationName,
+			timeout: durationpb.New(0),
+			out:     &testOperation,
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"negative timeout": {
+			in:      testOperationName,
+			timeout: durationpb.New(-1),
+			out:     nil,
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Timeout value (%v) is negative", "-1ns"),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			testEnv.opiSpdkServer.operations[testOperationName] = &operation{
+				cancel: func() {},
+				done:   make(chan struct{}),
+			}
+			_ = testEnv.opiSpdkServer.store.Set(testOperationName, &testOperation)
+			_ = testEnv.opiSpdkServer.store.Set(testDoneOperationName, &testDoneOperation)
+
+			request := &longrunningpb.WaitOperationRequest{Name: tt.in, Timeout: tt.timeout}
+			response, err := testEnv.client.WaitOperation(testEnv.ctx, request)
+
+			if !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+
+			if er, ok := status.FromError(err); ok {
+				if er.Code() != tt.errCode {
+					t.Error("error code: expected", tt.errCode, "received", er.Code())
+				}
+				if er.Message() != tt.errMsg {
+					t.Error("error message: expected", tt.errMsg, "received", er.Message())
+				}
+			} else {
+				t.Error("expected grpc error status")
+			}
+		})
+	}
+}
+
+func TestFrontEnd_OperationTenants(t *testing.T) {
+	unknown := fmt.Sprintf("unable to find key %v", testOperationName)
+	tests := map[string]struct {
+		tenant  string
+		call    func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out     proto.Message
+		errCode codes.Code
+		errMsg  string
+	}{
+		"get operation of the tenant": {
+			tenant: "tenant-a",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: testOperationName})
+			},
+			out:     &testOperation,
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"get operation of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: testOperationName})
+			},
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  unknown,
+		},
+		"get operation of the operator": {
+			tenant: "tenant-a",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetOperation(ctx, &longrunningpb.GetOperationRequest{Name: testDoneOperationName})
+			},
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testDoneOperationName),
+		},
+		"list operations of the tenant": {
+			tenant: "tenant-a",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListOperations(ctx, &longrunningpb.ListOperationsRequest{Name: "operations"})
+			},
+			out:     &longrunningpb.ListOperationsResponse{Operations: []*longrunningpb.Operation{&testOperation}},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list operations of the operator": {
+			tenant: "",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListOperations(ctx, &longrunningpb.ListOperationsRequest{Name: "operations"})
+			},
+			out:     &longrunningpb.ListOperationsResponse{Operations: []*longrunningpb.Operation{&testDoneOperation, &testOperation}},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"delete operation of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.DeleteOperation(ctx, &longrunningpb.DeleteOperationRequest{Name: testOperationName})
+			},
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  unknown,
+		},
+		"cancel operation of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CancelOperation(ctx, &longrunningpb.CancelOperationRequest{Name: testOperationName})
+			},
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  unknown,
+		},
+		"wait operation of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.WaitOperation(ctx, &longrunningpb.WaitOperationRequest{Name: testOperationName, Timeout: durationpb.New(0)})
+			},
+			out:     nil,
+			errCode: codes.NotFound,
+			errMsg:  unknown,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			for _, op := range []*longrunningpb.Operation{&testOperation, &testDoneOperation} {
+				if err := s.store.Set(op.Name, op); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[op.Name] = false
+			}
+			if err := s.setOperationOwner(testOperationName, "tenant-a"); err != nil {
+				t.Fatal(err)
+			}
+
+			ctx := testEnv.ctx
+			if tt.tenant != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, tenantKey, tt.tenant)
+			}
+			response, err := tt.call(ctx, testEnv.client)
+
+			if tt.out == nil {
+				if err == nil {
+					t.Error("response: expected error, received", response)
+				}
+			} else if !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			checkTenantError(t, err, tt.errCode, tt.errMsg)
+			found, err := s.store.Get(testOperationName, new(longrunningpb.Operation))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !found {
+				t.Error("expected the operation of the tenant to be kept")
+			}
+		})
+	}
+}
+
+func TestFrontEnd_CreateNvmeSubsystemOperation(t *testing.T) {
+	testEnv := createTestEnvironment([]string{
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+		`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v20.10","fields":{"major":20,"minor":10,"patch":0,"suffix":""}}}`,
+	})
+	defer testEnv.Close()
+
+	ctx := metadata.AppendToOutgoingContext(testEnv.ctx, asyncOperationKey, "true")
+	request := &pb.CreateNvmeSubsystemRequest{NvmeSubsystem: &testSubsystem, NvmeSubsystemId: testSubsystemID}
+	var header metadata.MD
+	response, err := testEnv.client.CreateNvmeSubsystem(ctx, request, grpc.Header(&header))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if response.Name != testSubsystemName || response.Status != nil {
+		t.Error("response: expected pending", testSubsystemName, "received", response)
+	}
+	names := header.Get(operationHeaderKey)
+	if len(names) != 1 {
+		t.Fatal("expected operation name in header, received", header)
+	}
+
+	op, err := testEnv.client.WaitOperation(testEnv.ctx, &longrunningpb.WaitOperationRequest{Name: names[0]})
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	if !op.Done {
+		t.Fatal("operation is not done", op)
+	}
+	expected := &pb.NvmeSubsystem{
+		Name:   testSubsystemName,
+		Spec:   testSubsystem.Spec,
+		Status: &pb.NvmeSubsystemStatus{FirmwareRevision: "SPDK v20.10"},
+	}
+	result, _ := anypb.New(expected)
+	if !proto.Equal(op.GetResponse(), result) {
+		t.Error("response: expected", result, "received", op.GetResponse())
+	}
+	stored := new(pb.NvmeSubsystem)
+	found, _ := testEnv.opiSpdkServer.store.Get(testSubsystemName, stored)
+	if !found || !proto.Equal(stored, expected) {
+		t.Error("stored: expected", expected, "received", stored)
+	}
+	if running, _ := testEnv.opiSpdkServer.runningOperations(); len(running) != 0 {
+		t.Error("running operations: expected none, received", running)
+	}
+}
+
+func TestFrontEnd_InterruptedOperations(t *testing.T) {
+	interrupted := &longrunningpb.Operation{
+		Name: testOperationName,
+		Done: true,
+		Result: &longrunningpb.Operation_Error{
+			Error: status.New(codes.Aborted, "operation interrupted by a restart of the server").Proto(),
+		},
+	}
+	tests := map[string]struct {
+		in     *longrunningpb.Operation
+		out    *longrunningpb.Operation
+		listed bool
+	}{
+		"running operation failed": {
+			in:     &testOperation,
+			out:    interrupted,
+			listed: true,
+		},
+		"done operation kept": {
+			in:     &testDoneOperation,
+			out:    &testDoneOperation,
+			listed: true,
+		},
+		"deleted operation forgotten": {
+			in:     nil,
+			out:    nil,
+			listed: false,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			name := testOperationName
+			if tt.in != nil {
+				name = tt.in.Name
+				if err := s.store.Set(name, tt.in); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if err := s.setOperationRunning(name, true); err != nil {
+				t.Fatal(err)
+			}
+
+			// the next instance of the server shares the store
+			restarted := NewServer(testEnv.jsonRPC, s.store.(*watchedStore).Store)
+
+			if tt.out != nil {
+				op := new(longrunningpb.Operation)
+				if _, err := restarted.store.Get(name, op); err != nil {
+					t.Fatal(err)
+				}
+				if !proto.Equal(op, tt.out) {
+					t.Error("operation: expected", tt.out, "received", op)
+				}
+			}
+			if restarted.isListed(name) != tt.listed {
+				t.Error("listed: expected", tt.listed, "received", restarted.isListed(name))
+			}
+			running, err := restarted.runningOperations()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(running) != 0 {
+				t.Error("running operations: expected none, received", running)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_ListHelperConcurrency(t *testing.T) {
+	testEnv := createTestEnvironment([]string{})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+
+	// the operations write ListHelper while the handlers range over it
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("operations/operation-%d-%d", i, j)
+				s.addListed(key)
+				_ = s.listedKeys()
+				s.setPageToken(key, j)
+				s.deleteListed(key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if keys := s.listedKeys(); len(keys) != 0 {
+		t.Error("keys: expected none, received", keys)
+	}
+}
+
+func TestFrontEnd_AsyncOperationsDisabled(t *testing.T) {
+	testEnv := createTestEnvironment([]string{
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+		`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v20.10","fields":{"major":20,"minor":10,"patch":0,"suffix":""}}}`,
+	})
+	defer testEnv.Close()
+	testEnv.opiSpdkServer.opts.AsyncOperations = false
+
+	ctx := metadata.AppendToOutgoingContext(testEnv.ctx, asyncOperationKey, "true")
+	request := &pb.CreateNvmeSubsystemRequest{NvmeSubsystem: &testSubsystem, NvmeSubsystemId: testSubsystemID}
+	var header metadata.MD
+	response, err := testEnv.client.CreateNvmeSubsystem(ctx, request, grpc.Header(&header))
+	if err != nil {
+		t.Fatal("unexpected error", err)
+	}
+	expected := &pb.NvmeSubsystem{
+		Name:   testSubsystemName,
+		Spec:   testSubsystem.Spec,
+		Status: &pb.NvmeSubsystemStatus{FirmwareRevision: "SPDK v20.10"},
+	}
+	if !proto.Equal(response, expected) {
+		t.Error("response: expected", expected, "received", response)
+	}
+	if names := header.Get(operationHeaderKey); len(names) != 0 {
+		t.Error("expected no operation, received", names)
+	}
+}
+
+func TestFrontEnd_Shutdown(t *testing.T) {
+	tests := map[string]struct {
+		running   bool
+		finishing bool
+		cancelled bool
+		errCode   codes.Code
+	}{
+		"no running operation": {
+			running:   false,
+			finishing: false,
+			cancelled: false,
+			errCode:   codes.OK,
+		},
+		"operation finishing in time": {
+			running:   true,
+			finishing: true,
+			cancelled: false,
+			errCode:   codes.OK,
+		},
+		"operation not finishing in time": {
+			running:   true,
+			finishing: false,
+			cancelled: true,
+			errCode:   codes.DeadlineExceeded,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+
+			cancelled := false
+			if tt.running {
+				op := &operation{done: make(chan struct{})}
+				// a cancelled operation rolls back and finishes
+				op.cancel = func() {
+					cancelled = true
+					close(op.done)
+				}
+				if tt.finishing {
+					close(op.done)
+				}
+				testEnv.opiSpdkServer.operations[testOperationName] = op
+			}
+
+			ctx, cancel := context.WithTimeout(testEnv.ctx, 10*time.Millisecond)
+			defer cancel()
+			err := testEnv.opiSpdkServer.Shutdown(ctx)
+			if status.FromContextError(err).Code() != tt.errCode {
+				t.Error("error code: expected", tt.errCode, "received", err)
+			}
+			if cancelled != tt.cancelled {
+				t.Error("cancelled: expected", tt.cancelled, "received", cancelled)
+			}
+
+			// new operations and watches are rejected
+			_, err = testEnv.opiSpdkServer.startOperation(testEnv.ctx, "CreateNvmeSubsystem", testSubsystemName, nil)
+			if status.Code(err) != codes.Unavailable {
+				t.Error("operation error code: expected", codes.Unavailable, "received", err)
+			}
+			stream, err := testEnv.client.WatchNvmeResources(testEnv.ctx, &mb.WatchNvmeResourcesRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := stream.Recv(); status.Code(err) != codes.Unavailable {
+				t.Error("watch error code: expected", codes.Unavailable, "received", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/longrunning/autogen/longrunningpb"
+	"go.einride.tech/aip/resourcename"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) validateOperationName(name string) error {
+	// longrunning protos carry no field_behavior annotations, check by hand
+	if name == "" {
+		return status.Error(codes.InvalidArgument, "missing required field: name")
+	}
+	if !strings.HasPrefix(name, "operations/") {
+		msg := fmt.Sprintf("Operation name (%s) must start with operations/", name)
+		return status.Errorf(codes.InvalidArgument, msg)
+	}
+	// Validate that a resource name conforms to the restrictions outlined in AIP-122.
+	return resourcename.Validate(name)
+}
+
+// validateListOperationsRequest returns the requested done state, the only
+// supported filter, or nil when all operations are requested
+func (s *Server) validateListOperationsRequest(in *longrunningpb.ListOperationsRequest) (*bool, error) {
+	if in.Name != "" && in.Name != "operations" {
+		msg := fmt.Sprintf("Operation collection (%s) is not supported, use operations", in.Name)
+		return nil, status.Errorf(codes.InvalidArgument, msg)
+	}
+	if in.Filter == "" {
+		return nil, nil
+	}
+	filter := strings.ReplaceAll(in.Filter, " ", "")
+	if !strings.HasPrefix(filter, "done=") {
+		msg := fmt.Sprintf("Filter (%s) is not supported, use done=true or done=false", in.Filter)
+		return nil, status.Errorf(codes.InvalidArgument, msg)
+	}
+	done, err := strconv.ParseBool(strings.TrimPrefix(filter, "done="))
+	if err != nil {
+		msg := fmt.Sprintf("Filter (%s) is not supported, use done=true or done=false", in.Filter)
+		return nil, status.Errorf(codes.InvalidArgument, msg)
+	}
+	return &done, nil
+}
+
+func (s *Server) validateWaitOperationRequest(in *longrunningpb.WaitOperationRequest) (time.Duration, error) {
+	if err := s.validateOperationName(in.Name); err != nil {
+		return 0, err
+	}
+	if in.Timeout == nil {
+		return defaultWaitOperationTimeout, nil
+	}
+	if err := in.Timeout.CheckValid(); err != nil {
+		return 0, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if in.Timeout.AsDuration() < 0 {
+		msg := fmt.Sprintf("Timeout value (%v) is negative", in.Timeout.AsDuration())
+		return 0, status.Errorf(codes.InvalidArgument, msg)
+	}
+	return in.Timeout.AsDuration(), nil
+}
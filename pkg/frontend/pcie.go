@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// PcieFunction is a PCIe physical function available to the emulated Nvme
+// controllers, with its virtual functions 1 to VirtualFunctions and the
+// budget of queues of all its controllers, zero is unlimited
+type PcieFunction struct {
+	PortID           int
+	PhysicalFunction int
+	VirtualFunctions int
+	MaxNsq           int
+	MaxNcq           int
+}
+
+// pcieEndpoint identifies a PCIe function, VF 0 is the physical function
+type pcieEndpoint struct {
+	port int
+	pf   int
+	vf   int
+}
+
+func (e pcieEndpoint) String() string {
+	return fmt.Sprintf("port %d pf %d vf %d", e.port, e.pf, e.vf)
+}
+
+func (e pcieEndpoint) proto() *pb.PciEndpoint {
+	return &pb.PciEndpoint{
+		PortId:           wrapperspb.Int32(int32(e.port)),
+		PhysicalFunction: wrapperspb.Int32(int32(e.pf)),
+		VirtualFunction:  wrapperspb.Int32(int32(e.vf)),
+	}
+}
+
+func pcieEndpointOf(in *pb.PciEndpoint) pcieEndpoint {
+	return pcieEndpoint{
+		port: int(in.GetPortId().GetValue()),
+		pf:   int(in.GetPhysicalFunction().GetValue()),
+		vf:   int(in.GetVirtualFunction().GetValue()),
+	}
+}
+
+// pcieKey is the store key of the reservation of a PCIe function by an
+// Nvme controller
+func pcieKey(e pcieEndpoint) string {
+	return fmt.Sprintf("pcie/%d/%d/%d", e.port, e.pf, e.vf)
+}
+
+// inPcieInventory checks if the PCIe function is in the inventory, all
+// functions are when there is no inventory
+func (s *Server) inPcieInventory(e pcieEndpoint) bool {
+	if len(s.opts.PcieFunctions) == 0 {
+		return true
+	}
+	for _, f := range s.opts.PcieFunctions {
+		if f.PortID == e.port && f.PhysicalFunction == e.pf && e.vf >= 0 && e.vf <= f.VirtualFunctions {
+			return true
+		}
+	}
+	return false
+}
+
+// pcieEndpointAssignable checks if the endpoint of the controller spec can
+// be assigned from the inventory
+func (s *Server) pcieEndpointAssignable(spec *pb.NvmeControllerSpec) bool {
+	return spec.GetEndpoint() == nil && len(s.opts.PcieFunctions) != 0
+}
+
+// pcieFunctionUser returns the controller reserving a PCIe function, empty
+// if it is free
+func (s *Server) pcieFunctionUser(e pcieEndpoint) (string, error) {
+	user := new(wrapperspb.StringValue)
+	if _, err := s.store.Get(pcieKey(e), user); err != nil {
+		return "", err
+	}
+	return user.Value, nil
+}
+
+// freeVirtualFunction returns the first free VF of the inventory
+func (s *Server) freeVirtualFunction() (pcieEndpoint, error) {
+	for _, f := range s.opts.PcieFunctions {
+		for vf := 1; vf <= f.VirtualFunctions; vf++ {
+			e := pcieEndpoint{port: f.PortID, pf: f.PhysicalFunction, vf: vf}
+			user, err := s.pcieFunctionUser(e)
+			if err != nil {
+				return pcieEndpoint{}, err
+			}
+			if user == "" {
+				return e, nil
+			}
+		}
+	}
+	return pcieEndpoint{}, status.Errorf(codes.ResourceExhausted, "no free PCIe virtual function")
+}
+
+// reservePcieFunction reserves the PCIe function requested for the
+// controller name and returns it. A free VF is assigned when the endpoint
+// is omitted and there is an inventory.
+func (s *Server) reservePcieFunction(name string, requested *pb.PciEndpoint) (*pb.PciEndpoint, error) {
+	s.pcieMutex.Lock()
+	defer s.pcieMutex.Unlock()
+	e := pcieEndpointOf(requested)
+	switch {
+	case len(s.opts.PcieFunctions) == 0 || requested != nil:
+		if !s.inPcieInventory(e) {
+			return nil, status.Errorf(codes.InvalidArgument, "PCIe function %s is not in the inventory", e)
+		}
+		user, err := s.pcieFunctionUser(e)
+		if err != nil {
+			return nil, err
+		}
+		if user != "" && user != name {
+			return nil, status.Errorf(codes.AlreadyExists, "PCIe function %s is already used by %s", e, user)
+		}
+	default:
+		free, err := s.freeVirtualFunction()
+		if err != nil {
+			return nil, err
+		}
+		e = free
+	}
+	if err := s.store.Set(pcieKey(e), wrapperspb.String(name)); err != nil {
+		return nil, err
+	}
+	return e.proto(), nil
+}
+
+// releasePcieFunctionOrLog frees the PCIe function reserved by the
+// controller name, only logging the failures
+func (s *Server) releasePcieFunctionOrLog(ctx context.Context, name string, endpoint *pb.PciEndpoint) {
+	if err := s.releasePcieFunction(name, endpoint); err != nil {
+		logger.ErrorContext(ctx, "Could not release the PCIe function", "name", name, "error", err)
+	}
+}
+
+// releasePcieFunction frees the PCIe function reserved by the controller
+// name
+func (s *Server) releasePcieFunction(name string, endpoint *pb.PciEndpoint) error {
+	s.pcieMutex.Lock()
+	defer s.pcieMutex.Unlock()
+	e := pcieEndpointOf(endpoint)
+	user, err := s.pcieFunctionUser(e)
+	if err != nil {
+		return err
+	}
+	if user != name {
+		return nil
+	}
+	return s.store.Delete(pcieKey(e))
+}
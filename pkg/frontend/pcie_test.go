@@ -0,0 +1,199 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_PcieFunctions(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	newControllerName := utils.ResourceIDToControllerName(testSubsystemID, "controller-new")
+	newController := func(endpoint *pb.PciEndpoint) *pb.NvmeController {
+		spec := &pb.NvmeControllerSpec{
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: proto.Int32(18),
+		}
+		if endpoint != nil {
+			spec.Endpoint = &pb.NvmeControllerSpec_PcieId{PcieId: endpoint}
+		}
+		return &pb.NvmeController{Spec: spec}
+	}
+	assignedController := &pb.NvmeController{
+		Name: newControllerName,
+		Spec: &pb.NvmeControllerSpec{
+			Endpoint:         &pb.NvmeControllerSpec_PcieId{PcieId: pcieEndpoint{port: 0, pf: 1, vf: 1}.proto()},
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: proto.Int32(18),
+		},
+		Status: &pb.NvmeControllerStatus{Active: true},
+	}
+	inventory := []PcieFunction{{PortID: 0, PhysicalFunction: 0, VirtualFunctions: 0}, {PortID: 0, PhysicalFunction: 1, VirtualFunctions: 3}}
+
+	tests := map[string]struct {
+		inventory []PcieFunction
+		reserved  map[pcieEndpoint]string
+		call      func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out       proto.Message
+		spdk      []string
+		errCode   codes.Code
+		errMsg    string
+		users     map[pcieEndpoint]string
+	}{
+		"assign a free VF": {
+			inventory: inventory,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: newController(nil), NvmeControllerId: "controller-new"})
+			},
+			out:     assignedController,
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+			users: map[pcieEndpoint]string{
+				{port: 0, pf: 1, vf: 1}: newControllerName,
+				{port: 0, pf: 1, vf: 2}: testControllerName,
+			},
+		},
+		"no free VF": {
+			inventory: inventory,
+			reserved: map[pcieEndpoint]string{
+				{port: 0, pf: 1, vf: 1}: "nvmeSubsystems/subsystem-test/nvmeControllers/controller-1",
+				{port: 0, pf: 1, vf: 3}: "nvmeSubsystems/subsystem-test/nvmeControllers/controller-3",
+			},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: newController(nil), NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "no free PCIe virtual function",
+			users: map[pcieEndpoint]string{
+				{port: 0, pf: 1, vf: 1}: "nvmeSubsystems/subsystem-test/nvmeControllers/controller-1",
+				{port: 0, pf: 1, vf: 2}: testControllerName,
+				{port: 0, pf: 1, vf: 3}: "nvmeSubsystems/subsystem-test/nvmeControllers/controller-3",
+			},
+		},
+		"function already used": {
+			inventory: nil,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: newController(testController.Spec.GetPcieId()), NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.AlreadyExists,
+			errMsg:  fmt.Sprintf("PCIe function port 0 pf 1 vf 2 is already used by %v", testControllerName),
+			users:   map[pcieEndpoint]string{{port: 0, pf: 1, vf: 2}: testControllerName},
+		},
+		"function not in the inventory": {
+			inventory: inventory,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				endpoint := pcieEndpoint{port: 0, pf: 1, vf: 4}.proto()
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: newController(endpoint), NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.InvalidArgument,
+			errMsg:  "PCIe function port 0 pf 1 vf 4 is not in the inventory",
+			users:   map[pcieEndpoint]string{{port: 0, pf: 1, vf: 2}: testControllerName},
+		},
+		"omitted endpoint without inventory": {
+			inventory: nil,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: newController(nil), NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.Unknown,
+			errMsg:  "invalid endpoint type passed for transport",
+			users:   map[pcieEndpoint]string{{port: 0, pf: 1, vf: 2}: testControllerName},
+		},
+		"release on SDK failure": {
+			inventory: inventory,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: newController(nil), NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":1}}`},
+			errCode: codes.InvalidArgument,
+			errMsg:  fmt.Sprintf("Could not create CTRL: %v", newControllerName),
+			users:   map[pcieEndpoint]string{{port: 0, pf: 1, vf: 2}: testControllerName},
+		},
+		"release on delete": {
+			inventory: inventory,
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.DeleteNvmeController(ctx, &pb.DeleteNvmeControllerRequest{Name: testControllerName})
+			},
+			out:     nil,
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+			users:   map[pcieEndpoint]string{},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+			s.opts.PcieFunctions = tt.inventory
+
+			testEnv.opiSpdkServer.store.Set(testSubsystemName, &testSubsystemWithStatus)
+			testEnv.opiSpdkServer.store.Set(testControllerName, &testControllerWithStatus)
+			testEnv.opiSpdkServer.ListHelper[testControllerName] = false
+			if _, err := s.reservePcieFunction(testControllerName, testController.Spec.GetPcieId()); err != nil {
+				t.Fatal(err)
+			}
+			for e, user := range tt.reserved {
+				if err := s.store.Set(pcieKey(e), wrapperspb.String(user)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			response, err := tt.call(testEnv.ctx, testEnv.client)
+
+			if tt.out != nil && !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Fatal("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+
+			users := map[pcieEndpoint]string{}
+			for _, f := range inventory {
+				for vf := 0; vf <= f.VirtualFunctions; vf++ {
+					e := pcieEndpoint{port: f.PortID, pf: f.PhysicalFunction, vf: vf}
+					user, err := s.pcieFunctionUser(e)
+					if err != nil {
+						t.Fatal(err)
+					}
+					if user != "" {
+						users[e] = user
+					}
+				}
+			}
+			if !reflect.DeepEqual(users, tt.users) {
+				t.Error("PCIe functions: expected", tt.users, "received", users)
+			}
+		})
+	}
+}
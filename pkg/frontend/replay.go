@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// replayNvmeSubsystem re-creates a stored subsystem missing in the SDK, e.g.
+// after an SDK restart, with its namespaces and controllers. The controllers
+// keep their stored IDs and PCIe functions, and the enabled namespaces are
+// attached back to the active controllers. If any call fails, the replayed
+// resources are removed again, so that the next replay starts over.
+func (s *Server) replayNvmeSubsystem(ctx context.Context, name string) error {
+	// fetch object from the database
+	subsys := new(pb.NvmeSubsystem)
+	found, err := s.store.Get(name, subsys)
+	if err != nil {
+		return err
+	}
+	if !found {
+		// deleted meanwhile, nothing to replay
+		return nil
+	}
+	ctrlrIDs, err := s.subsystemCtrlrIDRange(name)
+	if err != nil {
+		return err
+	}
+	namespaces, err := s.subsystemNamespaces(subsys)
+	if err != nil {
+		return err
+	}
+	controllers, err := s.subsystemControllers(subsys)
+	if err != nil {
+		return err
+	}
+	logger.WarnContext(ctx, "Replaying NvmeSubsystem missing in the SDK", "name", name,
+		"namespaces", len(namespaces), "controllers", len(controllers))
+
+	params := models.MrvlNvmCreateSubsystemParams{
+		Subnqn:        subsys.Spec.Nqn,
+		Mn:            subsys.Spec.ModelNumber,
+		Sn:            subsys.Spec.SerialNumber,
+		MaxNamespaces: int(subsys.Spec.MaxNamespaces),
+		MinCtrlrID:    int(ctrlrIDs.MinCtrlrId),
+		MaxCtrlrID:    int(ctrlrIDs.MaxCtrlrId),
+	}
+	var result models.MrvlNvmCreateSubsystemResult
+	err = s.rpc.Call(ctx, "mrvl_nvm_create_subsystem", &params, &result)
+	if err == nil && result.Status != 0 {
+		msg := fmt.Sprintf("Could not create NQN: %s", subsys.Spec.Nqn)
+		err = status.Errorf(codes.InvalidArgument, msg)
+	}
+	if err != nil {
+		return err
+	}
+	// the replayed resources are removed again in reverse order on failure
+	undo := []func(){func() { s.rollbackNvmeSubsystemReplay(ctx, subsys) }}
+	rollback := func() {
+		for i := len(undo) - 1; i >= 0; i-- {
+			undo[i]()
+		}
+	}
+	for _, namespace := range namespaces {
+		namespace := namespace
+		nsInstanceID, err := s.replayNvmeNamespace(ctx, subsys, namespace)
+		if err != nil {
+			rollback()
+			return err
+		}
+		undo = append(undo, func() { s.rollbackNvmeNamespaceCreate(ctx, subsys, namespace, nsInstanceID, nil) })
+	}
+	for _, controller := range controllers {
+		controller := controller
+		params := storedCtrlrParams(subsys, controller)
+		var result models.MrvlNvmSubsysCreateCtrlrResult
+		err := s.rpc.Call(ctx, "mrvl_nvm_subsys_create_ctrlr", &params, &result)
+		if err == nil && result.Status != 0 {
+			msg := fmt.Sprintf("Could not create CTRL: %s", controller.Name)
+			err = status.Errorf(codes.InvalidArgument, msg)
+		}
+		if err != nil {
+			rollback()
+			return err
+		}
+		undo = append(undo, func() { s.rollbackNvmeControllerCreate(ctx, subsys, controller) })
+	}
+	for _, controller := range activeControllers(controllers) {
+		attachments, err := s.enabledNamespaceAttachments(subsys, controller)
+		if err != nil {
+			rollback()
+			return err
+		}
+		if err := s.setAttached(ctx, subsys, attachments, true); err != nil {
+			rollback()
+			return err
+		}
+		undo = append(undo, func() { s.undoAttached(ctx, subsys, attachments, true) })
+	}
+	logger.InfoContext(ctx, "Replayed NvmeSubsystem", "name", name)
+	return nil
+}
+
+// replayNvmeNamespace allocates again the SDK namespace of a stored
+// namespace, and records the ns_instance_id picked by the SDK
+func (s *Server) replayNvmeNamespace(ctx context.Context, subsys *pb.NvmeSubsystem, namespace *pb.NvmeNamespace) (int, error) {
+	bdev, err := s.namespaceBdev(ctx, namespace.Spec)
+	if err != nil {
+		return 0, err
+	}
+	params := models.MrvlNvmSubsysAllocNsParams{
+		Subnqn:      subsys.Spec.Nqn,
+		Nguid:       namespace.Spec.Nguid,
+		Eui64:       formatEui64(namespace.Spec.Eui64),
+		UUID:        namespace.Spec.Uuid,
+		ShareEnable: shareEnable(s.opts.ShareNamespaces),
+		Bdev:        bdev,
+	}
+	var result models.MrvlNvmSubsysAllocNsResult
+	err = s.rpc.Call(ctx, "mrvl_nvm_subsys_alloc_ns", &params, &result)
+	if err == nil && result.Status != 0 {
+		msg := fmt.Sprintf("Could not create NS: %s", namespace.Name)
+		err = status.Errorf(codes.InvalidArgument, msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	// the SDK picks the ns_instance_id, which may differ from the previous one
+	nsInstanceID := result.NsInstanceID
+	if nsInstanceID == 0 {
+		nsInstanceID = int(namespace.Spec.HostNsid)
+	}
+	if err := s.setNsInstanceID(namespace, nsInstanceID); err != nil {
+		s.rollbackNvmeNamespaceCreate(ctx, subsys, namespace, nsInstanceID, nil)
+		return 0, err
+	}
+	return nsInstanceID, nil
+}
+
+// rollbackNvmeSubsystemReplay deletes the SDK subsystem of a failed replay,
+// so that it is still reported missing
+func (s *Server) rollbackNvmeSubsystemReplay(ctx context.Context, subsys *pb.NvmeSubsystem) {
+	// the rollback must not be cancelled as well
+	ctx = context.WithoutCancel(ctx)
+	logger.WarnContext(ctx, "Rolling back failed NvmeSubsystem replay", "name", subsys.Name)
+	params := models.MrvlNvmDeleteSubsystemParams{
+		Subnqn: subsys.Spec.Nqn,
+	}
+	var result models.MrvlNvmDeleteSubsystemResult
+	err := s.rpc.Call(ctx, "mrvl_nvm_delete_subsystem", &params, &result)
+	if err != nil || result.Status != 0 {
+		logger.ErrorContext(ctx, "Could not delete NQN on rollback", "name", subsys.Name, "error", err, "status", result.Status)
+	}
+}
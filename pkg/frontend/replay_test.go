@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_ReplayNvmeSubsystem(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	done := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`
+	failed := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`
+	allocated := `{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0,"ns_instance_id": 5}}`
+	tests := map[string]struct {
+		enabled      bool
+		spdk         []string
+		methods      []string
+		nsInstanceID int
+		wantErr      bool
+	}{
+		"subsystem replayed": {
+			enabled:      true,
+			spdk:         []string{done, allocated, done, done},
+			methods:      []string{"mrvl_nvm_create_subsystem", "mrvl_nvm_subsys_alloc_ns", "mrvl_nvm_subsys_create_ctrlr", "mrvl_nvm_ctrlr_attach_ns"},
+			nsInstanceID: 5,
+			wantErr:      false,
+		},
+		"disabled namespace not attached": {
+			enabled:      false,
+			spdk:         []string{done, allocated, done},
+			methods:      []string{"mrvl_nvm_create_subsystem", "mrvl_nvm_subsys_alloc_ns", "mrvl_nvm_subsys_create_ctrlr"},
+			nsInstanceID: 5,
+			wantErr:      false,
+		},
+		"failed controller removes the replayed resources": {
+			enabled: true,
+			spdk:    []string{done, allocated, failed, done, done},
+			methods: []string{
+				"mrvl_nvm_create_subsystem", "mrvl_nvm_subsys_alloc_ns", "mrvl_nvm_subsys_create_ctrlr",
+				"mrvl_nvm_subsys_unalloc_ns", "mrvl_nvm_delete_subsystem",
+			},
+			nsInstanceID: 5,
+			wantErr:      true,
+		},
+		"failed subsystem": {
+			enabled:      true,
+			spdk:         []string{failed},
+			methods:      []string{"mrvl_nvm_create_subsystem"},
+			nsInstanceID: 22,
+			wantErr:      true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+
+			namespace := utils.ProtoClone(&testNamespaceWithStatus)
+			namespace.Status = namespaceStatus(tt.enabled)
+			for key, value := range map[string]proto.Message{
+				testSubsystemName:  &testSubsystemWithStatus,
+				testControllerName: &testControllerWithStatus,
+				testNamespaceName:  namespace,
+			} {
+				if err := s.store.Set(key, value); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[key] = false
+			}
+			rpc := &recordingJSONRPC{JSONRPC: s.rpc}
+			s.rpc = rpc
+
+			err := s.replayNvmeSubsystem(testEnv.ctx, testSubsystemName)
+
+			if (err != nil) != tt.wantErr {
+				t.Error("expected error", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(rpc.methods, tt.methods) {
+				t.Error("SDK calls: expected", tt.methods, "received", rpc.methods)
+			}
+			if len(rpc.params) > 2 {
+				// the controller keeps its stored ID and PCIe function
+				params := rpc.params[2].(*models.MrvlNvmSubsysCreateCtrlrParams)
+				if params.CtrlrID != int(testController.Spec.GetNvmeControllerId()) ||
+					params.PfID != int(testController.Spec.GetPcieId().GetPhysicalFunction().GetValue()) ||
+					params.VfID != int(testController.Spec.GetPcieId().GetVirtualFunction().GetValue()) {
+					t.Error("controller: expected the stored ID and PCIe function, received", params)
+				}
+			}
+			nsInstanceID, err := s.nsInstanceID(namespace)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if nsInstanceID != tt.nsInstanceID {
+				t.Error("ns_instance_id: expected", tt.nsInstanceID, "received", nsInstanceID)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/opiproject/gospdk/spdk"
+	"github.com/opiproject/opi-marvell-bridge/pkg/audit"
+	"github.com/opiproject/opi-marvell-bridge/pkg/logging"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/opiproject/opi-marvell-bridge/pkg/frontend"
+
+var spdkLogger = logging.Logger("spdk")
+
+// tracedJSONRPC wraps every Marvell SDK call in a client span and records
+// its latency and failures
+type tracedJSONRPC struct {
+	spdk.JSONRPC
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+}
+
+func newTracedJSONRPC(rpc spdk.JSONRPC, tp trace.TracerProvider, mp metric.MeterProvider) *tracedJSONRPC {
+	meter := mp.Meter(instrumentationName)
+	duration, err := meter.Float64Histogram("marvell.sdk.call.duration",
+		metric.WithDescription("Duration of Marvell SDK JSON-RPC calls"),
+		metric.WithUnit("s"))
+	if err != nil {
+		log.Panic(err)
+	}
+	errors, err := meter.Int64Counter("marvell.sdk.call.errors",
+		metric.WithDescription("Marvell SDK JSON-RPC calls failed or returned a non-zero status"))
+	if err != nil {
+		log.Panic(err)
+	}
+	return &tracedJSONRPC{
+		JSONRPC:  rpc,
+		tracer:   tp.Tracer(instrumentationName),
+		duration: duration,
+		errors:   errors,
+	}
+}
+
+// Call calls the SDK method within a child span of ctx and reports it to
+// the audit record of the RPC being served
+func (r *tracedJSONRPC) Call(ctx context.Context, method string, args, result interface{}) error {
+	methodAttr := attribute.String("rpc.method", method)
+	ctx, span := r.tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(methodAttr, attribute.String("rpc.system", "jsonrpc")),
+		trace.WithAttributes(sdkCallAttributes(args)...),
+	)
+	defer span.End()
+
+	start := time.Now()
+	err := r.JSONRPC.Call(ctx, method, args, result)
+	elapsed := time.Since(start)
+	r.duration.Record(ctx, elapsed.Seconds(), metric.WithAttributes(methodAttr))
+
+	if err != nil {
+		spdkLogger.WarnContext(ctx, "SDK call failed", "method", method, "params", args, "error", err, "duration", elapsed)
+		span.RecordError(err)
+		span.SetStatus(otelcodes.Error, err.Error())
+		r.errors.Add(ctx, 1, metric.WithAttributes(methodAttr))
+		audit.AddSdkCall(ctx, method, args, nil, err)
+		return err
+	}
+	spdkLogger.DebugContext(ctx, "Received from SPDK", "method", method, "params", args, "result", result, "duration", elapsed)
+	var sdkStatus *int64
+	if value, ok := jsonField(result, "status"); ok && value.CanInt() {
+		sdkStatus = new(int64)
+		*sdkStatus = value.Int()
+		span.SetAttributes(attribute.Int64("marvell.status", value.Int()))
+		if value.Int() != 0 {
+			span.SetStatus(otelcodes.Error, fmt.Sprintf("status %d", value.Int()))
+			r.errors.Add(ctx, 1, metric.WithAttributes(methodAttr))
+		}
+	}
+	audit.AddSdkCall(ctx, method, args, sdkStatus, nil)
+	return nil
+}
+
+// sdkCallAttributes returns the resource identifiers found in SDK call params
+func sdkCallAttributes(args interface{}) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{}
+	if value, ok := jsonField(args, "subnqn"); ok && value.Kind() == reflect.String {
+		attrs = append(attrs, attribute.String("marvell.subsystem.nqn", value.String()))
+	}
+	if value, ok := jsonField(args, "ctrlr_id"); ok && value.CanInt() {
+		attrs = append(attrs, attribute.Int64("marvell.controller.id", value.Int()))
+	}
+	if value, ok := jsonField(args, "ns_instance_id"); ok && value.CanInt() {
+		attrs = append(attrs, attribute.Int64("marvell.namespace.id", value.Int()))
+	}
+	return attrs
+}
+
+// jsonField returns the field of the struct pointed to by v, tagged with the json name
+func jsonField(v interface{}, name string) (reflect.Value, bool) {
+	value := reflect.ValueOf(v)
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			return reflect.Value{}, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}, false
+	}
+	for i := 0; i < value.NumField(); i++ {
+		tag, _, _ := strings.Cut(value.Type().Field(i).Tag.Get("json"), ",")
+		if tag == name {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
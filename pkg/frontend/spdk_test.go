@@ -6,9 +6,121 @@
 package frontend
 
 import (
+	"context"
+	"log"
+	"os"
+	"reflect"
 	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
 )
 
-func TestSpdk_Call(_ *testing.T) {
+func TestSpdk_Call(t *testing.T) {
+	tests := map[string]struct {
+		spdk       []string
+		args       interface{}
+		attributes []attribute.KeyValue
+		status     otelcodes.Code
+		errors     int64
+	}{
+		"valid controller call": {
+			spdk: []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`},
+			args: &models.MrvlNvmGetCtrlrStatsParams{Subnqn: "nqn.2022-09.io.spdk:opi3", CtrlrID: 17},
+			attributes: []attribute.KeyValue{
+				attribute.String("rpc.method", "mrvl_nvm_get_ctrlr_stats"),
+				attribute.String("rpc.system", "jsonrpc"),
+				attribute.String("marvell.subsystem.nqn", "nqn.2022-09.io.spdk:opi3"),
+				attribute.Int64("marvell.controller.id", 17),
+				attribute.Int64("marvell.status", 0),
+			},
+			status: otelcodes.Unset,
+			errors: 0,
+		},
+		"valid namespace call with invalid SPDK response": {
+			spdk: []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`},
+			args: &models.MrvlNvmGetNsStatsParams{SubNqn: "nqn.2022-09.io.spdk:opi3", NsInstanceID: 22},
+			attributes: []attribute.KeyValue{
+				attribute.String("rpc.method", "mrvl_nvm_get_ctrlr_stats"),
+				attribute.String("rpc.system", "jsonrpc"),
+				attribute.String("marvell.subsystem.nqn", "nqn.2022-09.io.spdk:opi3"),
+				attribute.Int64("marvell.namespace.id", 22),
+				attribute.Int64("marvell.status", 1),
+			},
+			status: otelcodes.Error,
+			errors: 1,
+		},
+		"SDK call error": {
+			spdk: []string{`{"id":%d,"error":{"code":1,"message":"myopierr"},"result":{"status": 0}}`},
+			args: &models.MrvlNvmGetCtrlrStatsParams{Subnqn: "nqn.2022-09.io.spdk:opi3", CtrlrID: 17},
+			attributes: []attribute.KeyValue{
+				attribute.String("rpc.method", "mrvl_nvm_get_ctrlr_stats"),
+				attribute.String("rpc.system", "jsonrpc"),
+				attribute.String("marvell.subsystem.nqn", "nqn.2022-09.io.spdk:opi3"),
+				attribute.Int64("marvell.controller.id", 17),
+			},
+			status: otelcodes.Error,
+			errors: 1,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testSocket := utils.GenerateSocketName("spdk")
+			ln, jsonRPC := utils.CreateTestSpdkServer(testSocket, tt.spdk)
+			defer func() {
+				utils.CloseListener(ln)
+				if err := os.RemoveAll(testSocket); err != nil {
+					log.Fatal(err)
+				}
+			}()
+
+			recorder := tracetest.NewSpanRecorder()
+			reader := sdkmetric.NewManualReader()
+			rpc := newTracedJSONRPC(jsonRPC,
+				sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+				sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+			var result models.MrvlNvmGetCtrlrStatsResult
+			_ = rpc.Call(context.Background(), "mrvl_nvm_get_ctrlr_stats", tt.args, &result)
+
+			spans := recorder.Ended()
+			if len(spans) != 1 {
+				t.Fatal("spans: expected 1 received", len(spans))
+			}
+			if spans[0].Name() != "mrvl_nvm_get_ctrlr_stats" {
+				t.Error("span name: expected mrvl_nvm_get_ctrlr_stats received", spans[0].Name())
+			}
+			if !reflect.DeepEqual(spans[0].Attributes(), tt.attributes) {
+				t.Error("span attributes: expected", tt.attributes, "received", spans[0].Attributes())
+			}
+			if spans[0].Status().Code != tt.status {
+				t.Error("span status: expected", tt.status, "received", spans[0].Status().Code)
+			}
 
+			var metrics metricdata.ResourceMetrics
+			if err := reader.Collect(context.Background(), &metrics); err != nil {
+				t.Fatal(err)
+			}
+			errors := int64(0)
+			for _, m := range metrics.ScopeMetrics[0].Metrics {
+				if sum, ok := m.Data.(metricdata.Sum[int64]); ok && m.Name == "marvell.sdk.call.errors" {
+					for _, point := range sum.DataPoints {
+						errors += point.Value
+					}
+				}
+			}
+			if errors != tt.errors {
+				t.Error("errors: expected", tt.errors, "received", errors)
+			}
+		})
+	}
 }
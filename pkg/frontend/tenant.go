@@ -0,0 +1,273 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"strings"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/auth"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// tenantKey is the request metadata key the operators of the bridge, the
+// callers without tenant, set to act on behalf of a tenant
+const tenantKey = "x-opi-tenant"
+
+// Quota limits the Nvme resources of a tenant, zero is unlimited
+type Quota struct {
+	MaxSubsystems       int
+	MaxNamespaces       int
+	MaxVfControllers    int
+	MaxSubmissionQueues int
+	MaxCompletionQueues int
+}
+
+// tenantUsage counts the Nvme resources of a tenant
+type tenantUsage struct {
+	subsystems       int
+	namespaces       int
+	vfControllers    int
+	submissionQueues int
+	completionQueues int
+}
+
+// quotaReservation is the usage of a resource being created, counted in the
+// quota of its owner until the resource is stored
+type quotaReservation struct {
+	owner string
+	usage tenantUsage
+}
+
+// tenantOf returns the tenant of the caller, empty for the operators
+func tenantOf(ctx context.Context) string {
+	if identity, ok := auth.FromContext(ctx); ok && identity.Tenant != "" {
+		return identity.Tenant
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(tenantKey); len(values) != 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// ownerKey is the store key of the tenant owning a subsystem, its
+// controllers and namespaces, or an operation
+func ownerKey(subsysName string) string {
+	return "tenants/" + subsysName
+}
+
+// subsystemOwner returns the tenant owning a subsystem, empty if none
+func (s *Server) subsystemOwner(subsysName string) (string, error) {
+	owner := new(wrapperspb.StringValue)
+	if _, err := s.store.Get(ownerKey(subsysName), owner); err != nil {
+		return "", err
+	}
+	return owner.Value, nil
+}
+
+// setSubsystemOwner records the tenant owning a new subsystem
+func (s *Server) setSubsystemOwner(subsysName string, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	return s.store.Set(ownerKey(subsysName), wrapperspb.String(tenant))
+}
+
+// ownedByCaller checks if the caller can access the Nvme resource or the
+// operation name: operators access all of them, tenants only the resources
+// of their own subsystems and the operations they started. Missing
+// resources are left to the caller to report.
+func (s *Server) ownedByCaller(ctx context.Context, name string) (bool, error) {
+	tenant := tenantOf(ctx)
+	if tenant == "" {
+		return true, nil
+	}
+	if strings.HasPrefix(name, "operations/") {
+		owner, err := s.operationOwner(name)
+		return owner == tenant, err
+	}
+	subsysName := subsystemNameOf(name)
+	owner, err := s.subsystemOwner(subsysName)
+	if err != nil {
+		return false, err
+	}
+	if owner != "" {
+		return owner == tenant, nil
+	}
+	found, err := s.store.Get(subsysName, new(pb.NvmeSubsystem))
+	if err != nil {
+		return false, err
+	}
+	return !found, nil
+}
+
+// checkOwnedByCaller returns NotFound if the caller can't access name, so
+// that the resources of other tenants are not disclosed
+func (s *Server) checkOwnedByCaller(ctx context.Context, name string) error {
+	owned, err := s.ownedByCaller(ctx, name)
+	if err != nil {
+		return err
+	}
+	if !owned {
+		return status.Errorf(codes.NotFound, "unable to find key %s", name)
+	}
+	return nil
+}
+
+// quotaOf returns the quota of tenant
+func (s *Server) quotaOf(tenant string) Quota {
+	if quota, ok := s.opts.Quotas[tenant]; ok {
+		return quota
+	}
+	return s.opts.DefaultQuota
+}
+
+// usageOf counts the Nvme resources of the subsystems owned by tenant,
+// and the ones reserved by the Create calls in progress
+func (s *Server) usageOf(tenant string) (*tenantUsage, error) {
+	usage := &tenantUsage{}
+	owners := make(map[string]string)
+	keys, err := s.storedKeys("nvmeSubsystems/")
+	if err != nil {
+		return nil, err
+	}
+	stored := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		stored[key] = true
+	}
+	for name, reservation := range s.reservations {
+		if reservation.owner != tenant || stored[name] {
+			continue
+		}
+		usage.subsystems += reservation.usage.subsystems
+		usage.namespaces += reservation.usage.namespaces
+		usage.vfControllers += reservation.usage.vfControllers
+		usage.submissionQueues += reservation.usage.submissionQueues
+		usage.completionQueues += reservation.usage.completionQueues
+	}
+	for _, key := range keys {
+		subsysName := subsystemNameOf(key)
+		owner, ok := owners[subsysName]
+		if !ok {
+			var err error
+			if owner, err = s.subsystemOwner(subsysName); err != nil {
+				return nil, err
+			}
+			owners[subsysName] = owner
+		}
+		if owner != tenant {
+			continue
+		}
+		switch {
+		case strings.Contains(key, "/nvmeNamespaces/"):
+			usage.namespaces++
+		case strings.Contains(key, "/nvmeControllers/"):
+			controller := new(pb.NvmeController)
+			found, err := s.store.Get(key, controller)
+			if err != nil {
+				return nil, err
+			}
+			if !found {
+				continue
+			}
+			if controller.GetSpec().GetPcieId().GetVirtualFunction().GetValue() > 0 {
+				usage.vfControllers++
+			}
+			usage.submissionQueues += int(controller.GetSpec().GetMaxNsq())
+			usage.completionQueues += int(controller.GetSpec().GetMaxNcq())
+		default:
+			usage.subsystems++
+		}
+	}
+	return usage, nil
+}
+
+// checkQuota returns ResourceExhausted if creating the resource name would
+// exceed the quota of its owner. Resources already created are not counted
+// twice, so that the Create calls stay idempotent. Otherwise the usage of
+// the resource stays reserved until release is called, once the resource is
+// stored or its creation failed, so that concurrent Create calls do not
+// exceed the quotas together.
+func (s *Server) checkQuota(name string, owner string, add tenantUsage) (release func(), err error) {
+	if owner == "" {
+		return func() {}, nil
+	}
+	s.quotaMutex.Lock()
+	defer s.quotaMutex.Unlock()
+	if _, ok := s.reservations[name]; ok || s.isListed(name) {
+		return func() {}, nil
+	}
+	quota := s.quotaOf(owner)
+	usage, err := s.usageOf(owner)
+	if err != nil {
+		return nil, err
+	}
+	limits := []struct {
+		resource string
+		max      int
+		used     int
+		add      int
+	}{
+		{"subsystems", quota.MaxSubsystems, usage.subsystems, add.subsystems},
+		{"namespaces", quota.MaxNamespaces, usage.namespaces, add.namespaces},
+		{"VF controllers", quota.MaxVfControllers, usage.vfControllers, add.vfControllers},
+		{"submission queues", quota.MaxSubmissionQueues, usage.submissionQueues, add.submissionQueues},
+		{"completion queues", quota.MaxCompletionQueues, usage.completionQueues, add.completionQueues},
+	}
+	for _, limit := range limits {
+		if limit.add > 0 && limit.max > 0 && limit.used+limit.add > limit.max {
+			return nil, status.Errorf(codes.ResourceExhausted, "tenant %s would exceed its quota of %d %s, %d are used",
+				owner, limit.max, limit.resource, limit.used)
+		}
+	}
+	s.reservations[name] = quotaReservation{owner: owner, usage: add}
+	return func() {
+		s.quotaMutex.Lock()
+		defer s.quotaMutex.Unlock()
+		delete(s.reservations, name)
+	}, nil
+}
+
+// ownedSubsystemNqns returns the NQNs of the subsystems owned by tenant
+func (s *Server) ownedSubsystemNqns(tenant string) (map[string]bool, error) {
+	return s.subsystemNqns(func(name string) (bool, error) {
+		owner, err := s.subsystemOwner(name)
+		return owner == tenant, err
+	})
+}
+
+// subsystemNqns returns the NQNs of the subsystems matching match
+func (s *Server) subsystemNqns(match func(name string) (bool, error)) (map[string]bool, error) {
+	nqns := make(map[string]bool)
+	for _, key := range s.listedKeys() {
+		if !strings.HasPrefix(key, "nvmeSubsystems/") || strings.Count(key, "/") != 1 {
+			continue
+		}
+		ok, err := match(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		subsys := new(pb.NvmeSubsystem)
+		found, err := s.store.Get(key, subsys)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			nqns[subsys.GetSpec().GetNqn()] = true
+		}
+	}
+	return nqns, nil
+}
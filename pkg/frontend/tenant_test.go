@@ -0,0 +1,429 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/auth"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_Tenants(t *testing.T) {
+	t.Cleanup(checkGlobalTestProtoObjectsNotChanged(t, t.Name()))
+	otherSubsystemName := utils.ResourceIDToSubsystemName("subsystem-other")
+	otherSubsystem := &pb.NvmeSubsystem{
+		Name: otherSubsystemName,
+		Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi4"},
+	}
+	vfController := &pb.NvmeController{
+		Name: testControllerName,
+		Spec: &pb.NvmeControllerSpec{
+			Endpoint: testController.Spec.Endpoint,
+			Trtype:   pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			MaxNsq:   4,
+			MaxNcq:   4,
+		},
+	}
+	pfController := &pb.NvmeController{
+		Spec: &pb.NvmeControllerSpec{
+			Endpoint: &pb.NvmeControllerSpec_PcieId{
+				PcieId: &pb.PciEndpoint{
+					PhysicalFunction: wrapperspb.Int32(0),
+					VirtualFunction:  wrapperspb.Int32(0),
+					PortId:           wrapperspb.Int32(0)},
+			},
+			Trtype:           pb.NvmeTransportType_NVME_TRANSPORT_TYPE_PCIE,
+			NvmeControllerId: proto.Int32(18),
+			MaxNsq:           8,
+			MaxNcq:           2,
+		},
+	}
+	newNamespace := &pb.NvmeNamespace{Spec: &pb.NvmeNamespaceSpec{HostNsid: 23, VolumeNameRef: "Malloc1"}}
+
+	tests := map[string]struct {
+		tenant   string
+		identity *auth.Identity
+		quota    Quota
+		call     func(ctx context.Context, c *frontendClient) (proto.Message, error)
+		out      proto.Message
+		spdk     []string
+		errCode  codes.Code
+		errMsg   string
+	}{
+		"subsystem within quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxSubsystems: 2},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{
+					NvmeSubsystem: &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5"}}, NvmeSubsystemId: "subsystem-new"})
+			},
+			out: &pb.NvmeSubsystem{
+				Name:   utils.ResourceIDToSubsystemName("subsystem-new"),
+				Spec:   &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5"},
+				Status: &pb.NvmeSubsystemStatus{FirmwareRevision: "SPDK v20.10"},
+			},
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+				`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v20.10","fields":{"major":20,"minor":10,"patch":0,"suffix":""}}}`,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"subsystem over quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxSubsystems: 1},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{
+					NvmeSubsystem: &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi5"}}, NvmeSubsystemId: "subsystem-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "tenant tenant-a would exceed its quota of 1 subsystems, 1 are used",
+		},
+		"existing subsystem over quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxSubsystems: 1},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{NvmeSubsystem: &testSubsystem, NvmeSubsystemId: testSubsystemID})
+			},
+			out:     &testSubsystemWithStatus,
+			spdk:    []string{},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"subsystem of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{NvmeSubsystem: &testSubsystem, NvmeSubsystemId: testSubsystemID})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.AlreadyExists,
+			errMsg:  fmt.Sprintf("%v already exists", testSubsystemName),
+		},
+		"namespace over quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxNamespaces: 1},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: newNamespace, NvmeNamespaceId: "namespace-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "tenant tenant-a would exceed its quota of 1 namespaces, 1 are used",
+		},
+		"operator namespace counted against the owner quota": {
+			tenant: "",
+			quota:  Quota{MaxNamespaces: 1},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: newNamespace, NvmeNamespaceId: "namespace-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "tenant tenant-a would exceed its quota of 1 namespaces, 1 are used",
+		},
+		"namespace in subsystem of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: newNamespace, NvmeNamespaceId: "namespace-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testSubsystemName),
+		},
+		"existing controller of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: &testController, NvmeControllerId: testControllerID})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testSubsystemName),
+		},
+		"VF controller over quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxVfControllers: 1},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: &testController, NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "tenant tenant-a would exceed its quota of 1 VF controllers, 1 are used",
+		},
+		"PF controller within VF quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxVfControllers: 1, MaxSubmissionQueues: 12},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: pfController, NvmeControllerId: "controller-new"})
+			},
+			out: &pb.NvmeController{
+				Name:   utils.ResourceIDToControllerName(testSubsystemID, "controller-new"),
+				Spec:   pfController.Spec,
+				Status: &pb.NvmeControllerStatus{Active: true},
+			},
+			spdk: []string{
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"ctrlr_id":18}}`,
+				`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0}}`,
+			},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"submission queues over quota": {
+			tenant: "tenant-a",
+			quota:  Quota{MaxSubmissionQueues: 10},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeController(ctx, &pb.CreateNvmeControllerRequest{Parent: testSubsystemName, NvmeController: pfController, NvmeControllerId: "controller-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "tenant tenant-a would exceed its quota of 10 submission queues, 4 are used",
+		},
+		"tenant of the caller identity": {
+			tenant:   "tenant-b",
+			identity: &auth.Identity{Method: "token", Subject: "alice", Tenant: "tenant-a"},
+			quota:    Quota{MaxNamespaces: 1},
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.CreateNvmeNamespace(ctx, &pb.CreateNvmeNamespaceRequest{Parent: testSubsystemName, NvmeNamespace: newNamespace, NvmeNamespaceId: "namespace-new"})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.ResourceExhausted,
+			errMsg:  "tenant tenant-a would exceed its quota of 1 namespaces, 1 are used",
+		},
+		"list subsystems of the tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeSubsystems(ctx, &pb.ListNvmeSubsystemsRequest{})
+			},
+			out: &pb.ListNvmeSubsystemsResponse{
+				NvmeSubsystems: []*pb.NvmeSubsystem{{Spec: &pb.NvmeSubsystemSpec{Nqn: otherSubsystem.Spec.Nqn}}},
+			},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[{"subnqn":"nqn.2022-09.io.spdk:opi3"},{"subnqn":"nqn.2022-09.io.spdk:opi4"}]}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list subsystems of the operator": {
+			tenant: "",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeSubsystems(ctx, &pb.ListNvmeSubsystemsRequest{})
+			},
+			out: &pb.ListNvmeSubsystemsResponse{
+				NvmeSubsystems: []*pb.NvmeSubsystem{
+					{Spec: &pb.NvmeSubsystemSpec{Nqn: testSubsystem.Spec.Nqn}},
+					{Spec: &pb.NvmeSubsystemSpec{Nqn: otherSubsystem.Spec.Nqn}},
+				},
+			},
+			spdk:    []string{`{"id":%d,"error":{"code":0,"message":""},"result":{"status":0,"subsys_list":[{"subnqn":"nqn.2022-09.io.spdk:opi3"},{"subnqn":"nqn.2022-09.io.spdk:opi4"}]}}`},
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"list controllers of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.ListNvmeControllers(ctx, &pb.ListNvmeControllersRequest{Parent: testSubsystemName})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testSubsystemName),
+		},
+		"get namespace of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.GetNvmeNamespace(ctx, &pb.GetNvmeNamespaceRequest{Name: testNamespaceName})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testNamespaceName),
+		},
+		"delete subsystem of another tenant": {
+			tenant: "tenant-b",
+			call: func(ctx context.Context, c *frontendClient) (proto.Message, error) {
+				return c.DeleteNvmeSubsystem(ctx, &pb.DeleteNvmeSubsystemRequest{Name: testSubsystemName})
+			},
+			out:     nil,
+			spdk:    []string{},
+			errCode: codes.NotFound,
+			errMsg:  fmt.Sprintf("unable to find key %v", testSubsystemName),
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+			s.opts.DefaultQuota = tt.quota
+
+			for _, r := range []struct {
+				name   string
+				owner  string
+				object proto.Message
+			}{
+				{testSubsystemName, "tenant-a", &testSubsystemWithStatus},
+				{otherSubsystemName, "tenant-b", otherSubsystem},
+				{testControllerName, "", vfController},
+				{testNamespaceName, "", &testNamespaceWithStatus},
+			} {
+				if err := s.store.Set(r.name, r.object); err != nil {
+					t.Fatal(err)
+				}
+				if err := s.setSubsystemOwner(r.name, r.owner); err != nil {
+					t.Fatal(err)
+				}
+				s.ListHelper[r.name] = false
+			}
+
+			ctx := testEnv.ctx
+			if tt.tenant != "" {
+				ctx = metadata.AppendToOutgoingContext(ctx, tenantKey, tt.tenant)
+			}
+			if tt.identity != nil {
+				// the identity is set by the auth interceptor of the server
+				ctx = auth.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(tenantKey, tt.tenant)), tt.identity)
+				if owned, err := s.ownedByCaller(ctx, testNamespaceName); err != nil || !owned {
+					t.Error("expected the namespace to be owned by the identity tenant", err)
+				}
+				owner, err := s.subsystemOwner(testSubsystemName)
+				if err != nil {
+					t.Fatal(err)
+				}
+				release, err := s.checkQuota("nvmeSubsystems/subsystem-test/nvmeNamespaces/namespace-new", owner, tenantUsage{namespaces: 1})
+				if err == nil {
+					release()
+				}
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+				return
+			}
+			response, err := tt.call(ctx, testEnv.client)
+
+			if tt.out == nil {
+				if err == nil {
+					t.Error("response: expected error, received", response)
+				}
+			} else if !proto.Equal(response, tt.out) {
+				t.Error("response: expected", tt.out, "received", response)
+			}
+			checkTenantError(t, err, tt.errCode, tt.errMsg)
+		})
+	}
+}
+
+func checkTenantError(t *testing.T, err error, code codes.Code, msg string) {
+	t.Helper()
+	if er, ok := status.FromError(err); ok {
+		if er.Code() != code {
+			t.Error("error code: expected", code, "received", er.Code())
+		}
+		if er.Message() != msg {
+			t.Error("error message: expected", msg, "received", er.Message())
+		}
+	} else {
+		t.Error("expected grpc error status")
+	}
+}
+
+func TestFrontEnd_QuotaConcurrentCreates(t *testing.T) {
+	testEnv := createTestEnvironment([]string{
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+		`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v20.10","fields":{"major":20,"minor":10,"patch":0,"suffix":""}}}`,
+	})
+	defer testEnv.Close()
+	testEnv.opiSpdkServer.opts.DefaultQuota = Quota{MaxSubsystems: 1}
+	ctx := metadata.AppendToOutgoingContext(testEnv.ctx, tenantKey, "tenant-a")
+
+	// only one of the subsystems fits in the quota, the other one is never
+	// passed to the SDK
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = testEnv.client.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{
+				NvmeSubsystem:   &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: fmt.Sprintf("nqn.2022-09.io.spdk:opi%d", 5+i)}},
+				NvmeSubsystemId: fmt.Sprintf("subsystem-new-%d", i),
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	exhausted := 0
+	for _, err := range errs {
+		if status.Code(err) == codes.ResourceExhausted {
+			exhausted++
+		} else if err != nil {
+			t.Error("expected no error, received", err)
+		}
+	}
+	if exhausted != 1 {
+		t.Error("subsystems over quota: expected 1, received", exhausted)
+	}
+}
+
+func TestFrontEnd_QuotaReleasedOnFailure(t *testing.T) {
+	testEnv := createTestEnvironment([]string{
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 1}}`,
+		`{"id":%d,"error":{"code":0,"message":""},"result":{"status": 0}}`,
+		`{"jsonrpc":"2.0","id":%d,"result":{"version":"SPDK v20.10","fields":{"major":20,"minor":10,"patch":0,"suffix":""}}}`,
+	})
+	defer testEnv.Close()
+	testEnv.opiSpdkServer.opts.DefaultQuota = Quota{MaxSubsystems: 1}
+	ctx := metadata.AppendToOutgoingContext(testEnv.ctx, tenantKey, "tenant-a")
+
+	// the subsystem the SDK failed to create does not use the quota
+	for i, code := range []codes.Code{codes.InvalidArgument, codes.OK} {
+		_, err := testEnv.client.CreateNvmeSubsystem(ctx, &pb.CreateNvmeSubsystemRequest{
+			NvmeSubsystem:   &pb.NvmeSubsystem{Spec: &pb.NvmeSubsystemSpec{Nqn: fmt.Sprintf("nqn.2022-09.io.spdk:opi%d", 5+i)}},
+			NvmeSubsystemId: fmt.Sprintf("subsystem-new-%d", i),
+		})
+		if status.Code(err) != code {
+			t.Error("expected", code, "received", err)
+		}
+	}
+	if len(testEnv.opiSpdkServer.reservations) != 0 {
+		t.Error("expected no quota reservation left, received", testEnv.opiSpdkServer.reservations)
+	}
+}
+
+func TestFrontEnd_QuotaAfterRestart(t *testing.T) {
+	testEnv := createTestEnvironment([]string{})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.setSubsystemOwner(testSubsystemName, "tenant-a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the next instance of the server shares the store
+	store := keyListingStore{Store: s.store.(*watchedStore).Store, keys: []string{testSubsystemName}}
+	restarted := NewServerWithOptions(testEnv.jsonRPC, store, Options{DefaultQuota: Quota{MaxSubsystems: 1}})
+
+	_, err := restarted.checkQuota(utils.ResourceIDToSubsystemName("subsystem-new"), "tenant-a", tenantUsage{subsystems: 1})
+	checkTenantError(t, err, codes.ResourceExhausted, "tenant tenant-a would exceed its quota of 1 subsystems, 1 are used")
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/philippgille/gokv"
+
+	"github.com/opiproject/gospdk/spdk"
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-marvell-bridge/pkg/models"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// VolumeResolver resolves the VolumeNameRef of the namespaces to the name of
+// the SDK bdev backing them, or returns NotFound if no such volume exists
+type VolumeResolver interface {
+	ResolveVolume(ctx context.Context, name string) (string, error)
+}
+
+// volumeBdevKey is the store key of the bdev of a volume created through
+// the backend or middleend servers
+func volumeBdevKey(name string) string {
+	return "volumeBdevs/" + name
+}
+
+// volumeName returns the name of the volume of a VolumeNameRef, the plain
+// resource IDs being the ones of volumes/
+func volumeName(ref string) string {
+	if !strings.Contains(ref, "/") {
+		return utils.ResourceIDToVolumeName(ref)
+	}
+	return ref
+}
+
+// StoreVolumes records the bdevs of the volumes created through the
+// opi-spdk-bridge backend and middleend servers in the store shared with
+// them, so that they are known after a restart, and resolves the volumes:
+// the Aio, Null, Malloc and encrypted volumes, and the bdev of the
+// namespace of the Nvme remote controllers. The volumes not recorded, e.g.
+// created before the bridge started or outside of it, are looked up in
+// the bdevs of the SDK.
+type StoreVolumes struct {
+	store gokv.Store
+	rpc   spdk.JSONRPC
+	mutex sync.RWMutex
+}
+
+// NewStoreVolumes creates a StoreVolumes keeping the bdevs in store, and
+// looking up the bdevs of the SDK with jsonRPC
+func NewStoreVolumes(jsonRPC spdk.JSONRPC, store gokv.Store) *StoreVolumes {
+	if jsonRPC == nil {
+		log.Panic("nil for JSONRPC is not allowed")
+	}
+	if store == nil {
+		log.Panic("nil for Store is not allowed")
+	}
+	return &StoreVolumes{store: store, rpc: jsonRPC}
+}
+
+// ResolveVolume resolves the volume name, the plain resource IDs being the
+// ones of volumes/
+func (v *StoreVolumes) ResolveVolume(ctx context.Context, name string) (string, error) {
+	name = volumeName(name)
+	bdev := new(wrapperspb.StringValue)
+	v.mutex.RLock()
+	found, err := v.store.Get(volumeBdevKey(name), bdev)
+	v.mutex.RUnlock()
+	if err != nil {
+		return "", err
+	}
+	if found {
+		return bdev.Value, nil
+	}
+	var bdevs []spdk.BdevGetBdevsResult
+	err = v.rpc.Call(ctx, "bdev_get_bdevs", &models.BdevGetBdevsParams{}, &bdevs)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(name, "nvmeRemoteControllers/") {
+		return controllerBdev(name, bdevs)
+	}
+	for _, b := range bdevs {
+		if b.Name == path.Base(name) {
+			return b.Name, nil
+		}
+	}
+	return "", status.Errorf(codes.NotFound, "unable to find volume %s", name)
+}
+
+// controllerBdev returns the bdev of the namespace of the Nvme remote
+// controller name, SPDK names them <controller>n<nsid>
+func controllerBdev(name string, bdevs []spdk.BdevGetBdevsResult) (string, error) {
+	namespaceRegexp := regexp.MustCompile("^" + regexp.QuoteMeta(path.Base(name)) + "n[0-9]+$")
+	namespaces := []string{}
+	for _, b := range bdevs {
+		if namespaceRegexp.MatchString(b.Name) {
+			namespaces = append(namespaces, b.Name)
+		}
+	}
+	switch len(namespaces) {
+	case 0:
+		return "", status.Errorf(codes.NotFound, "unable to find volume %s", name)
+	case 1:
+		return namespaces[0], nil
+	default:
+		sort.Strings(namespaces)
+		msg := fmt.Sprintf("%s has %d namespaces, use the bdev of one of them: %s", name, len(namespaces), strings.Join(namespaces, ", "))
+		return "", status.Errorf(codes.FailedPrecondition, msg)
+	}
+}
+
+// setVolumeBdev records or, if bdev is empty, forgets the bdev of a volume
+func (v *StoreVolumes) setVolumeBdev(name string, bdev string) error {
+	v.mutex.Lock()
+	defer v.mutex.Unlock()
+	if bdev == "" {
+		return v.store.Delete(volumeBdevKey(name))
+	}
+	return v.store.Set(volumeBdevKey(name), wrapperspb.String(bdev))
+}
+
+// UnaryServerInterceptor records the bdevs of the volumes successfully
+// created and deleted through the backend and middleend servers
+func (v *StoreVolumes) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		name, bdev := "", ""
+		switch req.(type) {
+		case *pb.CreateAioVolumeRequest, *pb.CreateNullVolumeRequest, *pb.CreateMallocVolumeRequest, *pb.CreateEncryptedVolumeRequest:
+			if volume, ok := resp.(interface{ GetName() string }); ok {
+				// the servers name the bdevs after the resource IDs
+				name, bdev = volume.GetName(), path.Base(volume.GetName())
+			}
+		case *pb.DeleteAioVolumeRequest, *pb.DeleteNullVolumeRequest, *pb.DeleteMallocVolumeRequest, *pb.DeleteEncryptedVolumeRequest:
+			name = volumeName(req.(interface{ GetName() string }).GetName())
+		}
+		if name != "" {
+			if err := v.setVolumeBdev(name, bdev); err != nil {
+				logger.ErrorContext(ctx, "Could not record the volume bdev", "name", name, "bdev", bdev, "error", err)
+			}
+		}
+		return resp, nil
+	}
+}
+
+// namespaceBdev returns the SDK bdev of the VolumeNameRef of a namespace,
+// the VolumeNameRef itself without VolumeResolver
+func (s *Server) namespaceBdev(ctx context.Context, spec *pb.NvmeNamespaceSpec) (string, error) {
+	if s.opts.Volumes == nil {
+		return spec.VolumeNameRef, nil
+	}
+	return s.opts.Volumes.ResolveVolume(ctx, spec.VolumeNameRef)
+}
+
+// volumeUsers returns the namespaces using the volume name
+func (s *Server) volumeUsers(name string) ([]string, error) {
+	users := []string{}
+	for _, key := range s.listedKeys() {
+		if !strings.Contains(key, "/nvmeNamespaces/") {
+			continue
+		}
+		namespace := new(pb.NvmeNamespace)
+		found, err := s.store.Get(key, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if found && volumeName(namespace.GetSpec().GetVolumeNameRef()) == volumeName(name) {
+			users = append(users, namespace.Name)
+		}
+	}
+	sort.Strings(users)
+	return users, nil
+}
+
+// VolumeInUseInterceptor returns FailedPrecondition instead of deleting the
+// backend and middleend volumes still used by Nvme namespaces
+func (s *Server) VolumeInUseInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		switch req.(type) {
+		case *pb.DeleteAioVolumeRequest, *pb.DeleteNullVolumeRequest, *pb.DeleteMallocVolumeRequest,
+			*pb.DeleteEncryptedVolumeRequest, *pb.DeleteNvmeRemoteControllerRequest:
+			name := req.(interface{ GetName() string }).GetName()
+			users, err := s.volumeUsers(name)
+			if err != nil {
+				return nil, err
+			}
+			if len(users) != 0 {
+				return nil, status.Errorf(codes.FailedPrecondition, "volume %s is still used by %s", name, strings.Join(users, ", "))
+			}
+		}
+		return handler(ctx, req)
+	}
+}
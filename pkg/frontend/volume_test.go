@@ -0,0 +1,278 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+// Copyright (C) 2022 Marvell International Ltd.
+// Copyright (C) 2023 Intel Corporation
+
+// Package frontend implememnts the FrontEnd APIs (host facing) of the storage Server
+package frontend
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/philippgille/gokv/gomap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+)
+
+func TestFrontEnd_StoreVolumes(t *testing.T) {
+	bdevs := func(names ...string) string {
+		list := []string{}
+		for _, name := range names {
+			list = append(list, fmt.Sprintf(`{"name":"%s"}`, name))
+		}
+		return `{"id":%d,"error":{"code":0,"message":""},"result":[` + strings.Join(list, ",") + `]}`
+	}
+	tests := map[string]struct {
+		in      string
+		spdk    []string
+		bdev    string
+		errCode codes.Code
+		errMsg  string
+	}{
+		"recorded volume": {
+			in:      "volumes/malloc0",
+			spdk:    []string{},
+			bdev:    "malloc0",
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"resource ID of a recorded volume": {
+			in:      "aio0",
+			spdk:    []string{},
+			bdev:    "aio0",
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"volume not recorded": {
+			in:      "Malloc1",
+			spdk:    []string{bdevs("Malloc0", "Malloc1")},
+			bdev:    "Malloc1",
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"nvme remote controller": {
+			in:      "nvmeRemoteControllers/nvmetcp12",
+			spdk:    []string{bdevs("Malloc1", "nvmetcp12n2", "nvmetcp123n1")},
+			bdev:    "nvmetcp12n2",
+			errCode: codes.OK,
+			errMsg:  "",
+		},
+		"nvme remote controller with several namespaces": {
+			in:      "nvmeRemoteControllers/nvmetcp12",
+			spdk:    []string{bdevs("nvmetcp12n2", "nvmetcp12n1")},
+			bdev:    "",
+			errCode: codes.FailedPrecondition,
+			errMsg:  "nvmeRemoteControllers/nvmetcp12 has 2 namespaces, use the bdev of one of them: nvmetcp12n1, nvmetcp12n2",
+		},
+		"nvme remote controller without namespace": {
+			in:      "nvmeRemoteControllers/nvmetcp12",
+			spdk:    []string{bdevs("Malloc1")},
+			bdev:    "",
+			errCode: codes.NotFound,
+			errMsg:  "unable to find volume nvmeRemoteControllers/nvmetcp12",
+		},
+		"unknown volume": {
+			in:      "Malloc0",
+			spdk:    []string{bdevs("Malloc1")},
+			bdev:    "",
+			errCode: codes.NotFound,
+			errMsg:  "unable to find volume volumes/Malloc0",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment(tt.spdk)
+			defer testEnv.Close()
+			options := gomap.DefaultOptions
+			options.Codec = utils.ProtoCodec{}
+			volumes := NewStoreVolumes(testEnv.jsonRPC, gomap.NewStore(options))
+			if err := volumes.setVolumeBdev("volumes/malloc0", "malloc0"); err != nil {
+				t.Fatal(err)
+			}
+			if err := volumes.setVolumeBdev("volumes/aio0", "aio0"); err != nil {
+				t.Fatal(err)
+			}
+
+			bdev, err := volumes.ResolveVolume(testEnv.ctx, tt.in)
+
+			if bdev != tt.bdev {
+				t.Error("bdev: expected", tt.bdev, "received", bdev)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Error("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_StoreVolumesInterceptor(t *testing.T) {
+	tests := map[string]struct {
+		in     interface{}
+		out    interface{}
+		err    error
+		bdev   string
+		exists bool
+	}{
+		"created volume recorded": {
+			in:     &pb.CreateMallocVolumeRequest{MallocVolumeId: "malloc1"},
+			out:    &pb.MallocVolume{Name: "volumes/malloc1"},
+			err:    nil,
+			bdev:   "malloc1",
+			exists: true,
+		},
+		"failed creation not recorded": {
+			in:     &pb.CreateMallocVolumeRequest{MallocVolumeId: "malloc1"},
+			out:    nil,
+			err:    status.Error(codes.InvalidArgument, "failed"),
+			bdev:   "",
+			exists: false,
+		},
+		"deleted volume forgotten": {
+			in:     &pb.DeleteMallocVolumeRequest{Name: "volumes/malloc0"},
+			out:    &emptypb.Empty{},
+			err:    nil,
+			bdev:   "",
+			exists: false,
+		},
+		"failed deletion kept": {
+			in:     &pb.DeleteMallocVolumeRequest{Name: "volumes/malloc0"},
+			out:    nil,
+			err:    status.Error(codes.InvalidArgument, "failed"),
+			bdev:   "malloc0",
+			exists: true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+			options := gomap.DefaultOptions
+			options.Codec = utils.ProtoCodec{}
+			volumes := NewStoreVolumes(testEnv.jsonRPC, gomap.NewStore(options))
+			if err := volumes.setVolumeBdev("volumes/malloc0", "malloc0"); err != nil {
+				t.Fatal(err)
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return tt.out, tt.err
+			}
+			_, err := volumes.UnaryServerInterceptor()(testEnv.ctx, tt.in, &grpc.UnaryServerInfo{}, handler)
+			if err != tt.err {
+				t.Error("error: expected", tt.err, "received", err)
+			}
+
+			name := "volumes/malloc0"
+			if _, ok := tt.in.(*pb.CreateMallocVolumeRequest); ok {
+				name = "volumes/malloc1"
+			}
+			bdev := new(wrapperspb.StringValue)
+			found, err := volumes.store.Get(volumeBdevKey(name), bdev)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if found != tt.exists || bdev.Value != tt.bdev {
+				t.Error("bdev: expected", tt.exists, tt.bdev, "received", found, bdev.Value)
+			}
+		})
+	}
+}
+
+func TestFrontEnd_CreateNvmeNamespaceUnknownVolume(t *testing.T) {
+	testEnv := createTestEnvironment([]string{`{"id":%d,"error":{"code":0,"message":""},"result":[]}`})
+	defer testEnv.Close()
+	s := testEnv.opiSpdkServer
+	s.opts.Volumes = NewStoreVolumes(testEnv.jsonRPC, s.store)
+	if err := s.store.Set(testSubsystemName, &testSubsystemWithStatus); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := testEnv.client.CreateNvmeNamespace(testEnv.ctx, &pb.CreateNvmeNamespaceRequest{
+		Parent:          testSubsystemName,
+		NvmeNamespace:   &pb.NvmeNamespace{Spec: &pb.NvmeNamespaceSpec{HostNsid: 1, VolumeNameRef: "volumes/missing"}},
+		NvmeNamespaceId: "namespace-new",
+	})
+
+	checkTenantError(t, err, codes.NotFound, "unable to find volume volumes/missing")
+}
+
+func TestFrontEnd_VolumeInUseInterceptor(t *testing.T) {
+	tests := map[string]struct {
+		in      interface{}
+		errCode codes.Code
+		errMsg  string
+		called  bool
+	}{
+		"volume used by a namespace": {
+			in:      &pb.DeleteMallocVolumeRequest{Name: "volumes/Malloc0"},
+			errCode: codes.FailedPrecondition,
+			errMsg:  fmt.Sprintf("volume volumes/Malloc0 is still used by %v", testNamespaceName),
+			called:  false,
+		},
+		"unused volume": {
+			in:      &pb.DeleteAioVolumeRequest{Name: "volumes/aio0"},
+			errCode: codes.OK,
+			errMsg:  "",
+			called:  true,
+		},
+		"unused remote controller": {
+			in:      &pb.DeleteNvmeRemoteControllerRequest{Name: "nvmeRemoteControllers/nvmetcp12"},
+			errCode: codes.OK,
+			errMsg:  "",
+			called:  true,
+		},
+		"not a volume deletion": {
+			in:      &pb.GetMallocVolumeRequest{Name: "volumes/Malloc0"},
+			errCode: codes.OK,
+			errMsg:  "",
+			called:  true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testEnv := createTestEnvironment([]string{})
+			defer testEnv.Close()
+			s := testEnv.opiSpdkServer
+			if err := s.store.Set(testNamespaceName, &testNamespaceWithStatus); err != nil {
+				t.Fatal(err)
+			}
+			s.ListHelper[testNamespaceName] = false
+
+			called := false
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				return &emptypb.Empty{}, nil
+			}
+			_, err := s.VolumeInUseInterceptor()(testEnv.ctx, tt.in, &grpc.UnaryServerInfo{}, handler)
+
+			if called != tt.called {
+				t.Error("handler called: expected", tt.called, "received", called)
+			}
+			if tt.errCode == codes.OK {
+				if err != nil {
+					t.Error("expected no error, received", err)
+				}
+			} else {
+				checkTenantError(t, err, tt.errCode, tt.errMsg)
+			}
+		})
+	}
+}
@@ -0,0 +1,261 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package logging provides the structured, leveled loggers of the bridge
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const redacted = "[REDACTED]"
+
+// sensitiveNames are parts of attribute keys and proto field names whose
+// values are never logged
+var sensitiveNames = []string{"password", "secret", "token", "key", "psk", "authorization", "credential", "dhchap"}
+
+// reservedKeys are never redacted nor truncated
+var reservedKeys = map[string]bool{
+	slog.TimeKey:    true,
+	slog.LevelKey:   true,
+	slog.MessageKey: true,
+	slog.SourceKey:  true,
+	"component":     true,
+	"trace_id":      true,
+	"span_id":       true,
+}
+
+// Options configures the loggers
+type Options struct {
+	// Level is the level of components without an override in Levels
+	Level slog.Level
+	// Levels overrides the level per component
+	Levels map[string]slog.Level
+	// JSON selects JSON instead of text output
+	JSON bool
+	// MaxValueLength truncates longer attribute values, 0 disables truncation
+	MaxValueLength int
+}
+
+type config struct {
+	handler slog.Handler
+	level   slog.Level
+	levels  map[string]slog.Level
+}
+
+func (c *config) levelOf(component string) slog.Level {
+	if level, ok := c.levels[component]; ok {
+		return level
+	}
+	return c.level
+}
+
+var current atomic.Pointer[config]
+
+func init() {
+	Configure(os.Stderr, Options{Level: slog.LevelInfo, MaxValueLength: 1024})
+}
+
+// Configure applies opts to all loggers, including the ones created before,
+// and routes the standard log package through them
+func Configure(w io.Writer, opts Options) {
+	handlerOptions := &slog.HandlerOptions{
+		// levels are checked per component before reaching the handler
+		Level:       slog.LevelDebug,
+		ReplaceAttr: opts.replaceAttr,
+	}
+	var handler slog.Handler
+	if opts.JSON {
+		handler = slog.NewJSONHandler(w, handlerOptions)
+	} else {
+		handler = slog.NewTextHandler(w, handlerOptions)
+	}
+	levels := make(map[string]slog.Level, len(opts.Levels))
+	for component, level := range opts.Levels {
+		levels[component] = level
+	}
+	current.Store(&config{handler: &traceHandler{handler}, level: opts.Level, levels: levels})
+	slog.SetDefault(Logger("main"))
+}
+
+// ParseLevels parses per component levels in component=level,... format
+func ParseLevels(s string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	if s == "" {
+		return levels, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		component, value, found := strings.Cut(strings.TrimSpace(item), "=")
+		if !found || component == "" {
+			return nil, fmt.Errorf("invalid component level %q, expected component=level", item)
+		}
+		var level slog.Level
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			return nil, fmt.Errorf("invalid level of component %s: %w", component, err)
+		}
+		levels[component] = level
+	}
+	return levels, nil
+}
+
+// Logger returns the logger of a bridge component, e.g. frontend or spdk
+func Logger(component string) *slog.Logger {
+	return slog.New(&componentHandler{component: component})
+}
+
+// InterceptorLogger adapts a logger to the gRPC logging interceptor
+func InterceptorLogger(l *slog.Logger) logging.Logger {
+	return logging.LoggerFunc(func(ctx context.Context, lvl logging.Level, msg string, fields ...any) {
+		l.Log(ctx, slog.Level(lvl), msg, fields...)
+	})
+}
+
+// componentHandler resolves the configured handler on every record, so
+// that loggers created at package initialization follow Configure
+type componentHandler struct {
+	component string
+	ops       []func(slog.Handler) slog.Handler
+}
+
+func (h *componentHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= current.Load().levelOf(h.component)
+}
+
+func (h *componentHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler := current.Load().handler.WithAttrs([]slog.Attr{slog.String("component", h.component)})
+	for _, op := range h.ops {
+		handler = op(handler)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *componentHandler) with(op func(slog.Handler) slog.Handler) *componentHandler {
+	ops := make([]func(slog.Handler) slog.Handler, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &componentHandler{component: h.component, ops: append(ops, op)}
+}
+
+func (h *componentHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *componentHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+// traceHandler adds the IDs of the span in the record context
+type traceHandler struct {
+	slog.Handler
+}
+
+func (h *traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if span := trace.SpanContextFromContext(ctx); span.IsValid() {
+		r.AddAttrs(
+			slog.String("trace_id", span.TraceID().String()),
+			slog.String("span_id", span.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &traceHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	return &traceHandler{h.Handler.WithGroup(name)}
+}
+
+// replaceAttr redacts sensitive and truncates large attribute values
+func (opts Options) replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && reservedKeys[a.Key] {
+		return a
+	}
+	if isSensitive(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		a.Value = slog.StringValue(opts.truncate(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			a.Value = slog.StringValue(opts.truncate(v.Error()))
+		case proto.Message:
+			payload, err := protojson.Marshal(Redact(v))
+			if err != nil {
+				payload = []byte(err.Error())
+			}
+			a.Value = slog.StringValue(opts.truncate(string(payload)))
+		default:
+			if s := fmt.Sprintf("%+v", v); opts.MaxValueLength > 0 && len(s) > opts.MaxValueLength {
+				a.Value = slog.StringValue(opts.truncate(s))
+			}
+		}
+	}
+	return a
+}
+
+func (opts Options) truncate(s string) string {
+	if opts.MaxValueLength <= 0 || len(s) <= opts.MaxValueLength {
+		return s
+	}
+	return fmt.Sprintf("%s...(%d bytes truncated)", s[:opts.MaxValueLength], len(s)-opts.MaxValueLength)
+}
+
+func isSensitive(name string) bool {
+	name = strings.ToLower(name)
+	for _, sensitive := range sensitiveNames {
+		if strings.Contains(name, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a copy of msg with sensitive string and bytes fields replaced
+func Redact(msg proto.Message) proto.Message {
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return msg
+	}
+	clone := proto.Clone(msg)
+	redactMessage(clone.ProtoReflect())
+	return clone
+}
+
+func redactMessage(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case isSensitive(string(fd.Name())) && !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.StringKind:
+			m.Set(fd, protoreflect.ValueOfString(redacted))
+		case isSensitive(string(fd.Name())) && !fd.IsList() && !fd.IsMap() && fd.Kind() == protoreflect.BytesKind:
+			m.Set(fd, protoreflect.ValueOfBytes([]byte(redacted)))
+		case fd.IsList() && fd.Message() != nil:
+			for i := 0; i < v.List().Len(); i++ {
+				redactMessage(v.List().Get(i).Message())
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				redactMessage(mv.Message())
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			redactMessage(v.Message())
+		}
+		return true
+	})
+}
@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package logging provides the structured, leveled loggers of the bridge
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+)
+
+func TestLogging_Logger(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	spanCtx := trace.ContextWithSpanContext(context.Background(),
+		trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+
+	tests := map[string]struct {
+		opts      Options
+		component string
+		ctx       context.Context
+		level     slog.Level
+		args      []any
+		out       map[string]any
+	}{
+		"component and trace ids": {
+			opts:      Options{Level: slog.LevelInfo},
+			component: "frontend",
+			ctx:       spanCtx,
+			level:     slog.LevelInfo,
+			args:      []any{"name", "nvmeSubsystems/subsys0"},
+			out: map[string]any{
+				"component": "frontend",
+				"name":      "nvmeSubsystems/subsys0",
+				"trace_id":  "0102030405060708090a0b0c0d0e0f10",
+				"span_id":   "0102030405060708",
+			},
+		},
+		"level below component level": {
+			opts:      Options{Level: slog.LevelDebug, Levels: map[string]slog.Level{"spdk": slog.LevelWarn}},
+			component: "spdk",
+			ctx:       context.Background(),
+			level:     slog.LevelInfo,
+			args:      []any{},
+			out:       nil,
+		},
+		"level above default level with component override": {
+			opts:      Options{Level: slog.LevelWarn, Levels: map[string]slog.Level{"spdk": slog.LevelDebug}},
+			component: "spdk",
+			ctx:       context.Background(),
+			level:     slog.LevelDebug,
+			args:      []any{},
+			out:       map[string]any{"component": "spdk"},
+		},
+		"sensitive attribute": {
+			opts:      Options{Level: slog.LevelInfo},
+			component: "frontend",
+			ctx:       context.Background(),
+			level:     slog.LevelInfo,
+			args:      []any{"dhchap_key", "secret-value"},
+			out:       map[string]any{"component": "frontend", "dhchap_key": redacted},
+		},
+		"truncated attribute": {
+			opts:      Options{Level: slog.LevelInfo, MaxValueLength: 4},
+			component: "frontend",
+			ctx:       context.Background(),
+			level:     slog.LevelInfo,
+			args:      []any{"name", "nvmeSubsystems"},
+			out:       map[string]any{"component": "frontend", "name": "nvme...(10 bytes truncated)"},
+		},
+		"redacted payload": {
+			opts:      Options{Level: slog.LevelInfo},
+			component: "grpc",
+			ctx:       context.Background(),
+			level:     slog.LevelInfo,
+			args: []any{"grpc.request.content", &pb.CreateEncryptedVolumeRequest{
+				EncryptedVolume: &pb.EncryptedVolume{Key: []byte("0123456789abcdef"), VolumeNameRef: "Malloc0"},
+			}},
+			out: map[string]any{
+				"component":            "grpc",
+				"grpc.request.content": `{"encryptedVolume":{"volumeNameRef":"Malloc0","key":"W1JFREFDVEVEXQ=="}}`,
+			},
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tt.opts.JSON = true
+			Configure(&buf, tt.opts)
+
+			Logger(tt.component).Log(tt.ctx, tt.level, "test", tt.args...)
+
+			if tt.out == nil {
+				if buf.Len() != 0 {
+					t.Error("expected no output, received", buf.String())
+				}
+				return
+			}
+			record := map[string]any{}
+			if err := json.Unmarshal(buf.Bytes(), &record); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range []string{"time", "level", "msg"} {
+				delete(record, key)
+			}
+			if content, ok := record["grpc.request.content"].(string); ok {
+				record["grpc.request.content"] = strings.ReplaceAll(content, " ", "")
+			}
+			if !reflect.DeepEqual(record, tt.out) {
+				t.Error("record: expected", tt.out, "received", record)
+			}
+		})
+	}
+}
+
+func TestLogging_ParseLevels(t *testing.T) {
+	tests := map[string]struct {
+		in      string
+		out     map[string]slog.Level
+		wantErr bool
+	}{
+		"empty": {
+			in:      "",
+			out:     map[string]slog.Level{},
+			wantErr: false,
+		},
+		"valid levels": {
+			in:      "spdk=debug, grpc=warn",
+			out:     map[string]slog.Level{"spdk": slog.LevelDebug, "grpc": slog.LevelWarn},
+			wantErr: false,
+		},
+		"missing level": {
+			in:      "spdk",
+			out:     nil,
+			wantErr: true,
+		},
+		"invalid level": {
+			in:      "spdk=loud",
+			out:     nil,
+			wantErr: true,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			levels, err := ParseLevels(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Error("error: expected", tt.wantErr, "received", err)
+			}
+			if !reflect.DeepEqual(levels, tt.out) {
+				t.Error("levels: expected", tt.out, "received", levels)
+			}
+		})
+	}
+}
@@ -322,3 +322,9 @@ type MrvlNvmCtrlrGetNsStatsResult struct {
 	TotalWriteLatencyInUs int `json:"total_write_latency_in_us"`
 	StatsTimeWindowInUs   int `json:"stats_time_window_in_us"`
 }
+
+// BdevGetBdevsParams represents the parameters to a get bdevs request,
+// all bdevs are returned without name
+type BdevGetBdevsParams struct {
+	Name string `json:"name,omitempty"`
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/philippgille/gokv/encoding"
+	"github.com/philippgille/gokv/util"
+	bolt "go.etcd.io/bbolt"
+)
+
+// BboltStore is a gokv.Store in an embedded bbolt file, for single-node
+// DPUs without Redis
+type BboltStore struct {
+	db     *bolt.DB
+	bucket []byte
+	codec  encoding.Codec
+}
+
+// NewBboltStore opens, or creates, the bbolt file at path. bbolt locks the
+// file, so opening fails after timeout if another process holds it.
+func NewBboltStore(path string, bucket string, timeout time.Duration, codec encoding.Codec) (*BboltStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: timeout})
+	if err != nil {
+		return nil, fmt.Errorf("could not open bbolt store %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &BboltStore{db: db, bucket: []byte(bucket), codec: codec}, nil
+}
+
+// Set stores the value v for the key k
+func (s *BboltStore) Set(k string, v interface{}) error {
+	if err := util.CheckKeyAndValue(k, v); err != nil {
+		return err
+	}
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Put([]byte(k), data)
+	})
+}
+
+// Get retrieves the value of the key k into v
+func (s *BboltStore) Get(k string, v interface{}) (bool, error) {
+	if err := util.CheckKeyAndValue(k, v); err != nil {
+		return false, err
+	}
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		// the value is only valid during the transaction
+		if value := tx.Bucket(s.bucket).Get([]byte(k)); value != nil {
+			data = append([]byte{}, value...)
+		}
+		return nil
+	})
+	if err != nil || data == nil {
+		return false, err
+	}
+	return true, s.codec.Unmarshal(data, v)
+}
+
+// Delete deletes the key k, deleting a missing key is not an error
+func (s *BboltStore) Delete(k string) error {
+	if err := util.CheckKey(k); err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(s.bucket).Delete([]byte(k))
+	})
+}
+
+// Keys returns the keys starting with prefix
+func (s *BboltStore) Keys(prefix string) ([]string, error) {
+	var keys []string
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(s.bucket).Cursor()
+		for k, _ := c.Seek([]byte(prefix)); k != nil && bytes.HasPrefix(k, []byte(prefix)); k, _ = c.Next() {
+			keys = append(keys, string(k))
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// Close closes the file, releasing its lock
+func (s *BboltStore) Close() error {
+	return s.db.Close()
+}
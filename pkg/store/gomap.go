@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"sync"
+
+	"github.com/philippgille/gokv"
+	"github.com/philippgille/gokv/encoding"
+	"github.com/philippgille/gokv/gomap"
+)
+
+// gomapStore serializes the writes of a gomap.Store, whose Delete does not
+// take its lock
+type gomapStore struct {
+	gokv.Store
+	mutex sync.RWMutex
+}
+
+// NewGomapStore creates an in-memory store safe for concurrent use
+func NewGomapStore(codec encoding.Codec) gokv.Store {
+	options := gomap.DefaultOptions
+	options.Codec = codec
+	return &gomapStore{Store: gomap.NewStore(options)}
+}
+
+func (s *gomapStore) Set(k string, v interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.Store.Set(k, v)
+}
+
+func (s *gomapStore) Get(k string, v interface{}) (bool, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.Store.Get(k, v)
+}
+
+func (s *gomapStore) Delete(k string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.Store.Delete(k)
+}
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/philippgille/gokv"
+)
+
+// prefixedStore prefixes all keys, so that several bridges share a store
+// without colliding on the resource names
+type prefixedStore struct {
+	gokv.Store
+	prefix string
+}
+
+// WithKeyPrefix prefixes the keys of s, an empty prefix returns s
+func WithKeyPrefix(s gokv.Store, prefix string) gokv.Store {
+	if prefix == "" {
+		return s
+	}
+	return &prefixedStore{Store: s, prefix: prefix}
+}
+
+func (s *prefixedStore) Set(k string, v interface{}) error {
+	return s.Store.Set(s.prefix+k, v)
+}
+
+func (s *prefixedStore) Get(k string, v interface{}) (bool, error) {
+	return s.Store.Get(s.prefix+k, v)
+}
+
+func (s *prefixedStore) Delete(k string) error {
+	return s.Store.Delete(s.prefix + k)
+}
+
+func (s *prefixedStore) Keys(prefix string) ([]string, error) {
+	lister, ok := s.Store.(KeyLister)
+	if !ok {
+		return nil, fmt.Errorf("store %T does not list its keys", s.Store)
+	}
+	keys, err := lister.Keys(s.prefix + prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, k := range keys {
+		keys[i] = strings.TrimPrefix(k, s.prefix)
+	}
+	return keys, nil
+}
@@ -0,0 +1,236 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/go-redis/redis"
+	"github.com/philippgille/gokv/encoding"
+	"github.com/philippgille/gokv/util"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+)
+
+// redisScanCount is the number of keys scanned by each SCAN call
+const redisScanCount = 100
+
+// redisGlobEscaper escapes the glob patterns of the SCAN MATCH option
+var redisGlobEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+// RedisStore is a gokv.Store on a standalone, sentinel-monitored or
+// clustered Redis
+type RedisStore struct {
+	client redis.UniversalClient
+	codec  encoding.Codec
+}
+
+// redisAuth holds the credentials and database sent on every new connection
+type redisAuth struct {
+	username string
+	password string
+	db       int
+}
+
+// newRedisAuth reads the password of c, from its file if set
+func newRedisAuth(c *config.RedisConfig) (*redisAuth, error) {
+	auth := &redisAuth{username: c.Username, password: string(c.Password), db: c.DB}
+	if c.PasswordFile != "" {
+		data, err := os.ReadFile(filepath.Clean(c.PasswordFile))
+		if err != nil {
+			return nil, fmt.Errorf("could not read Redis password: %w", err)
+		}
+		auth.password = strings.TrimRight(string(data), "\r\n")
+	}
+	return auth, nil
+}
+
+// options returns the password, database and connection hook of the
+// client options. go-redis only sends AUTH <password>, so an ACL user
+// authenticates and then selects the database in the hook.
+func (a *redisAuth) options() (string, int, func(*redis.Conn) error) {
+	if a.username == "" {
+		return a.password, a.db, nil
+	}
+	return "", 0, func(cn *redis.Conn) error {
+		if err := cn.Do("auth", a.username, a.password).Err(); err != nil {
+			return err
+		}
+		if a.db != 0 {
+			return cn.Select(a.db).Err()
+		}
+		return nil
+	}
+}
+
+// newRedisTLSConfig builds the TLS configuration of c, nil if disabled
+func newRedisTLSConfig(c *config.RedisTLSConfig) (*tls.Config, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify, //nolint:gosec // opt-in, for testing only
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(filepath.Clean(c.CAFile))
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CAFile)
+		}
+	}
+	if c.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// NewRedisClient connects to Redis in the mode of c, it fails if Redis
+// does not answer
+func NewRedisClient(c *config.RedisConfig) (redis.UniversalClient, error) {
+	auth, err := newRedisAuth(c)
+	if err != nil {
+		return nil, err
+	}
+	password, db, onConnect := auth.options()
+	tlsConfig, err := newRedisTLSConfig(&c.TLS)
+	if err != nil {
+		return nil, fmt.Errorf("could not configure Redis TLS: %w", err)
+	}
+
+	var client redis.UniversalClient
+	switch c.Mode {
+	case config.RedisStandalone:
+		client = redis.NewClient(&redis.Options{
+			Addr:         c.Address,
+			OnConnect:    onConnect,
+			Password:     password,
+			DB:           db,
+			DialTimeout:  c.DialTimeout,
+			ReadTimeout:  c.ReadTimeout,
+			WriteTimeout: c.WriteTimeout,
+			PoolSize:     c.PoolSize,
+			MaxRetries:   c.MaxRetries,
+			TLSConfig:    tlsConfig,
+		})
+	case config.RedisSentinel:
+		client = redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:    c.MasterName,
+			SentinelAddrs: c.Addresses,
+			OnConnect:     onConnect,
+			Password:      password,
+			DB:            db,
+			DialTimeout:   c.DialTimeout,
+			ReadTimeout:   c.ReadTimeout,
+			WriteTimeout:  c.WriteTimeout,
+			PoolSize:      c.PoolSize,
+			MaxRetries:    c.MaxRetries,
+			TLSConfig:     tlsConfig,
+		})
+	case config.RedisCluster:
+		client = redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        c.Addresses,
+			OnConnect:    onConnect,
+			Password:     password,
+			DialTimeout:  c.DialTimeout,
+			ReadTimeout:  c.ReadTimeout,
+			WriteTimeout: c.WriteTimeout,
+			PoolSize:     c.PoolSize,
+			MaxRetries:   c.MaxRetries,
+			TLSConfig:    tlsConfig,
+		})
+	default:
+		return nil, fmt.Errorf("unknown Redis mode %q", c.Mode)
+	}
+	if err := client.Ping().Err(); err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("could not connect to Redis in %s mode: %w", c.Mode, err)
+	}
+	return client, nil
+}
+
+// NewRedisStore creates a store on client, closing the store closes client
+func NewRedisStore(client redis.UniversalClient, codec encoding.Codec) *RedisStore {
+	return &RedisStore{client: client, codec: codec}
+}
+
+// Set stores the value v for the key k
+func (s *RedisStore) Set(k string, v interface{}) error {
+	if err := util.CheckKeyAndValue(k, v); err != nil {
+		return err
+	}
+	data, err := s.codec.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(k, string(data), 0).Err()
+}
+
+// Get retrieves the value of the key k into v
+func (s *RedisStore) Get(k string, v interface{}) (bool, error) {
+	if err := util.CheckKeyAndValue(k, v); err != nil {
+		return false, err
+	}
+	data, err := s.client.Get(k).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, s.codec.Unmarshal(data, v)
+}
+
+// Delete deletes the key k, deleting a missing key is not an error
+func (s *RedisStore) Delete(k string) error {
+	if err := util.CheckKey(k); err != nil {
+		return err
+	}
+	return s.client.Del(k).Err()
+}
+
+// Keys returns the keys starting with prefix, of all masters in cluster mode
+func (s *RedisStore) Keys(prefix string) ([]string, error) {
+	match := redisGlobEscaper.Replace(prefix) + "*"
+	var mutex sync.Mutex
+	var keys []string
+	scan := func(c redis.Cmdable) error {
+		iter := c.Scan(0, match, redisScanCount).Iterator()
+		for iter.Next() {
+			mutex.Lock()
+			keys = append(keys, iter.Val())
+			mutex.Unlock()
+		}
+		return iter.Err()
+	}
+	if cluster, ok := s.client.(*redis.ClusterClient); ok {
+		err := cluster.ForEachMaster(func(c *redis.Client) error {
+			return scan(c)
+		})
+		return keys, err
+	}
+	return keys, scan(s.client)
+}
+
+// Close closes the Redis client
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,288 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+)
+
+// fakeRedis answers the few commands used by the store, and records them
+type fakeRedis struct {
+	lis      net.Listener
+	password string
+	mutex    sync.Mutex
+	commands []string
+	values   map[string]string
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &fakeRedis{lis: lis, password: password, values: map[string]string{}}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go r.serve(conn)
+		}
+	}()
+	t.Cleanup(func() { _ = lis.Close() })
+	return r
+}
+
+func (r *fakeRedis) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	reader := bufio.NewReader(conn)
+	authenticated := r.password == ""
+	for {
+		args, err := readCommand(reader)
+		if err != nil {
+			return
+		}
+		r.mutex.Lock()
+		r.commands = append(r.commands, strings.Join(args, " "))
+		var reply string
+		switch name := strings.ToLower(args[0]); {
+		case name == "auth":
+			authenticated = args[len(args)-1] == r.password
+			reply = "+OK\r\n"
+			if !authenticated {
+				reply = "-WRONGPASS invalid username-password pair\r\n"
+			}
+		case !authenticated:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case name == "select" || name == "ping" && len(args) == 1:
+			reply = "+OK\r\n"
+		case name == "set":
+			r.values[args[1]] = args[2]
+			reply = "+OK\r\n"
+		case name == "get":
+			value, ok := r.values[args[1]]
+			reply = "$-1\r\n"
+			if ok {
+				reply = fmt.Sprintf("$%d\r\n%s\r\n", len(value), value)
+			}
+		case name == "del":
+			delete(r.values, args[1])
+			reply = ":1\r\n"
+		case name == "scan":
+			// a single page of the keys starting with the escaped prefix
+			prefix := strings.ReplaceAll(strings.TrimSuffix(args[3], "*"), `\`, "")
+			var keys []string
+			for k := range r.values {
+				if strings.HasPrefix(k, prefix) {
+					keys = append(keys, fmt.Sprintf("$%d\r\n%s\r\n", len(k), k))
+				}
+			}
+			reply = fmt.Sprintf("*2\r\n$1\r\n0\r\n*%d\r\n%s", len(keys), strings.Join(keys, ""))
+		default:
+			reply = "-ERR unknown command\r\n"
+		}
+		r.mutex.Unlock()
+		if _, err := io.WriteString(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func (r *fakeRedis) received() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string{}, r.commands...)
+}
+
+// readCommand reads a RESP array of bulk strings
+func readCommand(reader *bufio.Reader) ([]string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "*")))
+	if err != nil {
+		return nil, err
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(line, "$")))
+		if err != nil {
+			return nil, err
+		}
+		arg := make([]byte, size+2)
+		if _, err := io.ReadFull(reader, arg); err != nil {
+			return nil, err
+		}
+		args[i] = string(arg[:size])
+	}
+	return args, nil
+}
+
+func TestStore_Redis(t *testing.T) {
+	tests := map[string]struct {
+		config   func(c *config.RedisConfig, dir string)
+		password string
+		commands []string
+		errMsg   string
+	}{
+		"no authentication": {
+			config: func(c *config.RedisConfig, _ string) {},
+			commands: []string{
+				"ping",
+				"set dpu-1/" + testSubsystem.Name,
+				"get dpu-1/" + testSubsystem.Name,
+			},
+		},
+		"password and database": {
+			config: func(c *config.RedisConfig, _ string) {
+				c.Password = "secret"
+				c.DB = 2
+			},
+			password: "secret",
+			commands: []string{
+				"auth secret",
+				"select 2",
+				"ping",
+				"set dpu-1/" + testSubsystem.Name,
+				"get dpu-1/" + testSubsystem.Name,
+			},
+		},
+		"ACL user with password file": {
+			config: func(c *config.RedisConfig, dir string) {
+				c.Username = "bridge"
+				c.PasswordFile = filepath.Join(dir, "password")
+				c.DB = 2
+			},
+			password: "secret",
+			commands: []string{
+				"auth bridge secret",
+				"select 2",
+				"ping",
+				"set dpu-1/" + testSubsystem.Name,
+				"get dpu-1/" + testSubsystem.Name,
+			},
+		},
+		"wrong password": {
+			config: func(c *config.RedisConfig, _ string) {
+				c.Password = "wrong"
+			},
+			password: "secret",
+			errMsg:   "WRONGPASS",
+		},
+		"missing password file": {
+			config: func(c *config.RedisConfig, dir string) {
+				c.PasswordFile = filepath.Join(dir, "missing")
+			},
+			errMsg: "could not read Redis password",
+		},
+		"invalid CA file": {
+			config: func(c *config.RedisConfig, dir string) {
+				c.TLS.Enabled = true
+				c.TLS.CAFile = filepath.Join(dir, "password")
+			},
+			errMsg: "could not configure Redis TLS: no certificate found in",
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "password"), []byte("secret\n"), 0600); err != nil {
+				t.Fatal(err)
+			}
+			server := newFakeRedis(t, tt.password)
+			c := config.Default().Store
+			c.Redis.Address = server.lis.Addr().String()
+			c.Redis.MaxRetries = 0
+			c.Redis.KeyPrefix = "dpu-1/"
+			tt.config(&c.Redis, dir)
+
+			store, err := New(&c)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatal("expected error", tt.errMsg, "received", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = store.Close() }()
+			if err := store.Set(testSubsystem.Name, &testSubsystem); err != nil {
+				t.Fatal(err)
+			}
+			if found, err := store.Get(testSubsystem.Name, new(pb.NvmeSubsystem)); !found || err != nil {
+				t.Error("expected stored key, received", found, err)
+			}
+
+			// the values are binary, only keep the command and key
+			received := server.received()
+			for i, command := range received {
+				if fields := strings.Fields(command); fields[0] == "set" {
+					received[i] = strings.Join(fields[:2], " ")
+				}
+			}
+			if !reflect.DeepEqual(received, tt.commands) {
+				t.Error("expected", tt.commands, "received", received)
+			}
+		})
+	}
+}
+
+func TestStore_RedisKeys(t *testing.T) {
+	server := newFakeRedis(t, "")
+	c := config.Default().Store
+	c.Redis.Address = server.lis.Addr().String()
+	c.Redis.MaxRetries = 0
+	c.Redis.KeyPrefix = "dpu-1/"
+	store, err := New(&c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	if err := store.Set(testSubsystem.Name, &testSubsystem); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set("tenants/"+testSubsystem.Name, &testSubsystem); err != nil {
+		t.Fatal(err)
+	}
+	server.mutex.Lock()
+	server.values["dpu-2/"+testSubsystem.Name] = "other"
+	server.mutex.Unlock()
+
+	keys, err := store.(KeyLister).Keys("//storage.opiproject.org/subsystems/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(keys, []string{testSubsystem.Name}) {
+		t.Error("expected", []string{testSubsystem.Name}, "received", keys)
+	}
+	received := server.received()
+	if scan := received[len(received)-1]; scan != "scan 0 match dpu-1///storage.opiproject.org/subsystems/* count 100" {
+		t.Error("expected SCAN of the prefix, received", scan)
+	}
+}
@@ -0,0 +1,41 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"fmt"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+
+	"github.com/philippgille/gokv"
+)
+
+// KeyLister is implemented by the stores listing their keys, so that the
+// resources stored before a restart are found
+type KeyLister interface {
+	// Keys returns the keys starting with prefix
+	Keys(prefix string) ([]string, error)
+}
+
+// New creates the store of type c.Type, the values are encoded as protobuf
+func New(c *config.StoreConfig) (gokv.Store, error) {
+	codec := utils.ProtoCodec{}
+	switch c.Type {
+	case config.StoreGomap:
+		return NewGomapStore(codec), nil
+	case config.StoreBbolt:
+		return NewBboltStore(c.Bbolt.Path, c.Bbolt.Bucket, c.Bbolt.Timeout, codec)
+	case config.StoreRedis:
+		client, err := NewRedisClient(&c.Redis)
+		if err != nil {
+			return nil, err
+		}
+		return WithKeyPrefix(NewRedisStore(client, codec), c.Redis.KeyPrefix), nil
+	default:
+		return nil, fmt.Errorf("unknown store type %q", c.Type)
+	}
+}
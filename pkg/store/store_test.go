@@ -0,0 +1,180 @@
+// SPDX-License-Identifier: Apache-2.0
+// Copyright (c) 2022-2023 Dell Inc, or its subsidiaries.
+
+// Package store creates the key-value store of the resources selected by
+// the configuration
+package store
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	pb "github.com/opiproject/opi-api/storage/v1alpha1/gen/go"
+	"github.com/opiproject/opi-spdk-bridge/pkg/utils"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/opiproject/opi-marvell-bridge/pkg/config"
+)
+
+var testSubsystem = pb.NvmeSubsystem{
+	Name: "//storage.opiproject.org/subsystems/subsystem-test",
+	Spec: &pb.NvmeSubsystemSpec{Nqn: "nqn.2022-09.io.spdk:opi3"},
+}
+
+func TestStore_New(t *testing.T) {
+	tests := map[string]struct {
+		config func(c *config.StoreConfig, dir string)
+		errMsg string
+	}{
+		"gomap": {
+			config: func(c *config.StoreConfig, _ string) {
+				c.Type = config.StoreGomap
+			},
+		},
+		"bbolt": {
+			config: func(c *config.StoreConfig, dir string) {
+				c.Type = config.StoreBbolt
+				c.Bbolt.Path = filepath.Join(dir, "nested", "store.db")
+			},
+		},
+		"unreachable redis": {
+			config: func(c *config.StoreConfig, _ string) {
+				c.Redis.Address = closedAddress(t)
+				c.Redis.DialTimeout = 100 * time.Millisecond
+				c.Redis.MaxRetries = 0
+			},
+			errMsg: "could not connect to Redis in standalone mode",
+		},
+		"unknown redis mode": {
+			config: func(c *config.StoreConfig, _ string) {
+				c.Redis.Mode = "replicated"
+			},
+			errMsg: `unknown Redis mode "replicated"`,
+		},
+		"unknown type": {
+			config: func(c *config.StoreConfig, _ string) {
+				c.Type = "etcd"
+			},
+			errMsg: `unknown store type "etcd"`,
+		},
+	}
+
+	// run tests
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := config.Default().Store
+			tt.config(&c, t.TempDir())
+
+			store, err := New(&c)
+			if tt.errMsg != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+					t.Fatal("expected error", tt.errMsg, "received", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = store.Close() }()
+
+			found, err := store.Get(testSubsystem.Name, new(pb.NvmeSubsystem))
+			if found || err != nil {
+				t.Error("expected missing key, received", found, err)
+			}
+			if err := store.Set(testSubsystem.Name, &testSubsystem); err != nil {
+				t.Fatal(err)
+			}
+			stored := new(pb.NvmeSubsystem)
+			found, err = store.Get(testSubsystem.Name, stored)
+			if !found || err != nil || !proto.Equal(stored, &testSubsystem) {
+				t.Error("expected", &testSubsystem, "received", stored, found, err)
+			}
+			if err := store.Delete(testSubsystem.Name); err != nil {
+				t.Fatal(err)
+			}
+			found, err = store.Get(testSubsystem.Name, stored)
+			if found || err != nil {
+				t.Error("expected deleted key, received", found, err)
+			}
+		})
+	}
+}
+
+func TestStore_Bbolt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.db")
+	store, err := NewBboltStore(path, "opi", time.Second, utils.ProtoCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Set(testSubsystem.Name, &testSubsystem); err != nil {
+		t.Fatal(err)
+	}
+
+	// the file is locked while open
+	if _, err := NewBboltStore(path, "opi", 50*time.Millisecond, utils.ProtoCodec{}); err == nil {
+		t.Error("expected lock timeout")
+	}
+
+	// and persisted once closed
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err = NewBboltStore(path, "opi", time.Second, utils.ProtoCodec{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+	stored := new(pb.NvmeSubsystem)
+	found, err := store.Get(testSubsystem.Name, stored)
+	if !found || err != nil || !proto.Equal(stored, &testSubsystem) {
+		t.Error("expected", &testSubsystem, "received", stored, found, err)
+	}
+
+	// the keys are listed by prefix
+	if err := store.Set("tenants/"+testSubsystem.Name, &testSubsystem); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := store.Keys("//storage.opiproject.org/subsystems/")
+	if err != nil || !reflect.DeepEqual(keys, []string{testSubsystem.Name}) {
+		t.Error("expected", []string{testSubsystem.Name}, "received", keys, err)
+	}
+}
+
+// closedAddress returns the address of a port nothing listens on
+func closedAddress(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := lis.Addr().String()
+	_ = lis.Close()
+	return address
+}
+
+func TestStore_GomapConcurrency(t *testing.T) {
+	store := NewGomapStore(utils.ProtoCodec{})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("%s-%d", testSubsystem.Name, i)
+			if err := store.Set(key, &testSubsystem); err != nil {
+				t.Error(err)
+			}
+			if found, err := store.Get(key, new(pb.NvmeSubsystem)); !found || err != nil {
+				t.Error("expected stored key, received", found, err)
+			}
+			if err := store.Delete(key); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}